@@ -10,10 +10,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/lmittmann/tint"
+	"github.com/nats-io/nats.go"
 	"github.com/programme-lv/backend/common/ctxlog"
+	"github.com/programme-lv/backend/common/filestore"
 	"github.com/programme-lv/backend/conf"
 	"github.com/programme-lv/backend/modules/exec"
 	exechttp "github.com/programme-lv/backend/modules/exec/http"
+	execpgrepo "github.com/programme-lv/backend/modules/exec/pgrepo"
 	planghttp "github.com/programme-lv/backend/modules/plang/http"
 	"github.com/programme-lv/backend/modules/subm/domain"
 	submhttp "github.com/programme-lv/backend/modules/subm/http"
@@ -36,6 +39,10 @@ func main() {
 	setupLogger()
 
 	listenResults := flag.Bool("listen-sqs", true, "Whether to listen for NATS execution results")
+	importExecFiles := flag.Bool("import-exec-files", false, "Import executions stored as JSON files into Postgres and exit")
+	gcStores := flag.Bool("gc-stores", false, "Report test files and public assets that nothing refers to and exit")
+	gcDelete := flag.Bool("gc-delete", false, "With -gc-stores, delete the unreferenced objects instead of a dry run")
+	gcGrace := flag.Duration("gc-grace", 7*24*time.Hour, "With -gc-stores, keep unreferenced objects modified within this duration")
 	flag.Parse()
 
 	jwtKey := conf.MustGetJwtKeyFromEnv()
@@ -56,15 +63,50 @@ func main() {
 
 	execCtx := context.Background()
 	execCtx = ctxlog.WithLogger(execCtx, slog.Default().With("module", "exec"))
-	execRepo := exec.NewFileExecRepo(execCtx, execStore)
+	execRepo := execpgrepo.NewPgExecRepo(pgPool, execStore)
+	if *importExecFiles {
+		stats, err := execRepo.ImportFileExecutions(execCtx, execStore)
+		if err != nil {
+			slog.Error("import execution files", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("imported execution files", "imported", stats.Imported,
+			"skipped", stats.Skipped, "failed", stats.Failed, "pending", stats.Pending)
+		return
+	}
+	if *gcStores {
+		runStoreGC(pgPool, execRepo, publicStore, testfileStore, *gcGrace, !*gcDelete)
+		return
+	}
 	natsConn := conf.MustGetNatsConnFromEnv(execCtx)
-	execSrvc := exec.NewExecSrvc(execCtx, execRepo, natsConn, testfileStore)
+	watchdogCfg := conf.MustGetExecWatchdogConfigFromEnv()
+	execOpts := []exec.ExecSrvcOption{
+		exec.WithWatchdog(exec.WatchdogConfig{
+			TotalTimeout: watchdogCfg.TotalTimeout,
+			EventTimeout: watchdogCfg.EventTimeout,
+			QueueTimeout: watchdogCfg.QueueTimeout,
+			MaxRetries:   watchdogCfg.MaxRetries,
+		}),
+	}
+	if transportCfg := conf.MustGetExecTransportConfigFromEnv(); transportCfg.JetStream {
+		slog.Info("using JetStream execution transport", "name", transportCfg.Name)
+		execOpts = append(execOpts, exec.WithJetStream(exec.JetStreamConfig{
+			Name:     transportCfg.Name,
+			Replicas: transportCfg.Replicas,
+		}))
+	}
+	execSrvc := exec.NewExecSrvc(execCtx, execRepo, natsConn, testfileStore, execOpts...)
 	if *listenResults {
 		err := execSrvc.StartPollingResultQueue(execCtx)
 		if err != nil {
 			slog.Error("listen for NATS execution results", "error", err)
 			os.Exit(1)
 		}
+		err = execSrvc.ResumePending(execCtx)
+		if err != nil {
+			slog.Error("resume pending executions", "error", err)
+			os.Exit(1)
+		}
 	} else {
 		slog.Info("NATS execution result listener disabled")
 	}
@@ -98,10 +140,19 @@ func main() {
 		testfileStore,
 		tasksrvc.WithPublicAPIBaseURL(apiPublicBaseURL),
 		tasksrvc.WithTestfileDownloadSigningKey(testfileSigningKey),
+		tasksrvc.WithExecSrvc(execSrvc),
+		tasksrvc.WithSolveCounter(submpgrepo.NewPgSubmRepo(pgPool).CountSolversPerTask),
 	)
+	if err := taskSrvc.InterruptSolutionChecks(context.Background()); err != nil {
+		slog.Error("interrupt unfinished solution checks", "error", err)
+	}
 
 	// Initialize HTTP handlers
-	submHttpHandler := newSubmHttpHandler(userSrvc, taskSrvc, execSrvc)
+	var resumePending func(context.Context) error
+	if *listenResults {
+		resumePending = execSrvc.ResumePending
+	}
+	submHttpHandler := newSubmHttpHandler(userSrvc, taskSrvc, execSrvc, natsConn, resumePending)
 	taskHttpHandler := taskhttp.NewTaskHttpHandler(
 		taskSrvc,
 		taskhttp.WithFileStores(publicStore, testfileStore, testfileSigningKey),
@@ -112,7 +163,7 @@ func main() {
 		userhttp.WithCookieDomain(cookieDomain),
 		userhttp.WithSecureCookie(cookieSecure),
 	)
-	execHttpHandler := exechttp.NewExecHttpHandler(execSrvc, adminAPIKey)
+	execHttpHandler := exechttp.NewExecHttpHandler(execSrvc, taskSrvc, adminAPIKey)
 	plangHttpHandler := planghttp.NewPlangHttpHandler()
 
 	// Start HTTP server
@@ -133,6 +184,39 @@ func main() {
 	slog.Info("server stopped", "error", err)
 }
 
+// runStoreGC deletes test files and public assets that no task, kept
+// evaluation or pending execution refers to, and logs the size report.
+func runStoreGC(pgPool *pgxpool.Pool, execRepo exec.ExecRepo, publicStore, testfileStore *filestore.Store, grace time.Duration, dryRun bool) {
+	ctx := ctxlog.WithLogger(context.Background(), slog.Default().With("cmd", "gc stores"))
+	taskSrvc := tasksrvc.NewTaskSrvc(
+		repo.NewTaskPgRepo(pgPool),
+		publicStore,
+		testfileStore,
+		tasksrvc.WithTestfileRefs(
+			submpgrepo.NewPgEvalRepo(pgPool).ListTestSha256s,
+			func(ctx context.Context) ([]string, error) {
+				return exec.ListPendingTestSha256s(ctx, execRepo)
+			},
+		),
+	)
+	report, err := taskSrvc.CollectGarbage(ctx, grace, dryRun)
+	if err != nil {
+		slog.Error("collect garbage", "error", err)
+		os.Exit(1)
+	}
+	for _, store := range []struct {
+		name string
+		r    tasksrvc.StoreGCReport
+	}{{"testfiles", report.Testfiles}, {"public", report.Public}} {
+		r := store.r
+		slog.Info("collected garbage", "store", store.name, "dry_run", report.DryRun, "grace", grace,
+			"objects", r.Objects, "bytes", r.Bytes,
+			"orphans", r.Orphans, "orphan_bytes", r.OrphanBytes,
+			"expired", r.Expired, "expired_bytes", r.ExpiredBytes,
+			"deleted", r.Deleted, "deleted_bytes", r.DeletedBytes)
+	}
+}
+
 func setupLogger() {
 	slog.SetDefault(slog.New(
 		tint.NewHandler(os.Stdout, &tint.Options{
@@ -143,7 +227,7 @@ func setupLogger() {
 	))
 }
 
-func newSubmHttpHandler(userSrvc usersrvc.UserService, taskSrvc tasksrvc.TaskService, execSrvc exec.CodeExecutionService) *submhttp.SubmHttpHandler {
+func newSubmHttpHandler(userSrvc usersrvc.UserService, taskSrvc tasksrvc.TaskService, execSrvc exec.CodeExecutionService, natsConn *nats.Conn, resumePending func(context.Context) error) *submhttp.SubmHttpHandler {
 	pgPool, err := conf.GetPgxPoolFromEnv()
 	if err != nil {
 		slog.Error("create pg pool", "error", err)
@@ -152,12 +236,52 @@ func newSubmHttpHandler(userSrvc usersrvc.UserService, taskSrvc tasksrvc.TaskSer
 
 	submPgRepo := submpgrepo.NewPgSubmRepo(pgPool)
 	evalPgRepo := submpgrepo.NewPgEvalRepo(pgPool)
-	submSrvc := srvc.NewSubmSrvc(userSrvc, taskSrvc, execSrvc, submPgRepo, evalPgRepo)
+	submSrvc := srvc.NewSubmSrvc(userSrvc, taskSrvc, execSrvc, submPgRepo, evalPgRepo,
+		srvc.WithNatsFanout(natsConn))
+	if err := submSrvc.StartFanout(context.Background()); err != nil {
+		slog.Error("subscribe to submission updates of other replicas", "error", err)
+		os.Exit(1)
+	}
+
+	// Continue evaluations interrupted by a previous process, then
+	// take over the executions of replicas that stop renewing their leases
+	if resumePending != nil {
+		if err := submSrvc.ResumeEvals(context.Background()); err != nil {
+			slog.Error("resume unfinished evaluations", "error", err)
+		}
+		go takeOverPending(context.Background(), resumePending, submSrvc)
+	}
 
 	// Check if migration is needed and run it
 	runScoreMigrationIfNeeded(pgPool, submSrvc, evalPgRepo)
 
-	return submhttp.NewSubmHttpHandler(submSrvc, taskSrvc, userSrvc)
+	handler := submhttp.NewSubmHttpHandler(submSrvc, taskSrvc, userSrvc)
+	if err := handler.StartCacheInvalidation(context.Background()); err != nil {
+		slog.Error("start submission cache invalidation", "error", err)
+		os.Exit(1)
+	}
+	return handler
+}
+
+// takeOverPending resumes the executions and evaluations
+// whose owners have not renewed their leases, until ctx ends.
+func takeOverPending(ctx context.Context, resumePending func(context.Context) error, submSrvc srvc.SubmissionService) {
+	ticker := time.NewTicker(exec.PendingLeaseTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := resumePending(ctx); err != nil {
+				slog.Error("take over pending executions", "error", err)
+				continue
+			}
+			if err := submSrvc.ResumeEvals(ctx); err != nil {
+				slog.Error("take over unfinished evaluations", "error", err)
+			}
+		}
+	}
 }
 
 // runScoreMigrationIfNeeded checks if there are evaluations without score info
@@ -185,10 +309,11 @@ func runScoreMigrationIfNeeded(pgPool *pgxpool.Pool, submSrvc srvc.SubmissionSer
 	slog.Warn("found evaluations missing score info, running migration", "count", count)
 
 	subms, err := submSrvc.ListSubms(ctx, srvc.ListSubmsParams{
-		Limit:  10000000,
-		Offset: 0,
-		Search: "",
-		Author: nil,
+		Limit:        10000000,
+		Offset:       0,
+		Search:       "",
+		Author:       nil,
+		IncludeAdmin: true,
 	})
 	if err != nil {
 		slog.Error("list submissions", "error", err)
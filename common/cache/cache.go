@@ -81,3 +81,12 @@ func (c *LruCache[K, V]) Delete(key K) {
 	delete(c.entries, key)
 	delete(c.lastTime, key)
 }
+
+// Clear removes all items from the cache.
+func (c *LruCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+	clear(c.lastTime)
+}
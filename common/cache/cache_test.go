@@ -63,3 +63,17 @@ func TestLRU_Delete(t *testing.T) {
 		t.Fatalf("expected deleted entry to be gone")
 	}
 }
+
+func TestLRU_Clear(t *testing.T) {
+	c := cache.NewLruCache[string, int](10)
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Clear()
+	if _, ok := c.Get("a"); ok {
+		t.Fatalf("expected cleared entry to be gone")
+	}
+	c.Set("c", 3, 0)
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Fatalf("expected cache to be usable after clear, got ok=%v v=%v", ok, v)
+	}
+}
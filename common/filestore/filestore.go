@@ -100,6 +100,69 @@ func (s *Store) Delete(key string) error {
 	return nil
 }
 
+// Touch sets the modification time of an existing object to now.
+func (s *Store) Touch(key string) error {
+	fullPath, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := os.Chtimes(fullPath, now, now); err != nil {
+		return fmt.Errorf("touch object: %w", err)
+	}
+	return nil
+}
+
+// Object describes a stored object returned by List.
+type Object struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// List returns every object stored under the prefix directory,
+// recursively, in lexical key order. A missing prefix lists nothing.
+func (s *Store) List(prefix string) ([]Object, error) {
+	dir := s.root
+	if prefix != "" {
+		var err error
+		dir, err = s.Path(prefix)
+		if err != nil {
+			return nil, err
+		}
+	}
+	objects := []Object{}
+	err := filepath.WalkDir(dir, func(fullPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && fullPath == dir {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.root, fullPath)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, Object{
+			Key:     filepath.ToSlash(rel),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list objects: %w", err)
+	}
+	return objects, nil
+}
+
 func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request, key string) error {
 	fullPath, err := s.Path(key)
 	if err != nil {
@@ -3,6 +3,7 @@ package filestore
 import (
 	"io"
 	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
@@ -33,6 +34,43 @@ func TestStoreUploadDownloadAndServe(t *testing.T) {
 	require.Equal(t, "hello", rec.Body.String())
 }
 
+func TestStoreList(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	objects, err := store.List("pending")
+	require.NoError(t, err)
+	require.Empty(t, objects)
+
+	_, err = store.Upload([]byte("a"), "pending/a.json", "application/json")
+	require.NoError(t, err)
+	_, err = store.Upload([]byte("bb"), "pending/sub/b.json", "application/json")
+	require.NoError(t, err)
+	_, err = store.Upload([]byte("c"), "other.json", "application/json")
+	require.NoError(t, err)
+
+	objects, err = store.List("pending")
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	require.Equal(t, "pending/a.json", objects[0].Key)
+	require.Equal(t, int64(1), objects[0].Size)
+	require.Equal(t, "pending/sub/b.json", objects[1].Key)
+
+	all, err := store.List("")
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+
+	old := time.Now().Add(-48 * time.Hour)
+	fullPath, err := store.Path("other.json")
+	require.NoError(t, err)
+	require.NoError(t, os.Chtimes(fullPath, old, old))
+	require.NoError(t, store.Touch("other.json"))
+	all, err = store.List("other.json")
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), all[0].ModTime, time.Minute)
+	require.Error(t, store.Touch("missing.json"))
+}
+
 func TestCleanKeyRejectsUnsafePaths(t *testing.T) {
 	for _, key := range []string{"", "/absolute", "../escape", "a/../escape", `a\escape`} {
 		_, err := CleanKey(key)
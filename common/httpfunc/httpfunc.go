@@ -52,3 +52,22 @@ func NoReqNoResp(handler HandlerNoReqNoResp) http.HandlerFunc {
 		jsonresp.Success(w, struct{}{})
 	}
 }
+
+type Handler[Q any, R any] func(ctx context.Context, request Q) (response R, err jsonresp.HttpStatusCoder)
+
+func JsonReqJsonResp[Q any, R any](handler Handler[Q, R]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var req Q
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			jsonresp.BadRequest(w, err.Error())
+			return
+		}
+		result, err := handler(ctx, req)
+		if err != nil {
+			jsonresp.WriteError(w, err)
+			return
+		}
+		jsonresp.Success(w, result)
+	}
+}
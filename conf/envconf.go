@@ -326,6 +326,61 @@ func MustGetEmailConfigFromEnv() EmailConfig {
 	return cfg
 }
 
+type ExecWatchdogConfig struct {
+	TotalTimeout time.Duration
+	EventTimeout time.Duration
+	QueueTimeout time.Duration
+	MaxRetries   int
+}
+
+// MustGetExecWatchdogConfigFromEnv reads the deadlines after which a
+// silent tester job is re-published or failed.
+func MustGetExecWatchdogConfigFromEnv() ExecWatchdogConfig {
+	cfg := ExecWatchdogConfig{
+		TotalTimeout: durationFromEnv("EXEC_TOTAL_TIMEOUT", 5*time.Minute),
+		EventTimeout: durationFromEnv("EXEC_EVENT_TIMEOUT", 20*time.Second),
+		QueueTimeout: durationFromEnv("EXEC_QUEUE_TIMEOUT", time.Minute),
+		MaxRetries:   2,
+	}
+	if raw := os.Getenv("EXEC_MAX_RETRIES"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			slog.Error("EXEC_MAX_RETRIES must be a non-negative integer", "value", raw)
+			os.Exit(1)
+		}
+		cfg.MaxRetries = parsed
+	}
+	return cfg
+}
+
+type ExecTransportConfig struct {
+	JetStream bool
+	// Name of this backend process for its durable JetStream consumer
+	Name     string
+	Replicas int
+}
+
+// MustGetExecTransportConfigFromEnv reads EXEC_TRANSPORT, which is
+// "core" (default) or "jetstream" for the durable job transport.
+func MustGetExecTransportConfigFromEnv() ExecTransportConfig {
+	cfg := ExecTransportConfig{
+		Name:     os.Getenv("EXEC_JETSTREAM_NAME"),
+		Replicas: intFromEnv("EXEC_JETSTREAM_REPLICAS", 1),
+	}
+	switch transport := os.Getenv("EXEC_TRANSPORT"); transport {
+	case "", "core":
+	case "jetstream":
+		cfg.JetStream = true
+	default:
+		slog.Error("EXEC_TRANSPORT must be core or jetstream", "value", transport)
+		os.Exit(1)
+	}
+	if cfg.Name == "" {
+		cfg.Name = "backend"
+	}
+	return cfg
+}
+
 func durationFromEnv(name string, fallback time.Duration) time.Duration {
 	raw := os.Getenv(name)
 	if raw == "" {
@@ -7,6 +7,8 @@ import (
 
 	srvc "github.com/programme-lv/backend/modules/task/srvc"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // MockTaskPgRepo is an autogenerated mock type for the TaskPgRepo type
@@ -269,6 +271,65 @@ func (_c *MockTaskPgRepo_Exists_Call) RunAndReturn(run func(context.Context, str
 	return _c
 }
 
+// GetSolutionReport provides a mock function with given fields: ctx, taskId
+func (_m *MockTaskPgRepo) GetSolutionReport(ctx context.Context, taskId string) (*srvc.SolutionReport, error) {
+	ret := _m.Called(ctx, taskId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSolutionReport")
+	}
+
+	var r0 *srvc.SolutionReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*srvc.SolutionReport, error)); ok {
+		return rf(ctx, taskId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *srvc.SolutionReport); ok {
+		r0 = rf(ctx, taskId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*srvc.SolutionReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, taskId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTaskPgRepo_GetSolutionReport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSolutionReport'
+type MockTaskPgRepo_GetSolutionReport_Call struct {
+	*mock.Call
+}
+
+// GetSolutionReport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - taskId string
+func (_e *MockTaskPgRepo_Expecter) GetSolutionReport(ctx interface{}, taskId interface{}) *MockTaskPgRepo_GetSolutionReport_Call {
+	return &MockTaskPgRepo_GetSolutionReport_Call{Call: _e.mock.On("GetSolutionReport", ctx, taskId)}
+}
+
+func (_c *MockTaskPgRepo_GetSolutionReport_Call) Run(run func(ctx context.Context, taskId string)) *MockTaskPgRepo_GetSolutionReport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockTaskPgRepo_GetSolutionReport_Call) Return(_a0 *srvc.SolutionReport, _a1 error) *MockTaskPgRepo_GetSolutionReport_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTaskPgRepo_GetSolutionReport_Call) RunAndReturn(run func(context.Context, string) (*srvc.SolutionReport, error)) *MockTaskPgRepo_GetSolutionReport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetTask provides a mock function with given fields: ctx, shortId
 func (_m *MockTaskPgRepo) GetTask(ctx context.Context, shortId string) (srvc.Task, error) {
 	ret := _m.Called(ctx, shortId)
@@ -383,9 +444,126 @@ func (_c *MockTaskPgRepo_GetTaskPreview_Call) RunAndReturn(run func(context.Cont
 	return _c
 }
 
-// ListOriginCounts provides a mock function with given fields: ctx
-func (_m *MockTaskPgRepo) ListOriginCounts(ctx context.Context) ([]srvc.OriginCount, error) {
-	ret := _m.Called(ctx)
+// GetTaskRevision provides a mock function with given fields: ctx, taskId, revision
+func (_m *MockTaskPgRepo) GetTaskRevision(ctx context.Context, taskId string, revision int) (*srvc.Task, error) {
+	ret := _m.Called(ctx, taskId, revision)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTaskRevision")
+	}
+
+	var r0 *srvc.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (*srvc.Task, error)); ok {
+		return rf(ctx, taskId, revision)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *srvc.Task); ok {
+		r0 = rf(ctx, taskId, revision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*srvc.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, taskId, revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTaskPgRepo_GetTaskRevision_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTaskRevision'
+type MockTaskPgRepo_GetTaskRevision_Call struct {
+	*mock.Call
+}
+
+// GetTaskRevision is a helper method to define mock.On call
+//   - ctx context.Context
+//   - taskId string
+//   - revision int
+func (_e *MockTaskPgRepo_Expecter) GetTaskRevision(ctx interface{}, taskId interface{}, revision interface{}) *MockTaskPgRepo_GetTaskRevision_Call {
+	return &MockTaskPgRepo_GetTaskRevision_Call{Call: _e.mock.On("GetTaskRevision", ctx, taskId, revision)}
+}
+
+func (_c *MockTaskPgRepo_GetTaskRevision_Call) Run(run func(ctx context.Context, taskId string, revision int)) *MockTaskPgRepo_GetTaskRevision_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockTaskPgRepo_GetTaskRevision_Call) Return(_a0 *srvc.Task, _a1 error) *MockTaskPgRepo_GetTaskRevision_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTaskPgRepo_GetTaskRevision_Call) RunAndReturn(run func(context.Context, string, int) (*srvc.Task, error)) *MockTaskPgRepo_GetTaskRevision_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InterruptSolutionReports provides a mock function with given fields: ctx, startedBefore
+func (_m *MockTaskPgRepo) InterruptSolutionReports(ctx context.Context, startedBefore time.Time) (int, error) {
+	ret := _m.Called(ctx, startedBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InterruptSolutionReports")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int, error)); ok {
+		return rf(ctx, startedBefore)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int); ok {
+		r0 = rf(ctx, startedBefore)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, startedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTaskPgRepo_InterruptSolutionReports_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InterruptSolutionReports'
+type MockTaskPgRepo_InterruptSolutionReports_Call struct {
+	*mock.Call
+}
+
+// InterruptSolutionReports is a helper method to define mock.On call
+//   - ctx context.Context
+//   - startedBefore time.Time
+func (_e *MockTaskPgRepo_Expecter) InterruptSolutionReports(ctx interface{}, startedBefore interface{}) *MockTaskPgRepo_InterruptSolutionReports_Call {
+	return &MockTaskPgRepo_InterruptSolutionReports_Call{Call: _e.mock.On("InterruptSolutionReports", ctx, startedBefore)}
+}
+
+func (_c *MockTaskPgRepo_InterruptSolutionReports_Call) Run(run func(ctx context.Context, startedBefore time.Time)) *MockTaskPgRepo_InterruptSolutionReports_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockTaskPgRepo_InterruptSolutionReports_Call) Return(_a0 int, _a1 error) *MockTaskPgRepo_InterruptSolutionReports_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTaskPgRepo_InterruptSolutionReports_Call) RunAndReturn(run func(context.Context, time.Time) (int, error)) *MockTaskPgRepo_InterruptSolutionReports_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListOriginCounts provides a mock function with given fields: ctx, includeUnlisted
+func (_m *MockTaskPgRepo) ListOriginCounts(ctx context.Context, includeUnlisted bool) ([]srvc.OriginCount, error) {
+	ret := _m.Called(ctx, includeUnlisted)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ListOriginCounts")
@@ -393,19 +571,19 @@ func (_m *MockTaskPgRepo) ListOriginCounts(ctx context.Context) ([]srvc.OriginCo
 
 	var r0 []srvc.OriginCount
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]srvc.OriginCount, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, bool) ([]srvc.OriginCount, error)); ok {
+		return rf(ctx, includeUnlisted)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []srvc.OriginCount); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, bool) []srvc.OriginCount); ok {
+		r0 = rf(ctx, includeUnlisted)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]srvc.OriginCount)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, bool) error); ok {
+		r1 = rf(ctx, includeUnlisted)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -420,13 +598,14 @@ type MockTaskPgRepo_ListOriginCounts_Call struct {
 
 // ListOriginCounts is a helper method to define mock.On call
 //   - ctx context.Context
-func (_e *MockTaskPgRepo_Expecter) ListOriginCounts(ctx interface{}) *MockTaskPgRepo_ListOriginCounts_Call {
-	return &MockTaskPgRepo_ListOriginCounts_Call{Call: _e.mock.On("ListOriginCounts", ctx)}
+//   - includeUnlisted bool
+func (_e *MockTaskPgRepo_Expecter) ListOriginCounts(ctx interface{}, includeUnlisted interface{}) *MockTaskPgRepo_ListOriginCounts_Call {
+	return &MockTaskPgRepo_ListOriginCounts_Call{Call: _e.mock.On("ListOriginCounts", ctx, includeUnlisted)}
 }
 
-func (_c *MockTaskPgRepo_ListOriginCounts_Call) Run(run func(ctx context.Context)) *MockTaskPgRepo_ListOriginCounts_Call {
+func (_c *MockTaskPgRepo_ListOriginCounts_Call) Run(run func(ctx context.Context, includeUnlisted bool)) *MockTaskPgRepo_ListOriginCounts_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context))
+		run(args[0].(context.Context), args[1].(bool))
 	})
 	return _c
 }
@@ -436,39 +615,102 @@ func (_c *MockTaskPgRepo_ListOriginCounts_Call) Return(_a0 []srvc.OriginCount, _
 	return _c
 }
 
-func (_c *MockTaskPgRepo_ListOriginCounts_Call) RunAndReturn(run func(context.Context) ([]srvc.OriginCount, error)) *MockTaskPgRepo_ListOriginCounts_Call {
+func (_c *MockTaskPgRepo_ListOriginCounts_Call) RunAndReturn(run func(context.Context, bool) ([]srvc.OriginCount, error)) *MockTaskPgRepo_ListOriginCounts_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListTaskPreviews provides a mock function with given fields: ctx, limit, offset
-func (_m *MockTaskPgRepo) ListTaskPreviews(ctx context.Context, limit int, offset int) ([]srvc.TaskPreview, error) {
-	ret := _m.Called(ctx, limit, offset)
+// ListReferencedObjects provides a mock function with given fields: ctx
+func (_m *MockTaskPgRepo) ListReferencedObjects(ctx context.Context) (srvc.ReferencedObjects, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListReferencedObjects")
+	}
+
+	var r0 srvc.ReferencedObjects
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (srvc.ReferencedObjects, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) srvc.ReferencedObjects); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(srvc.ReferencedObjects)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTaskPgRepo_ListReferencedObjects_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListReferencedObjects'
+type MockTaskPgRepo_ListReferencedObjects_Call struct {
+	*mock.Call
+}
+
+// ListReferencedObjects is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockTaskPgRepo_Expecter) ListReferencedObjects(ctx interface{}) *MockTaskPgRepo_ListReferencedObjects_Call {
+	return &MockTaskPgRepo_ListReferencedObjects_Call{Call: _e.mock.On("ListReferencedObjects", ctx)}
+}
+
+func (_c *MockTaskPgRepo_ListReferencedObjects_Call) Run(run func(ctx context.Context)) *MockTaskPgRepo_ListReferencedObjects_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockTaskPgRepo_ListReferencedObjects_Call) Return(_a0 srvc.ReferencedObjects, _a1 error) *MockTaskPgRepo_ListReferencedObjects_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTaskPgRepo_ListReferencedObjects_Call) RunAndReturn(run func(context.Context) (srvc.ReferencedObjects, error)) *MockTaskPgRepo_ListReferencedObjects_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListTaskPreviews provides a mock function with given fields: ctx, q
+func (_m *MockTaskPgRepo) ListTaskPreviews(ctx context.Context, q srvc.TaskPreviewQuery) ([]srvc.TaskPreview, int, error) {
+	ret := _m.Called(ctx, q)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ListTaskPreviews")
 	}
 
 	var r0 []srvc.TaskPreview
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]srvc.TaskPreview, error)); ok {
-		return rf(ctx, limit, offset)
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, srvc.TaskPreviewQuery) ([]srvc.TaskPreview, int, error)); ok {
+		return rf(ctx, q)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) []srvc.TaskPreview); ok {
-		r0 = rf(ctx, limit, offset)
+	if rf, ok := ret.Get(0).(func(context.Context, srvc.TaskPreviewQuery) []srvc.TaskPreview); ok {
+		r0 = rf(ctx, q)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]srvc.TaskPreview)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
-		r1 = rf(ctx, limit, offset)
+	if rf, ok := ret.Get(1).(func(context.Context, srvc.TaskPreviewQuery) int); ok {
+		r1 = rf(ctx, q)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(int)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(context.Context, srvc.TaskPreviewQuery) error); ok {
+		r2 = rf(ctx, q)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // MockTaskPgRepo_ListTaskPreviews_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTaskPreviews'
@@ -478,25 +720,83 @@ type MockTaskPgRepo_ListTaskPreviews_Call struct {
 
 // ListTaskPreviews is a helper method to define mock.On call
 //   - ctx context.Context
-//   - limit int
-//   - offset int
-func (_e *MockTaskPgRepo_Expecter) ListTaskPreviews(ctx interface{}, limit interface{}, offset interface{}) *MockTaskPgRepo_ListTaskPreviews_Call {
-	return &MockTaskPgRepo_ListTaskPreviews_Call{Call: _e.mock.On("ListTaskPreviews", ctx, limit, offset)}
+//   - q srvc.TaskPreviewQuery
+func (_e *MockTaskPgRepo_Expecter) ListTaskPreviews(ctx interface{}, q interface{}) *MockTaskPgRepo_ListTaskPreviews_Call {
+	return &MockTaskPgRepo_ListTaskPreviews_Call{Call: _e.mock.On("ListTaskPreviews", ctx, q)}
 }
 
-func (_c *MockTaskPgRepo_ListTaskPreviews_Call) Run(run func(ctx context.Context, limit int, offset int)) *MockTaskPgRepo_ListTaskPreviews_Call {
+func (_c *MockTaskPgRepo_ListTaskPreviews_Call) Run(run func(ctx context.Context, q srvc.TaskPreviewQuery)) *MockTaskPgRepo_ListTaskPreviews_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int))
+		run(args[0].(context.Context), args[1].(srvc.TaskPreviewQuery))
+	})
+	return _c
+}
+
+func (_c *MockTaskPgRepo_ListTaskPreviews_Call) Return(_a0 []srvc.TaskPreview, _a1 int, _a2 error) *MockTaskPgRepo_ListTaskPreviews_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockTaskPgRepo_ListTaskPreviews_Call) RunAndReturn(run func(context.Context, srvc.TaskPreviewQuery) ([]srvc.TaskPreview, int, error)) *MockTaskPgRepo_ListTaskPreviews_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListTaskRevisions provides a mock function with given fields: ctx, taskId
+func (_m *MockTaskPgRepo) ListTaskRevisions(ctx context.Context, taskId string) ([]srvc.TaskRevision, error) {
+	ret := _m.Called(ctx, taskId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTaskRevisions")
+	}
+
+	var r0 []srvc.TaskRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]srvc.TaskRevision, error)); ok {
+		return rf(ctx, taskId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []srvc.TaskRevision); ok {
+		r0 = rf(ctx, taskId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]srvc.TaskRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, taskId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTaskPgRepo_ListTaskRevisions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTaskRevisions'
+type MockTaskPgRepo_ListTaskRevisions_Call struct {
+	*mock.Call
+}
+
+// ListTaskRevisions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - taskId string
+func (_e *MockTaskPgRepo_Expecter) ListTaskRevisions(ctx interface{}, taskId interface{}) *MockTaskPgRepo_ListTaskRevisions_Call {
+	return &MockTaskPgRepo_ListTaskRevisions_Call{Call: _e.mock.On("ListTaskRevisions", ctx, taskId)}
+}
+
+func (_c *MockTaskPgRepo_ListTaskRevisions_Call) Run(run func(ctx context.Context, taskId string)) *MockTaskPgRepo_ListTaskRevisions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockTaskPgRepo_ListTaskPreviews_Call) Return(_a0 []srvc.TaskPreview, _a1 error) *MockTaskPgRepo_ListTaskPreviews_Call {
+func (_c *MockTaskPgRepo_ListTaskRevisions_Call) Return(_a0 []srvc.TaskRevision, _a1 error) *MockTaskPgRepo_ListTaskRevisions_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockTaskPgRepo_ListTaskPreviews_Call) RunAndReturn(run func(context.Context, int, int) ([]srvc.TaskPreview, error)) *MockTaskPgRepo_ListTaskPreviews_Call {
+func (_c *MockTaskPgRepo_ListTaskRevisions_Call) RunAndReturn(run func(context.Context, string) ([]srvc.TaskRevision, error)) *MockTaskPgRepo_ListTaskRevisions_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -620,6 +920,162 @@ func (_c *MockTaskPgRepo_ResolveNames_Call) RunAndReturn(run func(context.Contex
 	return _c
 }
 
+// ReviseTask provides a mock function with given fields: ctx, prev, next
+func (_m *MockTaskPgRepo) ReviseTask(ctx context.Context, prev srvc.Task, next srvc.Task) error {
+	ret := _m.Called(ctx, prev, next)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReviseTask")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, srvc.Task, srvc.Task) error); ok {
+		r0 = rf(ctx, prev, next)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockTaskPgRepo_ReviseTask_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReviseTask'
+type MockTaskPgRepo_ReviseTask_Call struct {
+	*mock.Call
+}
+
+// ReviseTask is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prev srvc.Task
+//   - next srvc.Task
+func (_e *MockTaskPgRepo_Expecter) ReviseTask(ctx interface{}, prev interface{}, next interface{}) *MockTaskPgRepo_ReviseTask_Call {
+	return &MockTaskPgRepo_ReviseTask_Call{Call: _e.mock.On("ReviseTask", ctx, prev, next)}
+}
+
+func (_c *MockTaskPgRepo_ReviseTask_Call) Run(run func(ctx context.Context, prev srvc.Task, next srvc.Task)) *MockTaskPgRepo_ReviseTask_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(srvc.Task), args[2].(srvc.Task))
+	})
+	return _c
+}
+
+func (_c *MockTaskPgRepo_ReviseTask_Call) Return(_a0 error) *MockTaskPgRepo_ReviseTask_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockTaskPgRepo_ReviseTask_Call) RunAndReturn(run func(context.Context, srvc.Task, srvc.Task) error) *MockTaskPgRepo_ReviseTask_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveSolutionReport provides a mock function with given fields: ctx, report
+func (_m *MockTaskPgRepo) SaveSolutionReport(ctx context.Context, report srvc.SolutionReport) error {
+	ret := _m.Called(ctx, report)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSolutionReport")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, srvc.SolutionReport) error); ok {
+		r0 = rf(ctx, report)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockTaskPgRepo_SaveSolutionReport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveSolutionReport'
+type MockTaskPgRepo_SaveSolutionReport_Call struct {
+	*mock.Call
+}
+
+// SaveSolutionReport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - report srvc.SolutionReport
+func (_e *MockTaskPgRepo_Expecter) SaveSolutionReport(ctx interface{}, report interface{}) *MockTaskPgRepo_SaveSolutionReport_Call {
+	return &MockTaskPgRepo_SaveSolutionReport_Call{Call: _e.mock.On("SaveSolutionReport", ctx, report)}
+}
+
+func (_c *MockTaskPgRepo_SaveSolutionReport_Call) Run(run func(ctx context.Context, report srvc.SolutionReport)) *MockTaskPgRepo_SaveSolutionReport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(srvc.SolutionReport))
+	})
+	return _c
+}
+
+func (_c *MockTaskPgRepo_SaveSolutionReport_Call) Return(_a0 error) *MockTaskPgRepo_SaveSolutionReport_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockTaskPgRepo_SaveSolutionReport_Call) RunAndReturn(run func(context.Context, srvc.SolutionReport) error) *MockTaskPgRepo_SaveSolutionReport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchTasks provides a mock function with given fields: ctx, query, limit, includeUnlisted
+func (_m *MockTaskPgRepo) SearchTasks(ctx context.Context, query string, limit int, includeUnlisted bool) ([]srvc.TaskSearchHit, error) {
+	ret := _m.Called(ctx, query, limit, includeUnlisted)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchTasks")
+	}
+
+	var r0 []srvc.TaskSearchHit
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, bool) ([]srvc.TaskSearchHit, error)); ok {
+		return rf(ctx, query, limit, includeUnlisted)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, bool) []srvc.TaskSearchHit); ok {
+		r0 = rf(ctx, query, limit, includeUnlisted)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]srvc.TaskSearchHit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, bool) error); ok {
+		r1 = rf(ctx, query, limit, includeUnlisted)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockTaskPgRepo_SearchTasks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchTasks'
+type MockTaskPgRepo_SearchTasks_Call struct {
+	*mock.Call
+}
+
+// SearchTasks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - limit int
+//   - includeUnlisted bool
+func (_e *MockTaskPgRepo_Expecter) SearchTasks(ctx interface{}, query interface{}, limit interface{}, includeUnlisted interface{}) *MockTaskPgRepo_SearchTasks_Call {
+	return &MockTaskPgRepo_SearchTasks_Call{Call: _e.mock.On("SearchTasks", ctx, query, limit, includeUnlisted)}
+}
+
+func (_c *MockTaskPgRepo_SearchTasks_Call) Run(run func(ctx context.Context, query string, limit int, includeUnlisted bool)) *MockTaskPgRepo_SearchTasks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *MockTaskPgRepo_SearchTasks_Call) Return(_a0 []srvc.TaskSearchHit, _a1 error) *MockTaskPgRepo_SearchTasks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockTaskPgRepo_SearchTasks_Call) RunAndReturn(run func(context.Context, string, int, bool) ([]srvc.TaskSearchHit, error)) *MockTaskPgRepo_SearchTasks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SearchTasksByName provides a mock function with given fields: ctx, name
 func (_m *MockTaskPgRepo) SearchTasksByName(ctx context.Context, name string) ([]string, error) {
 	ret := _m.Called(ctx, name)
@@ -775,6 +1231,55 @@ func (_c *MockTaskPgRepo_UpdateStatement_Call) RunAndReturn(run func(context.Con
 	return _c
 }
 
+// UpdateVisibility provides a mock function with given fields: ctx, taskId, visibility, publishAt
+func (_m *MockTaskPgRepo) UpdateVisibility(ctx context.Context, taskId string, visibility srvc.Visibility, publishAt *time.Time) error {
+	ret := _m.Called(ctx, taskId, visibility, publishAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateVisibility")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, srvc.Visibility, *time.Time) error); ok {
+		r0 = rf(ctx, taskId, visibility, publishAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockTaskPgRepo_UpdateVisibility_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateVisibility'
+type MockTaskPgRepo_UpdateVisibility_Call struct {
+	*mock.Call
+}
+
+// UpdateVisibility is a helper method to define mock.On call
+//   - ctx context.Context
+//   - taskId string
+//   - visibility srvc.Visibility
+//   - publishAt *time.Time
+func (_e *MockTaskPgRepo_Expecter) UpdateVisibility(ctx interface{}, taskId interface{}, visibility interface{}, publishAt interface{}) *MockTaskPgRepo_UpdateVisibility_Call {
+	return &MockTaskPgRepo_UpdateVisibility_Call{Call: _e.mock.On("UpdateVisibility", ctx, taskId, visibility, publishAt)}
+}
+
+func (_c *MockTaskPgRepo_UpdateVisibility_Call) Run(run func(ctx context.Context, taskId string, visibility srvc.Visibility, publishAt *time.Time)) *MockTaskPgRepo_UpdateVisibility_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(srvc.Visibility), args[3].(*time.Time))
+	})
+	return _c
+}
+
+func (_c *MockTaskPgRepo_UpdateVisibility_Call) Return(_a0 error) *MockTaskPgRepo_UpdateVisibility_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockTaskPgRepo_UpdateVisibility_Call) RunAndReturn(run func(context.Context, string, srvc.Visibility, *time.Time) error) *MockTaskPgRepo_UpdateVisibility_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockTaskPgRepo creates a new instance of MockTaskPgRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockTaskPgRepo(t interface {
@@ -0,0 +1,63 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/programme-lv/backend/common/ctxlog"
+	"github.com/programme-lv/backend/common/srvcerror"
+)
+
+// CancelSubject is broadcast to every tester. The tester running the
+// execution stops it; the others ignore the message.
+const CancelSubject = "tester.cancel"
+
+// CancelRequest is the JSON payload published to CancelSubject.
+type CancelRequest struct {
+	EvalUuid string `json:"eval_uuid"`
+}
+
+// Cancel asks testers to stop the execution and finishes it with
+// the cancelled stage. Listeners receive a Cancelled event. Events
+// that the tester sends before it stops are dropped. With JetStream,
+// a job that no tester has fetched yet is deleted from the stream.
+func (e *execSrvc) Cancel(ctx context.Context, execUuid uuid.UUID) srvcerror.E {
+	l := ctxlog.FromContext(ctx).With("cmd", "cancel execution")
+
+	e.awaitPublish(ctx, execUuid)
+	e.mu.Lock()
+	_, running := e.executions[execUuid]
+	var jobSeq uint64
+	if a := e.attempts[execUuid]; a != nil {
+		jobSeq = a.pending.JobSeq
+	}
+	e.mu.Unlock()
+	if !running {
+		if _, err := e.execRepo.Get(ctx, execUuid); err == nil {
+			return ErrExecNotRunning
+		}
+		return ErrEvalNotFound
+	}
+
+	if e.js != nil && jobSeq != 0 {
+		e.deleteQueuedJob(ctx, execUuid, jobSeq)
+	}
+
+	data, err := json.Marshal(CancelRequest{EvalUuid: execUuid.String()})
+	if err != nil {
+		l.Error("marshal cancel request", "error", err)
+		return srvcerror.InternalServerError()
+	}
+	msg := nats.NewMsg(CancelSubject)
+	msg.Data = data
+	if err := e.publishCancel(msg); err != nil {
+		// the tester keeps running, but its events are dropped
+		l.Warn("publish cancel request", "exec_uuid", execUuid, "error", err)
+	}
+
+	e.handleEvent(ctx, execUuid, Cancelled{})
+	l.Info("cancelled execution", "exec_uuid", execUuid)
+	return nil
+}
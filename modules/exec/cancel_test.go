@@ -0,0 +1,61 @@
+package exec
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelFinishesRunningExecution(t *testing.T) {
+	repo := NewInMemExecRepo()
+	srvc := NewExecSrvc(t.Context(), repo, &nats.Conn{}, nil)
+	srvc.publishJob = func(*nats.Msg) error { return nil }
+	cancels := []*nats.Msg{}
+	srvc.publishCancel = func(msg *nats.Msg) error {
+		cancels = append(cancels, msg)
+		return nil
+	}
+
+	id := uuid.New()
+	content := "test"
+	err := srvc.Enqueue(t.Context(), id, "print(1)", "python3.13", []TestFile{{
+		InContent: &content, AnsContent: &content,
+	}}, TestingParams{CpuMs: 1000, MemKiB: 1024})
+	require.NoError(t, err)
+	events, err := srvc.Listen(t.Context(), id)
+	require.NoError(t, err)
+
+	require.NoError(t, srvc.Cancel(t.Context(), id))
+
+	require.Len(t, cancels, 1)
+	require.Equal(t, CancelSubject, cancels[0].Subject)
+	var req CancelRequest
+	require.NoError(t, json.Unmarshal(cancels[0].Data, &req))
+	require.Equal(t, id.String(), req.EvalUuid)
+
+	ev, ok := <-events
+	require.True(t, ok)
+	require.Equal(t, CancelledType, ev.Type())
+	_, ok = <-events
+	require.False(t, ok)
+
+	exec, err := srvc.Get(t.Context(), id)
+	require.NoError(t, err)
+	require.Equal(t, StageCancelled, exec.Stage)
+	srvc.mu.Lock()
+	require.NotContains(t, srvc.executions, id)
+	require.NotContains(t, srvc.organizers, id)
+	srvc.mu.Unlock()
+	replay, err := srvc.Subscribe(t.Context(), id, 0)
+	require.NoError(t, err)
+	require.Equal(t, SeqEvent{Seq: 1, Event: Cancelled{}}, <-replay)
+	pending, listErr := repo.ListPending(t.Context())
+	require.NoError(t, listErr)
+	require.Empty(t, pending)
+
+	require.ErrorIs(t, srvc.Cancel(t.Context(), id), ErrExecNotRunning)
+	require.ErrorIs(t, srvc.Cancel(t.Context(), uuid.New()), ErrEvalNotFound)
+}
@@ -0,0 +1,46 @@
+package exec
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Exit codes of testlib checkers.
+const (
+	checkerExitOk = 0
+	// quitp: stdout starts with the test's share of points in [0, 1]
+	checkerExitPoints = 7
+	// _pc(n) in TESTSYS mode exits with 50+n, n being a percentage
+	checkerExitPartialBase = 50
+)
+
+// CheckerScore maps a checker's exit code and stdout to the share of
+// the test's points in [0, 1]. Unrecognized results score zero.
+func CheckerScore(exitCode int64, stdout string) float64 {
+	switch {
+	case exitCode == checkerExitOk:
+		return 1
+	case exitCode == checkerExitPoints:
+		fields := strings.Fields(stdout)
+		if len(fields) == 0 {
+			return 0
+		}
+		score, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return 0
+		}
+		return clampScore(score)
+	case exitCode >= checkerExitPartialBase && exitCode <= checkerExitPartialBase+100:
+		return float64(exitCode-checkerExitPartialBase) / 100
+	default:
+		return 0
+	}
+}
+
+func clampScore(score float64) float64 {
+	if math.IsNaN(score) || score < 0 {
+		return 0
+	}
+	return min(score, 1)
+}
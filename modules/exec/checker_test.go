@@ -0,0 +1,18 @@
+package exec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckerScore(t *testing.T) {
+	assert.Equal(t, 1.0, CheckerScore(0, "ok"))
+	assert.Equal(t, 0.0, CheckerScore(1, "wrong answer"))
+	assert.Equal(t, 0.4, CheckerScore(7, "0.4 points for the prefix"))
+	assert.Equal(t, 1.0, CheckerScore(7, "2"))
+	assert.Equal(t, 0.0, CheckerScore(7, "partially"))
+	assert.Equal(t, 0.0, CheckerScore(7, "NaN"))
+	assert.Equal(t, 0.3, CheckerScore(80, ""))
+	assert.Equal(t, 0.0, CheckerScore(151, ""))
+}
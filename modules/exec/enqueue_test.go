@@ -66,10 +66,55 @@ func assertExecutionStateAbsent(t *testing.T, srvc *execSrvc, id uuid.UUID) {
 	t.Helper()
 	srvc.mu.Lock()
 	defer srvc.mu.Unlock()
-	require.NotContains(t, srvc.notifiers, id)
+	require.NotContains(t, srvc.logs, id)
 	require.NotContains(t, srvc.organizers, id)
 	require.NotContains(t, srvc.executions, id)
 	require.NotContains(t, srvc.fileHashes, id)
 	_, exists := srvc.execWg.Load(id)
 	require.False(t, exists)
 }
+
+func TestEnqueuePublishesLaneHeader(t *testing.T) {
+	srvc := NewExecSrvc(t.Context(), NewInMemExecRepo(), &nats.Conn{}, nil)
+	subjects := []string{}
+	lanes := []string{}
+	srvc.publishJob = func(msg *nats.Msg) error {
+		subjects = append(subjects, msg.Subject)
+		lanes = append(lanes, msg.Header.Get(laneHeader))
+		return nil
+	}
+
+	content := "test"
+	tests := []TestFile{{InContent: &content, AnsContent: &content}}
+	for _, lane := range []Lane{"", LaneHigh, LaneBulk} {
+		err := srvc.Enqueue(t.Context(), uuid.New(), "print(1)", "python3.13", tests,
+			TestingParams{CpuMs: 1000, MemKiB: 1024, Lane: lane})
+		require.NoError(t, err)
+	}
+
+	// deployed testers subscribe to the subject that predates lanes
+	require.Equal(t, []string{NatsSubject, NatsSubject, NatsSubject}, subjects)
+	require.Equal(t, []string{"high", "high", "bulk"}, lanes)
+}
+
+func TestPublishDoesNotHoldLock(t *testing.T) {
+	cfg := WatchdogConfig{TotalTimeout: time.Minute, EventTimeout: time.Second, MaxRetries: 1}
+	srvc := NewExecSrvc(t.Context(), NewInMemExecRepo(), &nats.Conn{}, nil, WithWatchdog(cfg))
+	published := 0
+	srvc.publishJob = func(*nats.Msg) error {
+		require.True(t, srvc.mu.TryLock(), "publishing must not block event handling")
+		srvc.mu.Unlock()
+		published++
+		return nil
+	}
+
+	id := uuid.New()
+	content := "test"
+	err := srvc.Enqueue(t.Context(), id, "print(1)", "python3.13", []TestFile{{
+		InContent: &content, AnsContent: &content,
+	}}, TestingParams{CpuMs: 1000, MemKiB: 1024})
+	require.NoError(t, err)
+	srvc.handleEvent(t.Context(), id, ReceivedSubmission{StartedAt: time.Now()})
+	srvc.checkDeadlines(t.Context(), time.Now().Add(time.Hour))
+	require.Equal(t, 2, published)
+}
@@ -41,7 +41,22 @@ var ErrEvalNotFound = srvcerror.New(
 	"izpilde netika atrasta",
 ).SetHttpStatusCode(http.StatusNotFound)
 
+var ErrExecNotRunning = srvcerror.New(
+	"exec_not_running",
+	"izpilde jau ir beigusies",
+).SetHttpStatusCode(http.StatusConflict)
+
 var ErrInvalidTestFile = srvcerror.New(
 	"invalid_test_file",
 	"nederīgs testa fails",
 ).SetHttpStatusCode(http.StatusBadRequest)
+
+var ErrInvalidSeedRange = srvcerror.New(
+	"invalid_seed_range",
+	"nederīgs sēklu intervāls",
+).SetHttpStatusCode(http.StatusBadRequest)
+
+var ErrInteractiveStressTest = srvcerror.New(
+	"interactive_stress_test",
+	"interaktīvus uzdevumus nevar stresa testēt",
+).SetHttpStatusCode(http.StatusBadRequest)
@@ -68,4 +68,97 @@ func (r *FileEvalRepo) Get(ctx context.Context, evalUuid uuid.UUID) (*Execution,
 	return &eval, nil
 }
 
+const pendingPrefix = "pending"
+
+func pendingKey(id uuid.UUID) string {
+	return fmt.Sprintf("%s/%s.json", pendingPrefix, id.String())
+}
+
+func (r *FileEvalRepo) SavePending(ctx context.Context, p *PendingExec) error {
+	if data, err := r.store.Download(pendingKey(p.UUID)); err == nil {
+		var prev PendingExec
+		if json.Unmarshal(data, &prev) == nil && !prev.claimableBy(p.Owner, time.Now()) {
+			return ErrPendingLeased
+		}
+	}
+	return r.uploadPending(p)
+}
+
+func (r *FileEvalRepo) uploadPending(p *PendingExec) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal pending execution: %w", err)
+	}
+	if _, err := r.store.Upload(data, pendingKey(p.UUID), "application/json"); err != nil {
+		return fmt.Errorf("store pending execution: %w", err)
+	}
+	return nil
+}
+
+func (r *FileEvalRepo) DeletePending(ctx context.Context, id uuid.UUID) error {
+	if err := r.store.Delete(pendingKey(id)); err != nil {
+		return fmt.Errorf("delete pending execution: %w", err)
+	}
+	return nil
+}
+
+func (r *FileEvalRepo) ListPending(ctx context.Context) ([]PendingExec, error) {
+	objects, err := r.store.List(pendingPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("list pending executions: %w", err)
+	}
+	pending := make([]PendingExec, 0, len(objects))
+	for _, obj := range objects {
+		data, err := r.store.Download(obj.Key)
+		if err != nil {
+			return nil, fmt.Errorf("get pending execution %s: %w", obj.Key, err)
+		}
+		var p PendingExec
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("unmarshal pending execution %s: %w", obj.Key, err)
+		}
+		pending = append(pending, p)
+	}
+	return pending, nil
+}
+
+// ClaimPending implements [ExecRepo]. The files are not locked,
+// so only one process may use the repository.
+func (r *FileEvalRepo) ClaimPending(ctx context.Context, owner string, now time.Time, leaseUntil time.Time) ([]PendingExec, error) {
+	pending, err := r.ListPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var claimed []PendingExec
+	for _, p := range pending {
+		if !p.claimableBy(owner, now) {
+			continue
+		}
+		claimed = append(claimed, p)
+		p.Owner, p.LeaseUntil = owner, leaseUntil
+		if err := r.uploadPending(&p); err != nil {
+			return nil, err
+		}
+	}
+	return claimed, nil
+}
+
+// RenewPending implements [ExecRepo].
+func (r *FileEvalRepo) RenewPending(ctx context.Context, owner string, leaseUntil time.Time) error {
+	pending, err := r.ListPending(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range pending {
+		if p.Owner != owner {
+			continue
+		}
+		p.LeaseUntil = leaseUntil
+		if err := r.uploadPending(&p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 var _ ExecRepo = &FileEvalRepo{}
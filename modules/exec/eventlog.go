@@ -0,0 +1,141 @@
+package exec
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// eventLogRetention is how long the event log of a finished execution
+// stays in memory for reconnecting subscribers. Older executions are
+// replayed from a snapshot of the stored result.
+const eventLogRetention = 10 * time.Minute
+
+// SeqEvent is an execution event together with its 1-based position
+// in the execution's event log. SSE endpoints use Seq as the event ID.
+type SeqEvent struct {
+	Seq   int
+	Event Event
+}
+
+// eventLog keeps every ordered event of one execution so that any
+// number of subscribers can replay it from an arbitrary position and
+// then follow the live tail.
+type eventLog struct {
+	mu     sync.Mutex
+	events []Event
+	closed bool
+	// changed is closed and replaced whenever events are appended
+	// or the log is closed
+	changed chan struct{}
+}
+
+func newEventLog() *eventLog {
+	return &eventLog{changed: make(chan struct{})}
+}
+
+// newClosedEventLog returns a finished log holding the given events.
+func newClosedEventLog(events []Event) *eventLog {
+	l := newEventLog()
+	l.append(events...)
+	l.close()
+	return l
+}
+
+func (l *eventLog) append(events ...Event) {
+	if len(events) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	l.events = append(l.events, events...)
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+func (l *eventLog) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	l.closed = true
+	close(l.changed)
+}
+
+// subscribe streams the events after the given sequence number and
+// closes the channel once the log is closed and drained or ctx ends.
+func (l *eventLog) subscribe(ctx context.Context, after int) <-chan SeqEvent {
+	out := make(chan SeqEvent)
+	go func() {
+		defer close(out)
+		next := max(after, 0)
+		for {
+			l.mu.Lock()
+			next = min(next, len(l.events))
+			pending := l.events[next:]
+			closed := l.closed
+			changed := l.changed
+			l.mu.Unlock()
+
+			for _, ev := range pending {
+				next++
+				select {
+				case out <- SeqEvent{Seq: next, Event: ev}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if len(pending) > 0 {
+				continue
+			}
+			if closed {
+				return
+			}
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// snapshotEvents describes a stored execution as the events that
+// would have produced it.
+func snapshotEvents(e *Execution) []Event {
+	var events []Event
+	if e.SysInfo != nil {
+		events = append(events, ReceivedSubmission{SysInfo: *e.SysInfo, StartedAt: e.CreatedAt})
+	}
+	if e.SubmComp != nil {
+		events = append(events, StartedCompiling{}, FinishedCompiling{RuntimeData: e.SubmComp})
+	}
+	for _, t := range e.TestRes {
+		if t.Ignored {
+			events = append(events, IgnoredTest{TestId: t.ID})
+			continue
+		}
+		if t.Reached {
+			events = append(events, ReachedTest{TestId: t.ID, In: t.Input, Ans: t.Answer})
+		}
+		if t.Finished {
+			events = append(events, FinishedTest{TestID: t.ID, Subm: t.Subm, Checker: t.Checker})
+		}
+	}
+	switch e.Stage {
+	case StageCompileError:
+		events = append(events, CompilationError{ErrorMsg: e.ErrorMsg})
+	case StageInternalError:
+		events = append(events, InternalServerError{ErrorMsg: e.ErrorMsg})
+	case StageCancelled:
+		events = append(events, Cancelled{})
+	default:
+		events = append(events, FinishedTesting{})
+	}
+	return events
+}
@@ -0,0 +1,63 @@
+package exec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func collect(ch <-chan SeqEvent) []SeqEvent {
+	var res []SeqEvent
+	for ev := range ch {
+		res = append(res, ev)
+	}
+	return res
+}
+
+func TestEventLogReplaysAndFollowsTail(t *testing.T) {
+	log := newEventLog()
+	log.append(ReceivedSubmission{}, StartedCompiling{})
+
+	first := log.subscribe(t.Context(), 0)
+	resumed := log.subscribe(t.Context(), 1)
+	require.Equal(t, SeqEvent{Seq: 1, Event: ReceivedSubmission{}}, <-first)
+	require.Equal(t, SeqEvent{Seq: 2, Event: StartedCompiling{}}, <-resumed)
+
+	log.append(FinishedTesting{})
+	log.close()
+
+	require.Equal(t, []SeqEvent{
+		{Seq: 2, Event: StartedCompiling{}},
+		{Seq: 3, Event: FinishedTesting{}},
+	}, collect(first))
+	require.Equal(t, []SeqEvent{{Seq: 3, Event: FinishedTesting{}}}, collect(resumed))
+	require.Empty(t, collect(log.subscribe(t.Context(), 3)))
+}
+
+func TestEventLogSubscriberStopsWithContext(t *testing.T) {
+	log := newEventLog()
+	ctx, cancel := context.WithCancel(t.Context())
+	ch := log.subscribe(ctx, 0)
+	cancel()
+	_, ok := <-ch
+	require.False(t, ok)
+}
+
+func TestSubscribeReplaysSnapshotOfStoredExecution(t *testing.T) {
+	repo := NewInMemExecRepo()
+	srvc := NewExecSrvc(t.Context(), repo, nil, nil)
+	exec := &Execution{
+		Stage:   StageFinished,
+		TestRes: []TestRes{{ID: 1, Reached: true, Finished: true}, {ID: 2, Ignored: true}},
+	}
+	require.NoError(t, repo.Save(t.Context(), exec))
+
+	ch, err := srvc.Subscribe(t.Context(), exec.UUID, 1)
+	require.NoError(t, err)
+	require.Equal(t, []SeqEvent{
+		{Seq: 2, Event: FinishedTest{TestID: 1}},
+		{Seq: 3, Event: IgnoredTest{TestId: 2}},
+		{Seq: 4, Event: FinishedTesting{}},
+	}, collect(ch))
+}
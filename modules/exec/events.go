@@ -20,6 +20,7 @@ const (
 	FinishedTestType        = "finished_test"
 	FinishedTestingType     = "finished_testing"
 	InternalServerErrorType = "internal_server_error"
+	CancelledType           = "cancelled"
 )
 
 type ReceivedSubmission struct {
@@ -180,3 +181,17 @@ func (s InternalServerError) MarshalJSON() ([]byte, error) {
 		payload
 	}{Type: s.Type(), payload: payload(s)})
 }
+
+type Cancelled struct{}
+
+var _ Event = Cancelled{}
+
+func (s Cancelled) Type() string {
+	return CancelledType
+}
+
+func (s Cancelled) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string `json:"type"`
+	}{Type: s.Type()})
+}
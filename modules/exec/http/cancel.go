@@ -0,0 +1,26 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/common/jsonresp"
+)
+
+func (h *ExecHttpHandler) execCancel(w http.ResponseWriter, r *http.Request) {
+	execUuidStr := chi.URLParam(r, "execUuid")
+	execUuid, err := uuid.Parse(execUuidStr)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.execSrvc.Cancel(r.Context(), execUuid); err != nil {
+		jsonresp.HandleSrvcError(slog.Default(), w, err)
+		return
+	}
+
+	jsonresp.Success(w, nil)
+}
@@ -3,17 +3,20 @@ package http
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/programme-lv/backend/modules/exec"
+	tasksrvc "github.com/programme-lv/backend/modules/task/srvc"
 	"github.com/programme-lv/backend/modules/user/auth"
 )
 
 type ExecHttpHandler struct {
 	execSrvc    exec.CodeExecutionService
+	taskSrvc    tasksrvc.TaskService
 	adminAPIKey []byte
 }
 
-func NewExecHttpHandler(execSrvc exec.CodeExecutionService, adminAPIKey []byte) *ExecHttpHandler {
+func NewExecHttpHandler(execSrvc exec.CodeExecutionService, taskSrvc tasksrvc.TaskService, adminAPIKey []byte) *ExecHttpHandler {
 	return &ExecHttpHandler{
 		execSrvc:    execSrvc,
+		taskSrvc:    taskSrvc,
 		adminAPIKey: adminAPIKey,
 	}
 }
@@ -23,6 +26,9 @@ func (h *ExecHttpHandler) RegisterRoutes(r *chi.Mux) {
 		r.Use(auth.HttpAllowOnlyAdmins(h.adminAPIKey))
 		r.Post("/tester/run", h.testerRun)
 		r.Get("/tester/run/{evalUuid}", h.testerListen)
+		r.Get("/testers", h.listTesters)
 		r.Get("/exec/{execUuid}", h.execGet)
+		r.Post("/exec/{execUuid}/cancel", h.execCancel)
+		r.Post("/exec/stress", h.execStress)
 	})
 }
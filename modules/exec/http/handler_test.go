@@ -32,12 +32,34 @@ func (fakeExecService) Listen(context.Context, uuid.UUID) (<-chan exec.Event, sr
 	return ch, nil
 }
 
+func (fakeExecService) Subscribe(context.Context, uuid.UUID, int) (<-chan exec.SeqEvent, srvcerror.E) {
+	ch := make(chan exec.SeqEvent)
+	close(ch)
+	return ch, nil
+}
+
 func (fakeExecService) Get(_ context.Context, execUUID uuid.UUID) (exec.Execution, srvcerror.E) {
 	return exec.Execution{UUID: execUUID}, nil
 }
 
+func (fakeExecService) HasPending(context.Context, uuid.UUID) bool {
+	return false
+}
+
+func (fakeExecService) ListPendingIDs(context.Context) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (fakeExecService) Cancel(context.Context, uuid.UUID) srvcerror.E {
+	return nil
+}
+
+func (fakeExecService) ListTesters(context.Context) []exec.TesterStatus {
+	return []exec.TesterStatus{}
+}
+
 func TestExecRoutesRequireAdminAuthentication(t *testing.T) {
-	handler := NewExecHttpHandler(fakeExecService{}, []byte("admin-api-key"))
+	handler := NewExecHttpHandler(fakeExecService{}, nil, []byte("admin-api-key"))
 	router := chi.NewRouter()
 	handler.RegisterRoutes(router)
 
@@ -47,7 +69,10 @@ func TestExecRoutesRequireAdminAuthentication(t *testing.T) {
 	}{
 		{method: http.MethodPost, path: "/tester/run"},
 		{method: http.MethodGet, path: "/tester/run/" + uuid.NewString()},
+		{method: http.MethodGet, path: "/testers"},
 		{method: http.MethodGet, path: "/exec/" + uuid.NewString()},
+		{method: http.MethodPost, path: "/exec/" + uuid.NewString() + "/cancel"},
+		{method: http.MethodPost, path: "/exec/stress"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
@@ -62,7 +87,7 @@ func TestExecRoutesRequireAdminAuthentication(t *testing.T) {
 }
 
 func TestExecGetAcceptsAdminAPIKey(t *testing.T) {
-	handler := NewExecHttpHandler(fakeExecService{}, []byte("admin-api-key"))
+	handler := NewExecHttpHandler(fakeExecService{}, nil, []byte("admin-api-key"))
 	router := chi.NewRouter()
 	handler.RegisterRoutes(router)
 
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/programme-lv/backend/common/jsonresp"
+	"github.com/programme-lv/backend/modules/exec"
+)
+
+// execStress searches a seed range for an input on which the candidate
+// disagrees with the reference solution, graded with the task's checker
+// and limits. It responds once the search stops, which may take a while
+// for large ranges.
+func (h *ExecHttpHandler) execStress(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		TaskID    string       `json:"task_id"`
+		Generator exec.Program `json:"generator"`
+		Reference exec.Program `json:"reference"`
+		Candidate exec.Program `json:"candidate"`
+		SeedFrom  int          `json:"seed_from"`
+		SeedTo    int          `json:"seed_to"`
+	}
+
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.taskSrvc.GetTask(r.Context(), req.TaskID)
+	if err != nil {
+		jsonresp.HandleSrvcError(slog.Default(), w, err)
+		return
+	}
+	if task.InteractorPtr() != nil {
+		jsonresp.HandleSrvcError(slog.Default(), w, exec.ErrInteractiveStressTest)
+		return
+	}
+
+	res, err := exec.StressTest(r.Context(), h.execSrvc, exec.StressTestReq{
+		Generator: req.Generator,
+		Reference: req.Reference,
+		Candidate: req.Candidate,
+		Checker:   task.CheckerPtr(),
+		CpuMs:     task.CpuMillis(),
+		MemKiB:    task.MemoryKiB(),
+		SeedFrom:  req.SeedFrom,
+		SeedTo:    req.SeedTo,
+	})
+	if err != nil {
+		jsonresp.HandleSrvcError(slog.Default(), w, err)
+		return
+	}
+
+	jsonresp.Success(w, res)
+}
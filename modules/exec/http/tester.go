@@ -3,9 +3,12 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/programme-lv/backend/common/jsonresp"
 	"github.com/programme-lv/backend/modules/exec"
@@ -79,26 +82,27 @@ func (h *ExecHttpHandler) testerRun(w http.ResponseWriter, r *http.Request) {
 	jsonresp.Success(w, res)
 }
 
+// testerListen streams execution events as SSE with their sequence
+// numbers as event IDs. A reconnecting client sends Last-Event-ID and
+// receives only the events it missed.
 func (h *ExecHttpHandler) testerListen(w http.ResponseWriter, r *http.Request) {
-	type request struct {
-		ExecUuid string `json:"exec_uuid"`
-	}
-
-	var req request
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-		return
-	}
-
-	execUuid, err := uuid.Parse(req.ExecUuid)
+	execUuid, err := uuid.Parse(chi.URLParam(r, "evalUuid"))
 	if err != nil {
 		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
+	afterSeq := 0
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+		afterSeq, err = strconv.Atoi(lastID)
+		if err != nil || afterSeq < 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
 
-	ch, err := h.execSrvc.Listen(context.Background(), execUuid)
-	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	ch, srvcErr := h.execSrvc.Subscribe(r.Context(), execUuid, afterSeq)
+	if srvcErr != nil {
+		jsonresp.HandleSrvcError(slog.Default(), w, srvcErr)
 		return
 	}
 
@@ -119,28 +123,19 @@ func (h *ExecHttpHandler) testerListen(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 
-	encoder := json.NewEncoder(w)
-
 	if f, ok := w.(http.Flusher); ok {
 		f.Flush()
 	}
 
-	notify := r.Context().Done()
-
-	for {
-		select {
-		case event, ok := <-ch:
-			if !ok {
-				return
-			}
-			w.Write([]byte("data: "))
-			encoder.Encode(event)
-			w.Write([]byte("\n\n"))
-			if f, ok := w.(http.Flusher); ok {
-				f.Flush()
-			}
-		case <-notify:
+	for ev := range ch {
+		data, err := json.Marshal(ev.Event)
+		if err != nil {
+			slog.Default().Error("marshal execution event", "exec_uuid", execUuid, "error", err)
 			return
 		}
+		fmt.Fprintf(w, "id: %d\ndata: %s\n\n", ev.Seq, data)
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
 	}
 }
@@ -0,0 +1,11 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/programme-lv/backend/common/jsonresp"
+)
+
+func (h *ExecHttpHandler) listTesters(w http.ResponseWriter, r *http.Request) {
+	jsonresp.Success(w, h.execSrvc.ListTesters(r.Context()))
+}
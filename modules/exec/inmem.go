@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -11,6 +12,7 @@ import (
 type InMemExecRepo struct {
 	mu         sync.Mutex
 	executions map[uuid.UUID]*Execution
+	pending    map[uuid.UUID]PendingExec
 }
 
 var _ ExecRepo = &InMemExecRepo{}
@@ -18,6 +20,7 @@ var _ ExecRepo = &InMemExecRepo{}
 func NewInMemExecRepo() *InMemExecRepo {
 	return &InMemExecRepo{
 		executions: make(map[uuid.UUID]*Execution),
+		pending:    make(map[uuid.UUID]PendingExec),
 	}
 }
 
@@ -40,4 +43,63 @@ func (i *InMemExecRepo) Save(ctx context.Context, exec *Execution) error {
 	return nil
 }
 
+// SavePending implements [ExecRepo].
+func (i *InMemExecRepo) SavePending(ctx context.Context, p *PendingExec) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if prev, ok := i.pending[p.UUID]; ok && !prev.claimableBy(p.Owner, time.Now()) {
+		return ErrPendingLeased
+	}
+	i.pending[p.UUID] = *p
+	return nil
+}
+
+// DeletePending implements [ExecRepo].
+func (i *InMemExecRepo) DeletePending(ctx context.Context, id uuid.UUID) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.pending, id)
+	return nil
+}
+
+// ListPending implements [ExecRepo].
+func (i *InMemExecRepo) ListPending(ctx context.Context) ([]PendingExec, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	pending := make([]PendingExec, 0, len(i.pending))
+	for _, p := range i.pending {
+		pending = append(pending, p)
+	}
+	return pending, nil
+}
+
+// ClaimPending implements [ExecRepo].
+func (i *InMemExecRepo) ClaimPending(ctx context.Context, owner string, now time.Time, leaseUntil time.Time) ([]PendingExec, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	var claimed []PendingExec
+	for id, p := range i.pending {
+		if !p.claimableBy(owner, now) {
+			continue
+		}
+		claimed = append(claimed, p)
+		p.Owner, p.LeaseUntil = owner, leaseUntil
+		i.pending[id] = p
+	}
+	return claimed, nil
+}
+
+// RenewPending implements [ExecRepo].
+func (i *InMemExecRepo) RenewPending(ctx context.Context, owner string, leaseUntil time.Time) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for id, p := range i.pending {
+		if p.Owner == owner {
+			p.LeaseUntil = leaseUntil
+			i.pending[id] = p
+		}
+	}
+	return nil
+}
+
 var _ ExecRepo = &InMemExecRepo{}
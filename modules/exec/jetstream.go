@@ -0,0 +1,247 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// JetStream transport keeps queued jobs and undelivered results in
+// streams, so they survive restarts of the backend and of NATS.
+const (
+	JobStreamName    = "TESTER_JOBS"
+	ResultStreamName = "EXEC_RESULTS"
+
+	// testers pull jobs of each lane from these durable consumers
+	JobConsumerHigh = "workers-high"
+	JobConsumerBulk = "workers-bulk"
+
+	resultSubjectPrefix = "exec.results."
+	fileSubjectPrefix   = "exec.files."
+)
+
+// replySubjectHeader names the result subject of a job. JetStream
+// does not keep the reply field of stored messages.
+const replySubjectHeader = "Proglv-Reply-Subject"
+
+const (
+	// resultAckWait is how long a received result may stay unacked
+	// before it is redelivered. Results are acked once the finished
+	// execution is saved and marked in progress every
+	// resultProgressInterval until then. The wait stays well below
+	// the watchdog's EventTimeout, so that the results a stopped
+	// process held come back before the resumed execution is retried.
+	// Redelivered duplicates are dropped.
+	resultAckWait          = 5 * time.Second
+	resultProgressInterval = resultAckWait / 3
+	// results of executions that this process does not track yet are
+	// redelivered a few times, since ResumePending may still claim them
+	resultRedeliveryDelay = time.Second
+	maxResultDeliveries   = 30
+	resultRetention       = 24 * time.Hour
+)
+
+// JetStreamConfig configures the durable execution transport.
+type JetStreamConfig struct {
+	// Name identifies the backend process across restarts. It names the
+	// result subject and consumer and owns the pending executions of the
+	// process, so replicas need distinct names.
+	Name string
+	// Replicas of the job and result streams in a NATS cluster.
+	Replicas int
+}
+
+// WithJetStream publishes jobs to the TESTER_JOBS work-queue stream and
+// receives results from a durable consumer of the EXEC_RESULTS stream
+// instead of Core NATS subjects. StartPollingResultQueue creates the
+// streams and consumers.
+func WithJetStream(cfg JetStreamConfig) ExecSrvcOption {
+	return func(e *execSrvc) {
+		if cfg.Replicas < 1 {
+			cfg.Replicas = 1
+		}
+		// New only fails on invalid options
+		js, _ := jetstream.New(e.natsConn)
+		e.js = js
+		e.jsCfg = cfg
+		e.replicaID = cfg.Name
+		e.natsInbox = resultSubjectPrefix + cfg.Name
+		e.fileSubject = fileSubjectPrefix + cfg.Name
+	}
+}
+
+// publishJetStreamJob stores the job in the work-queue stream and
+// records its sequence, so that Cancel can delete the job while no
+// tester has fetched it. The sequence is saved with the pending
+// execution to outlive restarts.
+func (e *execSrvc) publishJetStreamJob(ctx context.Context, execUuid uuid.UUID, msg *nats.Msg) error {
+	ack, err := e.js.PublishMsg(ctx, msg)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	a, ok := e.attempts[execUuid]
+	var pending PendingExec
+	if ok {
+		a.pending.JobSeq = ack.Sequence
+		pending = a.pending
+	}
+	e.mu.Unlock()
+	if !ok {
+		return nil // finished meanwhile
+	}
+	pending.LeaseUntil = time.Now().Add(PendingLeaseTTL)
+	if err := e.execRepo.SavePending(ctx, &pending); err != nil {
+		e.logger.Warn("save job sequence", "exec_uuid", execUuid, "error", err)
+	}
+	return nil
+}
+
+// deleteQueuedJob removes the execution's job from the work-queue
+// stream. The deletion fails for a job that a tester has already
+// acked, which is gone anyway.
+func (e *execSrvc) deleteQueuedJob(ctx context.Context, execUuid uuid.UUID, seq uint64) {
+	stream, err := e.js.Stream(ctx, JobStreamName)
+	if err == nil {
+		err = stream.DeleteMsg(ctx, seq)
+	}
+	if err != nil && !errors.Is(err, jetstream.ErrMsgDeleteUnsuccessful) {
+		e.logger.Warn("delete queued job", "exec_uuid", execUuid, "seq", seq, "error", err)
+	}
+}
+
+// setupJetStream creates or updates the streams and consumers
+// that the transport relies on.
+func (e *execSrvc) setupJetStream(ctx context.Context) (jetstream.Consumer, error) {
+	_, err := e.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      JobStreamName,
+		Subjects:  []string{NatsSubjectHigh, NatsSubjectBulk},
+		Retention: jetstream.WorkQueuePolicy,
+		Storage:   jetstream.FileStorage,
+		Replicas:  e.jsCfg.Replicas,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create job stream: %w", err)
+	}
+	for consumer, subject := range map[string]string{
+		JobConsumerHigh: NatsSubjectHigh,
+		JobConsumerBulk: NatsSubjectBulk,
+	} {
+		_, err := e.js.CreateOrUpdateConsumer(ctx, JobStreamName, jetstream.ConsumerConfig{
+			Durable:       consumer,
+			FilterSubject: subject,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create job consumer %s: %w", consumer, err)
+		}
+	}
+
+	_, err = e.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     ResultStreamName,
+		Subjects: []string{resultSubjectPrefix + ">"},
+		Storage:  jetstream.FileStorage,
+		Replicas: e.jsCfg.Replicas,
+		MaxAge:   resultRetention,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create result stream: %w", err)
+	}
+	results, err := e.js.CreateOrUpdateConsumer(ctx, ResultStreamName, jetstream.ConsumerConfig{
+		Durable:       e.jsCfg.Name,
+		FilterSubject: e.natsInbox,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       resultAckWait,
+		MaxAckPending: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create result consumer: %w", err)
+	}
+	return results, nil
+}
+
+// consumeJetStreamResults delivers results from the durable consumer
+// until the returned stop function is called.
+func (e *execSrvc) consumeJetStreamResults(ctx context.Context) (func(), error) {
+	results, err := e.setupJetStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cc, err := results.Consume(func(msg jetstream.Msg) {
+		e.handleJetStreamResult(ctx, msg)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("consume results: %w", err)
+	}
+	return cc.Stop, nil
+}
+
+func (e *execSrvc) handleJetStreamResult(ctx context.Context, msg jetstream.Msg) {
+	execUUID, event, ok := e.parseResult(msg.Data())
+	if !ok {
+		_ = msg.Term()
+		return
+	}
+
+	e.mu.Lock()
+	_, tracked := e.executions[execUUID]
+	if tracked {
+		e.resultAcks[execUUID] = append(e.resultAcks[execUUID], msg)
+	}
+	e.mu.Unlock()
+	if tracked {
+		e.handleEvent(ctx, execUUID, event)
+		return
+	}
+
+	if _, err := e.execRepo.Get(ctx, execUUID); err == nil {
+		_ = msg.Ack() // redelivered after the execution was saved
+		return
+	}
+	meta, err := msg.Metadata()
+	if err == nil && meta.NumDelivered < maxResultDeliveries {
+		_ = msg.NakWithDelay(resultRedeliveryDelay)
+		return
+	}
+	e.logger.Warn("dropping result of unknown execution", "exec_uuid", execUUID)
+	_ = msg.Term()
+}
+
+// holdResults marks the JetStream results of running executions
+// as in progress, so that they are not redelivered before they are
+// acked. Only results of a stopped process come back.
+func (e *execSrvc) holdResults() {
+	e.mu.Lock()
+	var msgs []jetstream.Msg
+	for _, held := range e.resultAcks {
+		msgs = append(msgs, held...)
+	}
+	e.mu.Unlock()
+	for _, msg := range msgs {
+		if err := msg.InProgress(); err != nil {
+			e.logger.Warn("mark execution result in progress", "error", err)
+		}
+	}
+}
+
+// settleResults acks the JetStream results of a finished execution
+// once it is saved, or forgets them so that they are redelivered.
+func (e *execSrvc) settleResults(execUUID uuid.UUID, saved bool) {
+	e.mu.Lock()
+	msgs := e.resultAcks[execUUID]
+	delete(e.resultAcks, execUUID)
+	e.mu.Unlock()
+	if !saved {
+		return
+	}
+	for _, msg := range msgs {
+		if err := msg.Ack(); err != nil {
+			e.logger.Warn("ack execution result", "exec_uuid", execUUID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	testerapi "github.com/programme-lv/tester/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runJetStreamServer starts an embedded NATS server with JetStream
+// storing its streams in a temporary directory.
+func runJetStreamServer(t *testing.T) *nats.Conn {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+	go ns.Start()
+	t.Cleanup(ns.Shutdown)
+	require.True(t, ns.ReadyForConnections(5*time.Second))
+
+	nc, err := nats.Connect(ns.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(nc.Close)
+	return nc
+}
+
+func startJetStreamSrvc(t *testing.T, ctx context.Context, nc *nats.Conn, repo ExecRepo) *execSrvc {
+	t.Helper()
+	srvc := NewExecSrvc(ctx, repo, nc, nil, WithJetStream(JetStreamConfig{Name: "test"}))
+	require.NoError(t, srvc.StartPollingResultQueue(ctx))
+	return srvc
+}
+
+// publishFinishJob plays a tester finishing a job without tests.
+func publishFinishJob(t *testing.T, nc *nats.Conn, subject string, id uuid.UUID) {
+	t.Helper()
+	publishResult(t, nc, subject, map[string]any{
+		"eval_uuid": id.String(),
+		"msg_type":  testerapi.FinishJobMsg,
+	})
+}
+
+// publishStartJob plays a tester receiving a job.
+func publishStartJob(t *testing.T, nc *nats.Conn, subject string, id uuid.UUID) {
+	t.Helper()
+	publishResult(t, nc, subject, map[string]any{
+		"eval_uuid":    id.String(),
+		"msg_type":     testerapi.StartJobMsg,
+		"system_info":  "tester-1",
+		"started_time": time.Now().Format(time.RFC3339),
+	})
+}
+
+func publishResult(t *testing.T, nc *nats.Conn, subject string, msg map[string]any) {
+	t.Helper()
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	require.NoError(t, nc.Publish(subject, data))
+}
+
+func requireConsumerPending(t *testing.T, js jetstream.JetStream, stream, consumer string, pending, ackPending int) {
+	t.Helper()
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		info, err := js.Consumer(t.Context(), stream, consumer)
+		if !assert.NoError(c, err) {
+			return
+		}
+		assert.Equal(c, uint64(pending), info.CachedInfo().NumPending)
+		assert.Equal(c, ackPending, info.CachedInfo().NumAckPending)
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestJetStreamResultsAckedAfterSave(t *testing.T) {
+	nc := runJetStreamServer(t)
+	repo := NewInMemExecRepo()
+	srvc := startJetStreamSrvc(t, t.Context(), nc, repo)
+
+	id := uuid.New()
+	err := srvc.Enqueue(t.Context(), id, "print(1)", "python3.13", nil,
+		TestingParams{CpuMs: 1000, MemKiB: 1024})
+	require.Nil(t, err)
+
+	js, jsErr := jetstream.New(nc)
+	require.NoError(t, jsErr)
+	workers, jsErr := js.Consumer(t.Context(), JobStreamName, JobConsumerHigh)
+	require.NoError(t, jsErr)
+	batch, jsErr := workers.Fetch(1, jetstream.FetchMaxWait(time.Second))
+	require.NoError(t, jsErr)
+	job := <-batch.Messages()
+	require.NotNil(t, job)
+	require.Equal(t, "exec.results.test", job.Headers().Get(replySubjectHeader))
+	require.Equal(t, "exec.files.test", job.Headers().Get(fileSubjectHeader))
+	require.NoError(t, job.Ack())
+
+	publishFinishJob(t, nc, job.Headers().Get(replySubjectHeader), id)
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	res, err := srvc.Get(ctx, id)
+	require.Nil(t, err)
+	require.Equal(t, StageFinished, res.Stage)
+
+	requireConsumerPending(t, js, ResultStreamName, "test", 0, 0)
+}
+
+func TestJetStreamJobsAndResultsSurviveBackendRestart(t *testing.T) {
+	nc := runJetStreamServer(t)
+	repo := NewInMemExecRepo()
+
+	firstCtx, stopFirst := context.WithCancel(t.Context())
+	first := startJetStreamSrvc(t, firstCtx, nc, repo)
+	id := uuid.New()
+	err := first.Enqueue(t.Context(), id, "print(1)", "python3.13", nil,
+		TestingParams{CpuMs: 1000, MemKiB: 1024})
+	require.Nil(t, err)
+	stopFirst()
+	require.Eventually(t, func() bool { return !first.isPolling.Load() },
+		time.Second, 10*time.Millisecond)
+
+	pending, pendErr := repo.ListPending(t.Context())
+	require.NoError(t, pendErr)
+	require.Equal(t, uint64(1), pending[0].JobSeq, "the job sequence is saved")
+
+	// the tester finishes while no backend is running
+	publishFinishJob(t, nc, resultSubjectPrefix+"test", id)
+
+	second := startJetStreamSrvc(t, t.Context(), nc, repo)
+	require.NoError(t, second.ResumePending(t.Context()))
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+	res, err := second.Get(ctx, id)
+	require.Nil(t, err)
+	require.Equal(t, StageFinished, res.Stage)
+
+	js, jsErr := jetstream.New(nc)
+	require.NoError(t, jsErr)
+	// the job was not published again
+	requireConsumerPending(t, js, JobStreamName, JobConsumerHigh, 1, 0)
+	requireConsumerPending(t, js, ResultStreamName, "test", 0, 0)
+}
+
+func TestJetStreamHeldResultsSurviveBackendRestart(t *testing.T) {
+	nc := runJetStreamServer(t)
+	repo := NewInMemExecRepo()
+	js, jsErr := jetstream.New(nc)
+	require.NoError(t, jsErr)
+	results := resultSubjectPrefix + "test"
+
+	firstCtx, stopFirst := context.WithCancel(t.Context())
+	first := startJetStreamSrvc(t, firstCtx, nc, repo)
+	id := uuid.New()
+	content := "1"
+	err := first.Enqueue(t.Context(), id, "print(1)", "python3.13", []TestFile{{
+		InContent: &content, AnsContent: &content,
+	}}, TestingParams{CpuMs: 1000, MemKiB: 1024})
+	require.Nil(t, err)
+
+	// the first results are delivered and held until the execution is saved
+	publishStartJob(t, nc, results, id)
+	publishResult(t, nc, results, map[string]any{
+		"eval_uuid": id.String(), "msg_type": testerapi.ReachTestMsg, "TestId": 1,
+	})
+	requireConsumerPending(t, js, ResultStreamName, "test", 0, 2)
+	time.Sleep(resultAckWait + resultProgressInterval)
+	info, jsErr := js.Consumer(t.Context(), ResultStreamName, "test")
+	require.NoError(t, jsErr)
+	require.Zero(t, info.CachedInfo().NumRedelivered, "held results are kept in progress")
+
+	stopFirst()
+	require.Eventually(t, func() bool { return !first.isPolling.Load() },
+		time.Second, 10*time.Millisecond)
+	publishResult(t, nc, results, map[string]any{
+		"eval_uuid": id.String(), "msg_type": testerapi.FinishTestMsg, "TestId": 1,
+		"Submission": map[string]any{"Stdout": "1"},
+	})
+	publishFinishJob(t, nc, results, id)
+
+	// the later results wait for the held ones, which are
+	// redelivered before the watchdog retries the job
+	second := startJetStreamSrvc(t, t.Context(), nc, repo)
+	require.NoError(t, second.ResumePending(t.Context()))
+	ctx, cancel := context.WithTimeout(t.Context(), 3*resultAckWait)
+	defer cancel()
+	res, err := second.Get(ctx, id)
+	require.Nil(t, err)
+	require.Equal(t, StageFinished, res.Stage)
+	require.NotNil(t, res.SysInfo)
+	require.NotNil(t, res.TestRes[0].Subm)
+
+	requireConsumerPending(t, js, JobStreamName, JobConsumerHigh, 1, 0)
+	requireConsumerPending(t, js, ResultStreamName, "test", 0, 0)
+}
+
+func TestJetStreamCancelDeletesQueuedJob(t *testing.T) {
+	nc := runJetStreamServer(t)
+	repo := NewInMemExecRepo()
+	srvc := startJetStreamSrvc(t, t.Context(), nc, repo)
+	js, jsErr := jetstream.New(nc)
+	require.NoError(t, jsErr)
+
+	queued, started := uuid.New(), uuid.New()
+	for _, id := range []uuid.UUID{started, queued} {
+		err := srvc.Enqueue(t.Context(), id, "print(1)", "python3.13", nil,
+			TestingParams{CpuMs: 1000, MemKiB: 1024})
+		require.Nil(t, err)
+	}
+	workers, jsErr := js.Consumer(t.Context(), JobStreamName, JobConsumerHigh)
+	require.NoError(t, jsErr)
+	batch, jsErr := workers.Fetch(1, jetstream.FetchMaxWait(time.Second))
+	require.NoError(t, jsErr)
+	job := <-batch.Messages()
+	require.NotNil(t, job)
+	require.NoError(t, job.Ack())
+
+	require.Nil(t, srvc.Cancel(t.Context(), started))
+	require.Nil(t, srvc.Cancel(t.Context(), queued))
+	requireConsumerPending(t, js, JobStreamName, JobConsumerHigh, 0, 0)
+	stream, jsErr := js.Stream(t.Context(), JobStreamName)
+	require.NoError(t, jsErr)
+	require.Zero(t, stream.CachedInfo().State.Msgs)
+}
@@ -0,0 +1,70 @@
+package exec
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Lane is the priority of an execution job. Core NATS queue groups
+// have no priority, so there the lane only labels the job; JetStream
+// testers may prefer the high lane's consumer.
+type Lane string
+
+const (
+	// LaneHigh carries live submissions and is the default.
+	LaneHigh Lane = "high"
+	// LaneBulk carries re-evaluations and other batch work.
+	LaneBulk Lane = "bulk"
+)
+
+// NatsSubject is the Core NATS job subject. Deployed testers subscribe
+// to it in the workers queue group; the lane travels in laneHeader.
+const NatsSubject = "tester.jobs"
+
+// JetStream job subjects, one per lane consumer.
+const (
+	NatsSubjectHigh = "tester.jobs.high"
+	NatsSubjectBulk = "tester.jobs.bulk"
+)
+
+const laneHeader = "Proglv-Lane"
+
+var lanes = []Lane{LaneHigh, LaneBulk}
+
+func (l Lane) subject() string {
+	if l == LaneBulk {
+		return NatsSubjectBulk
+	}
+	return NatsSubjectHigh
+}
+
+func (l Lane) label() string {
+	if l == LaneBulk {
+		return string(LaneBulk)
+	}
+	return string(LaneHigh)
+}
+
+var queueDepth = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "exec_queue_depth",
+		Help: "Execution jobs published by this backend process that no tester has started yet.",
+	},
+	[]string{"lane"},
+)
+
+// reportQueueDepth counts jobs still waiting for their first event.
+func (e *execSrvc) reportQueueDepth() {
+	depth := make(map[string]int, len(lanes))
+	e.mu.Lock()
+	for _, a := range e.attempts {
+		if a.startedAt.IsZero() {
+			depth[a.pending.Params.Lane.label()]++
+		}
+	}
+	e.mu.Unlock()
+
+	for _, l := range lanes {
+		queueDepth.WithLabelValues(l.label()).Set(float64(depth[l.label()]))
+	}
+}
@@ -0,0 +1,153 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/common/ctxlog"
+)
+
+// PendingExec is the durable record of an enqueued execution that has
+// not finished yet. It holds everything needed to publish the job again
+// after the backend process restarts.
+type PendingExec struct {
+	UUID       uuid.UUID     `json:"uuid"`
+	SrcCode    string        `json:"src_code"`
+	LangId     string        `json:"lang_id"`
+	Tests      []TestFile    `json:"tests"`
+	Params     TestingParams `json:"params"`
+	EnqueuedAt time.Time     `json:"enqueued_at"`
+
+	// Owner is the replica that publishes the job and receives its
+	// results. Another replica takes the execution over once the
+	// lease runs out, e.g. because the owner stopped.
+	Owner      string    `json:"owner"`
+	LeaseUntil time.Time `json:"lease_until"`
+
+	// JobSeq is the sequence of the job in the JetStream work-queue
+	// stream, zero until it is published there.
+	JobSeq uint64 `json:"job_seq,omitempty"`
+}
+
+// PendingLeaseTTL is how long a pending execution stays with its owner
+// after the last renewal. Owners renew their leases every third of it.
+const PendingLeaseTTL = time.Minute
+
+// claimableBy reports whether owner holds the lease of p
+// or the lease ended before now.
+func (p PendingExec) claimableBy(owner string, now time.Time) bool {
+	return p.Owner == owner || p.LeaseUntil.Before(now)
+}
+
+// ErrPendingLeased is returned when saving a pending execution
+// whose lease another live replica holds.
+var ErrPendingLeased = errors.New("pending execution is leased by another replica")
+
+// ResumePending takes over the unfinished executions whose owner has
+// not renewed its lease, such as those of a previous backend process
+// or of a stopped replica, and re-publishes them. The owner's inbox
+// is gone, so the execution restarts from scratch under the same UUID.
+// With the JetStream transport the queued job and its results outlive
+// the process, so an execution this replica already owned under the
+// same name is only tracked again.
+//
+// Executions leased by live replicas are left alone. Call it after
+// StartPollingResultQueue so the new results are received, and again
+// every PendingLeaseTTL to take over the executions of stopped replicas.
+func (e *execSrvc) ResumePending(ctx context.Context) error {
+	log := ctxlog.FromContext(ctx).With("cmd", "resume pending executions")
+
+	now := time.Now()
+	pending, err := e.execRepo.ClaimPending(ctx, e.replicaID, now, now.Add(PendingLeaseTTL))
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pending {
+		e.mu.Lock()
+		_, inFlight := e.executions[p.UUID]
+		e.mu.Unlock()
+		if inFlight {
+			continue
+		}
+
+		if _, err := e.execRepo.Get(ctx, p.UUID); err == nil {
+			// finished, but the pending record outlived the process
+			if err := e.execRepo.DeletePending(ctx, p.UUID); err != nil {
+				log.Error("delete pending execution", "exec_uuid", p.UUID, "error", err)
+			}
+			continue
+		}
+
+		if e.js != nil && p.Owner == e.replicaID {
+			if err := e.track(ctx, p); err != nil {
+				log.Error("track pending execution", "exec_uuid", p.UUID, "error", err)
+				continue
+			}
+			log.Info("tracking pending execution", "exec_uuid", p.UUID, "enqueued_at", p.EnqueuedAt)
+			continue
+		}
+		if err := e.publish(ctx, p); err != nil {
+			log.Error("re-enqueue pending execution", "exec_uuid", p.UUID, "error", err)
+			continue
+		}
+		log.Info("re-enqueued pending execution", "exec_uuid", p.UUID,
+			"enqueued_at", p.EnqueuedAt, "previous_owner", p.Owner)
+	}
+
+	return nil
+}
+
+// renewLeases keeps the pending executions of this replica
+// from being taken over.
+func (e *execSrvc) renewLeases(ctx context.Context, now time.Time) {
+	if err := e.execRepo.RenewPending(ctx, e.replicaID, now.Add(PendingLeaseTTL)); err != nil {
+		e.logger.Error("renew pending execution leases", "error", err)
+	}
+}
+
+// ListPendingIDs returns the executions that any replica
+// has enqueued and not finished yet.
+func (e *execSrvc) ListPendingIDs(ctx context.Context) ([]uuid.UUID, error) {
+	pending, err := e.execRepo.ListPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(pending))
+	for i, p := range pending {
+		ids[i] = p.UUID
+	}
+	return ids, nil
+}
+
+// HasPending reports whether the execution is enqueued
+// and has not finished yet in this backend process.
+func (e *execSrvc) HasPending(ctx context.Context, execUuid uuid.UUID) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.executions[execUuid]
+	return ok
+}
+
+// ListPendingTestSha256s returns the input and answer hashes of the
+// tests of pending executions, whose test files must stay downloadable.
+func ListPendingTestSha256s(ctx context.Context, repo ExecRepo) ([]string, error) {
+	pending, err := repo.ListPending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var sha256s []string
+	for _, p := range pending {
+		for _, test := range p.Tests {
+			if test.InSha256 != nil {
+				sha256s = append(sha256s, *test.InSha256)
+			}
+			if test.AnsSha256 != nil {
+				sha256s = append(sha256s, *test.AnsSha256)
+			}
+		}
+	}
+	return sha256s, nil
+}
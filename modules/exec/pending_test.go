@@ -0,0 +1,131 @@
+package exec
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnqueueRecordsPendingExecution(t *testing.T) {
+	repo := NewInMemExecRepo()
+	srvc := NewExecSrvc(t.Context(), repo, &nats.Conn{}, nil)
+	srvc.publishJob = func(*nats.Msg) error { return nil }
+
+	id := uuid.New()
+	content := "test"
+	err := srvc.Enqueue(t.Context(), id, "print(1)", "python3.13", []TestFile{{
+		InContent: &content, AnsContent: &content,
+	}}, TestingParams{CpuMs: 1000, MemKiB: 1024})
+	require.NoError(t, err)
+
+	pending, listErr := repo.ListPending(t.Context())
+	require.NoError(t, listErr)
+	require.Len(t, pending, 1)
+	require.Equal(t, id, pending[0].UUID)
+	require.Equal(t, "print(1)", pending[0].SrcCode)
+	require.Equal(t, "python3.13", pending[0].LangId)
+}
+
+func TestEnqueuePublishFailureDropsPendingExecution(t *testing.T) {
+	repo := NewInMemExecRepo()
+	srvc := NewExecSrvc(t.Context(), repo, &nats.Conn{}, nil)
+	srvc.publishJob = func(*nats.Msg) error { return errors.New("publish unavailable") }
+
+	content := "test"
+	err := srvc.Enqueue(t.Context(), uuid.New(), "print(1)", "python3.13", []TestFile{{
+		InContent: &content, AnsContent: &content,
+	}}, TestingParams{CpuMs: 1000, MemKiB: 1024})
+	require.Error(t, err)
+
+	pending, listErr := repo.ListPending(t.Context())
+	require.NoError(t, listErr)
+	require.Empty(t, pending)
+}
+
+func TestResumePendingRepublishesUnfinishedExecutions(t *testing.T) {
+	repo := NewInMemExecRepo()
+	content := "test"
+	unfinished := PendingExec{
+		UUID:    uuid.New(),
+		SrcCode: "print(1)",
+		LangId:  "python3.13",
+		Tests:   []TestFile{{InContent: &content, AnsContent: &content}},
+		Params:  TestingParams{CpuMs: 1000, MemKiB: 1024},
+	}
+	finished := unfinished
+	finished.UUID = uuid.New()
+	require.NoError(t, repo.SavePending(t.Context(), &unfinished))
+	require.NoError(t, repo.SavePending(t.Context(), &finished))
+	require.NoError(t, repo.Save(t.Context(), &Execution{UUID: finished.UUID, Stage: StageFinished}))
+
+	srvc := NewExecSrvc(t.Context(), repo, &nats.Conn{}, nil)
+	published := []*nats.Msg{}
+	srvc.publishJob = func(msg *nats.Msg) error {
+		published = append(published, msg)
+		return nil
+	}
+
+	require.NoError(t, srvc.ResumePending(t.Context()))
+
+	require.Len(t, published, 1)
+	require.Equal(t, NatsSubject, published[0].Subject)
+	require.Equal(t, srvc.natsInbox, published[0].Reply)
+	srvc.mu.Lock()
+	require.Contains(t, srvc.executions, unfinished.UUID)
+	require.NotContains(t, srvc.executions, finished.UUID)
+	srvc.mu.Unlock()
+
+	pending, err := repo.ListPending(t.Context())
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, unfinished.UUID, pending[0].UUID)
+}
+
+func TestResumePendingTakesOverExpiredLeasesOnly(t *testing.T) {
+	repo := NewInMemExecRepo()
+	content := "test"
+	live := PendingExec{
+		UUID:       uuid.New(),
+		SrcCode:    "print(1)",
+		LangId:     "python3.13",
+		Tests:      []TestFile{{InContent: &content, AnsContent: &content}},
+		Params:     TestingParams{CpuMs: 1000, MemKiB: 1024},
+		Owner:      "other",
+		LeaseUntil: time.Now().Add(PendingLeaseTTL),
+	}
+	expired := live
+	expired.UUID = uuid.New()
+	expired.LeaseUntil = time.Now().Add(-time.Second)
+	require.NoError(t, repo.SavePending(t.Context(), &live))
+	require.NoError(t, repo.SavePending(t.Context(), &expired))
+
+	srvc := NewExecSrvc(t.Context(), repo, &nats.Conn{}, nil)
+	published := 0
+	srvc.publishJob = func(*nats.Msg) error {
+		published++
+		return nil
+	}
+	require.NoError(t, srvc.ResumePending(t.Context()))
+	require.Equal(t, 1, published)
+	require.True(t, srvc.HasPending(t.Context(), expired.UUID))
+	require.False(t, srvc.HasPending(t.Context(), live.UUID))
+
+	pending, err := repo.ListPending(t.Context())
+	require.NoError(t, err)
+	for _, p := range pending {
+		if p.UUID == expired.UUID {
+			require.Equal(t, srvc.replicaID, p.Owner)
+		} else {
+			require.Equal(t, "other", p.Owner)
+		}
+	}
+
+	// another replica cannot enqueue an execution this one holds
+	taken := expired
+	taken.Owner = "other"
+	require.ErrorIs(t, repo.SavePending(t.Context(), &taken), ErrPendingLeased)
+}
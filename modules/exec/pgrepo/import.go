@@ -0,0 +1,87 @@
+package pgrepo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/common/ctxlog"
+	"github.com/programme-lv/backend/common/filestore"
+	"github.com/programme-lv/backend/modules/exec"
+)
+
+// ImportStats counts the outcome of ImportFileExecutions.
+type ImportStats struct {
+	Imported int
+	Skipped  int // already in Postgres
+	Failed   int
+	Pending  int
+}
+
+// ImportFileExecutions copies the executions and pending records that
+// exec.FileEvalRepo wrote to the store into Postgres. Executions that
+// already exist are skipped, so an interrupted import can be re-run.
+// The JSON files are left in place.
+func (r *pgExecRepo) ImportFileExecutions(ctx context.Context, store *filestore.Store) (ImportStats, error) {
+	log := ctxlog.FromContext(ctx).With("cmd", "import execution files")
+	files := exec.NewFileExecRepo(ctx, store)
+	var stats ImportStats
+
+	objects, err := store.List("")
+	if err != nil {
+		return stats, err
+	}
+	for _, obj := range objects {
+		name, ok := strings.CutSuffix(obj.Key, ".json")
+		if !ok || strings.Contains(name, "/") {
+			continue
+		}
+		id, err := uuid.Parse(name)
+		if err != nil {
+			continue
+		}
+
+		exists, err := r.exists(ctx, id)
+		if err != nil {
+			return stats, err
+		}
+		if exists {
+			stats.Skipped++
+			continue
+		}
+		execution, err := files.Get(ctx, id)
+		if err == nil {
+			err = r.Save(ctx, execution)
+		}
+		if err != nil {
+			log.Error("import execution", "exec_uuid", id, "error", err)
+			stats.Failed++
+			continue
+		}
+		stats.Imported++
+	}
+
+	pending, err := files.ListPending(ctx)
+	if err != nil {
+		return stats, err
+	}
+	for _, p := range pending {
+		if err := r.SavePending(ctx, &p); err != nil {
+			return stats, err
+		}
+		stats.Pending++
+	}
+	return stats, nil
+}
+
+func (r *pgExecRepo) exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM executions WHERE uuid = $1)`, id,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check if execution exists: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,431 @@
+package pgrepo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/programme-lv/backend/common/filestore"
+	"github.com/programme-lv/backend/modules/exec"
+)
+
+// maxInlineStream is the longest stdin, stdout or stderr kept in the
+// table row; longer streams are uploaded to the file store.
+const maxInlineStream = 4 * 1024
+
+const (
+	programCompile = "compile"
+	programSubm    = "subm"
+	programChecker = "checker"
+)
+
+type pgExecRepo struct {
+	pool  *pgxpool.Pool
+	store *filestore.Store
+}
+
+var _ exec.ExecRepo = &pgExecRepo{}
+
+// NewPgExecRepo stores executions in Postgres and
+// their long program output in the given file store.
+func NewPgExecRepo(pool *pgxpool.Pool, store *filestore.Store) *pgExecRepo {
+	return &pgExecRepo{pool: pool, store: store}
+}
+
+func (r *pgExecRepo) Save(ctx context.Context, e *exec.Execution) error {
+	prLang, err := json.Marshal(e.PrLang)
+	if err != nil {
+		return fmt.Errorf("marshal programming language: %w", err)
+	}
+	params, err := json.Marshal(e.Params)
+	if err != nil {
+		return fmt.Errorf("marshal testing params: %w", err)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO executions (
+			uuid, stage, lang_id, pr_lang, cpu_lim_ms, mem_lim_kib,
+			params, error_msg, sys_info, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (uuid) DO UPDATE SET
+			stage = EXCLUDED.stage,
+			lang_id = EXCLUDED.lang_id,
+			pr_lang = EXCLUDED.pr_lang,
+			cpu_lim_ms = EXCLUDED.cpu_lim_ms,
+			mem_lim_kib = EXCLUDED.mem_lim_kib,
+			params = EXCLUDED.params,
+			error_msg = EXCLUDED.error_msg,
+			sys_info = EXCLUDED.sys_info,
+			created_at = EXCLUDED.created_at
+	`,
+		e.UUID, e.Stage, e.PrLang.ShortId, prLang, e.Params.CpuMs, e.Params.MemKiB,
+		string(params), nullBytes(e.ErrorMsg), nullBytes(e.SysInfo), e.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert execution: %w", err)
+	}
+
+	for _, query := range []string{
+		`DELETE FROM exec_tests WHERE exec_uuid = $1`,
+		`DELETE FROM exec_run_data WHERE exec_uuid = $1`,
+	} {
+		if _, err := tx.Exec(ctx, query, e.UUID); err != nil {
+			return fmt.Errorf("delete existing data: %w", err)
+		}
+	}
+
+	uploaded := make(map[string]bool)
+	if e.SubmComp != nil {
+		if err := r.insertRunData(ctx, tx, e.UUID, 0, programCompile, e.SubmComp, uploaded); err != nil {
+			return err
+		}
+	}
+	for _, t := range e.TestRes {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO exec_tests (
+				exec_uuid, test_id, input_preview, answer_preview, reached, ignored, finished
+			) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, e.UUID, t.ID, nullBytes(t.Input), nullBytes(t.Answer), t.Reached, t.Ignored, t.Finished)
+		if err != nil {
+			return fmt.Errorf("insert test %d: %w", t.ID, err)
+		}
+		if t.Subm != nil {
+			if err := r.insertRunData(ctx, tx, e.UUID, t.ID, programSubm, t.Subm, uploaded); err != nil {
+				return err
+			}
+		}
+		if t.Checker != nil {
+			if err := r.insertRunData(ctx, tx, e.UUID, t.ID, programChecker, t.Checker, uploaded); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return r.deleteStaleStreams(e.UUID, uploaded)
+}
+
+// deleteStaleStreams deletes the stream files of an execution that
+// an earlier save uploaded and the last one no longer refers to.
+func (r *pgExecRepo) deleteStaleStreams(execUuid uuid.UUID, uploaded map[string]bool) error {
+	if r.store == nil {
+		return nil
+	}
+	objects, err := r.store.List(streamDir(execUuid))
+	if err != nil {
+		return fmt.Errorf("list stream files: %w", err)
+	}
+	for _, obj := range objects {
+		if uploaded[obj.Key] {
+			continue
+		}
+		if err := r.store.Delete(obj.Key); err != nil {
+			return fmt.Errorf("delete %s: %w", obj.Key, err)
+		}
+	}
+	return nil
+}
+
+func streamDir(execUuid uuid.UUID) string {
+	return fmt.Sprintf("runs/%s", execUuid)
+}
+
+// nullBytes converts optional text for a BYTEA column.
+func nullBytes(s *string) []byte {
+	if s == nil {
+		return nil
+	}
+	return []byte(*s)
+}
+
+// nullString converts a scanned BYTEA column back to optional text.
+func nullString(b []byte) *string {
+	if b == nil {
+		return nil
+	}
+	s := string(b)
+	return &s
+}
+
+func (r *pgExecRepo) insertRunData(
+	ctx context.Context, tx pgx.Tx, execUuid uuid.UUID, testID int, program string, rd *exec.RunData,
+	uploaded map[string]bool,
+) error {
+	var streams [3]storedStream
+	for i, s := range []struct{ name, text string }{
+		{"stdin", rd.StdIn}, {"stdout", rd.StdOut}, {"stderr", rd.StdErr},
+	} {
+		key := fmt.Sprintf("%s/%d-%s-%s.txt", streamDir(execUuid), testID, program, s.name)
+		stream, err := r.storeStream(key, s.text)
+		if err != nil {
+			return err
+		}
+		if stream.key != nil {
+			uploaded[key] = true
+		}
+		streams[i] = stream
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO exec_run_data (
+			exec_uuid, test_id, program,
+			stdin, stdin_key, stdout, stdout_key, stderr, stderr_key,
+			cpu_ms, wall_ms, mem_kib, exit_code, ctx_sw_v, ctx_sw_f,
+			signal, is_oom_killed, isol_status, isol_msg
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	`,
+		execUuid, testID, program,
+		streams[0].data, streams[0].key, streams[1].data, streams[1].key, streams[2].data, streams[2].key,
+		rd.CpuMs, rd.WallMs, rd.MemKiB, rd.ExitCode, rd.CtxSwV, rd.CtxSwF,
+		rd.Signal, rd.IsOomKilled, rd.IsolStatus, rd.IsolMsg,
+	)
+	if err != nil {
+		return fmt.Errorf("insert %s run data of test %d: %w", program, testID, err)
+	}
+	return nil
+}
+
+// storedStream holds a stream either inline or as a file store key.
+// Inline streams are bytes, as program output may contain NUL.
+type storedStream struct {
+	data []byte
+	key  *string
+}
+
+func (r *pgExecRepo) storeStream(key string, text string) (storedStream, error) {
+	if len(text) <= maxInlineStream {
+		return storedStream{data: []byte(text)}, nil
+	}
+	if _, err := r.store.Upload([]byte(text), key, "text/plain"); err != nil {
+		return storedStream{}, fmt.Errorf("upload %s: %w", key, err)
+	}
+	return storedStream{key: &key}, nil
+}
+
+func (r *pgExecRepo) loadStream(s storedStream) (string, error) {
+	if s.key == nil {
+		return string(s.data), nil
+	}
+	data, err := r.store.Download(*s.key)
+	if err != nil {
+		return "", fmt.Errorf("download %s: %w", *s.key, err)
+	}
+	return string(data), nil
+}
+
+func (r *pgExecRepo) Get(ctx context.Context, id uuid.UUID) (*exec.Execution, error) {
+	var e exec.Execution
+	var prLang []byte
+	var params string
+	var errorMsg, sysInfo []byte
+	err := r.pool.QueryRow(ctx, `
+		SELECT uuid, stage, pr_lang, params, error_msg, sys_info, created_at
+		FROM executions
+		WHERE uuid = $1
+	`, id).Scan(&e.UUID, &e.Stage, &prLang, &params, &errorMsg, &sysInfo, &e.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("execution not found: %w", err)
+		}
+		return nil, fmt.Errorf("query execution: %w", err)
+	}
+	if err := json.Unmarshal(prLang, &e.PrLang); err != nil {
+		return nil, fmt.Errorf("unmarshal programming language: %w", err)
+	}
+	if err := json.Unmarshal([]byte(params), &e.Params); err != nil {
+		return nil, fmt.Errorf("unmarshal testing params: %w", err)
+	}
+	e.ErrorMsg, e.SysInfo = nullString(errorMsg), nullString(sysInfo)
+
+	testRows, err := r.pool.Query(ctx, `
+		SELECT test_id, input_preview, answer_preview, reached, ignored, finished
+		FROM exec_tests
+		WHERE exec_uuid = $1
+		ORDER BY test_id ASC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query tests: %w", err)
+	}
+	e.TestRes, err = pgx.CollectRows(testRows, func(row pgx.CollectableRow) (exec.TestRes, error) {
+		var t exec.TestRes
+		var input, answer []byte
+		err := row.Scan(&t.ID, &input, &answer, &t.Reached, &t.Ignored, &t.Finished)
+		t.Input, t.Answer = nullString(input), nullString(answer)
+		return t, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan tests: %w", err)
+	}
+
+	if err := r.attachRunData(ctx, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *pgExecRepo) attachRunData(ctx context.Context, e *exec.Execution) error {
+	rows, err := r.pool.Query(ctx, `
+		SELECT test_id, program,
+			stdin, stdin_key, stdout, stdout_key, stderr, stderr_key,
+			cpu_ms, wall_ms, mem_kib, exit_code, ctx_sw_v, ctx_sw_f,
+			signal, is_oom_killed, isol_status, isol_msg
+		FROM exec_run_data
+		WHERE exec_uuid = $1
+	`, e.UUID)
+	if err != nil {
+		return fmt.Errorf("query run data: %w", err)
+	}
+	defer rows.Close()
+
+	testIdx := make(map[int]int, len(e.TestRes))
+	for i, t := range e.TestRes {
+		testIdx[t.ID] = i
+	}
+	for rows.Next() {
+		var testID int
+		var program string
+		var streams [3]storedStream
+		var rd exec.RunData
+		err := rows.Scan(&testID, &program,
+			&streams[0].data, &streams[0].key, &streams[1].data, &streams[1].key,
+			&streams[2].data, &streams[2].key,
+			&rd.CpuMs, &rd.WallMs, &rd.MemKiB, &rd.ExitCode, &rd.CtxSwV, &rd.CtxSwF,
+			&rd.Signal, &rd.IsOomKilled, &rd.IsolStatus, &rd.IsolMsg,
+		)
+		if err != nil {
+			return fmt.Errorf("scan run data: %w", err)
+		}
+		for i, dst := range []*string{&rd.StdIn, &rd.StdOut, &rd.StdErr} {
+			if *dst, err = r.loadStream(streams[i]); err != nil {
+				return err
+			}
+		}
+
+		if program == programCompile {
+			e.SubmComp = &rd
+			continue
+		}
+		i, ok := testIdx[testID]
+		if !ok {
+			return fmt.Errorf("run data of unknown test %d", testID)
+		}
+		switch program {
+		case programSubm:
+			e.TestRes[i].Subm = &rd
+		case programChecker:
+			e.TestRes[i].Checker = &rd
+		}
+	}
+	return rows.Err()
+}
+
+func (r *pgExecRepo) SavePending(ctx context.Context, p *exec.PendingExec) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal pending execution: %w", err)
+	}
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO exec_pending (uuid, request, enqueued_at, owner, lease_until)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (uuid) DO UPDATE SET
+			request = EXCLUDED.request,
+			enqueued_at = EXCLUDED.enqueued_at,
+			owner = EXCLUDED.owner,
+			lease_until = EXCLUDED.lease_until
+		WHERE exec_pending.owner = EXCLUDED.owner OR exec_pending.lease_until < now()
+	`, p.UUID, string(data), p.EnqueuedAt, p.Owner, p.LeaseUntil)
+	if err != nil {
+		return fmt.Errorf("store pending execution: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return exec.ErrPendingLeased
+	}
+	return nil
+}
+
+func (r *pgExecRepo) DeletePending(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM exec_pending WHERE uuid = $1`, id); err != nil {
+		return fmt.Errorf("delete pending execution: %w", err)
+	}
+	return nil
+}
+
+func (r *pgExecRepo) ListPending(ctx context.Context) ([]exec.PendingExec, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT request, owner, lease_until FROM exec_pending ORDER BY enqueued_at ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list pending executions: %w", err)
+	}
+	return collectPending(rows)
+}
+
+// ClaimPending skips rows that a concurrent claim has locked,
+// so that two replicas never take over the same execution.
+func (r *pgExecRepo) ClaimPending(ctx context.Context, owner string, now time.Time, leaseUntil time.Time) ([]exec.PendingExec, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE exec_pending p SET owner = $1, lease_until = $3
+		FROM (
+			SELECT uuid, owner, lease_until FROM exec_pending
+			WHERE owner = $1 OR lease_until < $2
+			FOR UPDATE SKIP LOCKED
+		) prev
+		WHERE p.uuid = prev.uuid
+		RETURNING p.request, prev.owner, prev.lease_until
+	`, owner, now, leaseUntil)
+	if err != nil {
+		return nil, fmt.Errorf("claim pending executions: %w", err)
+	}
+	pending, err := collectPending(rows)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].EnqueuedAt.Before(pending[j].EnqueuedAt)
+	})
+	return pending, nil
+}
+
+func (r *pgExecRepo) RenewPending(ctx context.Context, owner string, leaseUntil time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE exec_pending SET lease_until = $2 WHERE owner = $1`, owner, leaseUntil)
+	if err != nil {
+		return fmt.Errorf("renew pending executions: %w", err)
+	}
+	return nil
+}
+
+// collectPending scans rows of request, owner and lease_until.
+func collectPending(rows pgx.Rows) ([]exec.PendingExec, error) {
+	pending, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (exec.PendingExec, error) {
+		var data string
+		var p exec.PendingExec
+		var owner string
+		var leaseUntil time.Time
+		if err := row.Scan(&data, &owner, &leaseUntil); err != nil {
+			return p, err
+		}
+		if err := json.Unmarshal([]byte(data), &p); err != nil {
+			return p, fmt.Errorf("unmarshal pending execution: %w", err)
+		}
+		p.Owner, p.LeaseUntil = owner, leaseUntil
+		return p, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan pending executions: %w", err)
+	}
+	return pending, nil
+}
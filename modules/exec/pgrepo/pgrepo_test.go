@@ -0,0 +1,156 @@
+//go:build integration
+
+package pgrepo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/programme-lv/backend/common/filestore"
+	"github.com/programme-lv/backend/common/testutil"
+	"github.com/programme-lv/backend/modules/exec"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleExecution() *exec.Execution {
+	checker := "checker"
+	input := "1\x002"
+	errorMsg := "tester said \x00"
+	signal := int64(9)
+	return &exec.Execution{
+		UUID:  uuid.New(),
+		Stage: exec.StageFinished,
+		PrLang: exec.PrLang{
+			ShortId: "python3.13", Display: "Python 3.13",
+			CodeFname: "main.py", ExecCmd: "python3 main.py",
+		},
+		Params:    exec.TestingParams{CpuMs: 1000, MemKiB: 65536, Checker: &checker, Lane: exec.LaneBulk},
+		ErrorMsg:  &errorMsg,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		TestRes: []exec.TestRes{
+			{
+				ID: 1, Input: &input, Reached: true, Finished: true,
+				Subm:    &exec.RunData{StdIn: "1 2", StdOut: strings.Repeat("3\n", maxInlineStream), CpuMs: 10},
+				Checker: &exec.RunData{StdOut: "ok\x00", ExitCode: 0},
+			},
+			{
+				ID: 2, Reached: true, Finished: true,
+				Subm: &exec.RunData{IsOomKilled: true, Signal: &signal, MemKiB: 65536},
+			},
+		},
+	}
+}
+
+func TestPgExecRepoRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	repo := NewPgExecRepo(testutil.MustGetMigratedTestPostgresDb(t), store)
+
+	want := sampleExecution()
+	require.NoError(t, repo.Save(ctx, want))
+	require.NoError(t, repo.Save(ctx, want)) // saving again replaces the rows
+
+	got, err := repo.Get(ctx, want.UUID)
+	require.NoError(t, err)
+	require.True(t, want.CreatedAt.Equal(got.CreatedAt))
+	got.CreatedAt = want.CreatedAt
+	require.Equal(t, want, got)
+
+	var oomTests int
+	err = repo.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM exec_run_data r
+		JOIN executions e ON e.uuid = r.exec_uuid
+		WHERE r.program = 'subm' AND r.is_oom_killed AND e.lang_id = 'python3.13'
+	`).Scan(&oomTests)
+	require.NoError(t, err)
+	require.Equal(t, 1, oomTests)
+
+	_, err = repo.Get(ctx, uuid.New())
+	require.Error(t, err)
+}
+
+func TestPgExecRepoDeletesStaleStreams(t *testing.T) {
+	ctx := context.Background()
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	repo := NewPgExecRepo(testutil.MustGetMigratedTestPostgresDb(t), store)
+
+	execution := sampleExecution()
+	require.NoError(t, repo.Save(ctx, execution))
+	objects, err := store.List(streamDir(execution.UUID))
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+
+	execution.TestRes[0].Subm.StdOut = "3\n"
+	require.NoError(t, repo.Save(ctx, execution))
+	objects, err = store.List(streamDir(execution.UUID))
+	require.NoError(t, err)
+	require.Empty(t, objects)
+
+	got, err := repo.Get(ctx, execution.UUID)
+	require.NoError(t, err)
+	require.Equal(t, "3\n", got.TestRes[0].Subm.StdOut)
+}
+
+func TestPgExecRepoPending(t *testing.T) {
+	ctx := context.Background()
+	repo := NewPgExecRepo(testutil.MustGetMigratedTestPostgresDb(t), nil)
+
+	p := exec.PendingExec{UUID: uuid.New(), SrcCode: "print('\x00')", LangId: "python3.13",
+		EnqueuedAt: time.Now().UTC().Truncate(time.Microsecond)}
+	require.NoError(t, repo.SavePending(ctx, &p))
+
+	pending, err := repo.ListPending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, p.UUID, pending[0].UUID)
+	require.Equal(t, p.SrcCode, pending[0].SrcCode)
+
+	now := time.Now()
+	claimed, err := repo.ClaimPending(ctx, "a", now, now.Add(exec.PendingLeaseTTL))
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Empty(t, claimed[0].Owner)
+	claimed, err = repo.ClaimPending(ctx, "b", now, now.Add(exec.PendingLeaseTTL))
+	require.NoError(t, err)
+	require.Empty(t, claimed, "the lease of a is live")
+	require.ErrorIs(t, repo.SavePending(ctx, &exec.PendingExec{UUID: p.UUID, Owner: "b"}), exec.ErrPendingLeased)
+	require.NoError(t, repo.RenewPending(ctx, "a", now.Add(-time.Second)))
+	claimed, err = repo.ClaimPending(ctx, "b", now, now.Add(exec.PendingLeaseTTL))
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, "a", claimed[0].Owner)
+
+	require.NoError(t, repo.DeletePending(ctx, p.UUID))
+	pending, err = repo.ListPending(ctx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestImportFileExecutions(t *testing.T) {
+	ctx := context.Background()
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	files := exec.NewFileExecRepo(ctx, store)
+	execution := sampleExecution()
+	require.NoError(t, files.Save(ctx, execution))
+	require.NoError(t, files.SavePending(ctx, &exec.PendingExec{UUID: uuid.New()}))
+
+	repo := NewPgExecRepo(testutil.MustGetMigratedTestPostgresDb(t), store)
+	stats, err := repo.ImportFileExecutions(ctx, store)
+	require.NoError(t, err)
+	require.Equal(t, ImportStats{Imported: 1, Pending: 1}, stats)
+
+	stats, err = repo.ImportFileExecutions(ctx, store)
+	require.NoError(t, err)
+	require.Equal(t, ImportStats{Skipped: 1, Pending: 1}, stats)
+
+	got, err := repo.Get(ctx, execution.UUID)
+	require.NoError(t, err)
+	require.Equal(t, execution.TestRes, got.TestRes)
+}
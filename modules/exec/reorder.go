@@ -41,6 +41,9 @@ type ExecResStreamOrganizer struct {
 	// indicates if internal server error occurred
 	returnedISE bool
 
+	// indicates if the execution was cancelled
+	cancelled bool
+
 	// synchronizes access to internal state
 	mu sync.Mutex
 }
@@ -96,8 +99,8 @@ func (o *ExecResStreamOrganizer) Add(
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
-	// Internal server error encountered
-	if o.returnedISE {
+	// Internal server error encountered or cancelled
+	if o.returnedISE || o.cancelled {
 		return nil, nil
 	}
 
@@ -135,6 +138,9 @@ func (o *ExecResStreamOrganizer) Add(
 	case InternalServerError:
 		o.returnedISE = true
 		return []Event{event}, nil
+	case Cancelled:
+		o.cancelled = true
+		return []Event{event}, nil
 	default:
 		return nil, fmt.Errorf(
 			"unknown event type: %s",
@@ -149,11 +155,12 @@ func (o *ExecResStreamOrganizer) Add(
 //   - Internal server error encountered
 //   - All tests complete successfully
 //   - Compilation fails
+//   - Execution is cancelled
 func (o *ExecResStreamOrganizer) HasFinished() bool {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
-	return o.returnedISE ||
+	return o.returnedISE || o.cancelled ||
 		o.retKeys[FinishedTestingType] ||
 		o.retKeys[CompilationErrorType]
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/klauspost/compress/zstd"
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"github.com/programme-lv/backend/common/ctxlog"
 	"github.com/programme-lv/backend/common/srvcerror"
 	testerapi "github.com/programme-lv/tester/api"
@@ -21,7 +22,12 @@ import (
 type CodeExecutionService interface {
 	Enqueue(ctx context.Context, uuid uuid.UUID, srcCode string, prLangId string, tests []TestFile, params TestingParams) srvcerror.E
 	Listen(ctx context.Context, uuid uuid.UUID) (<-chan Event, srvcerror.E)
+	Subscribe(ctx context.Context, uuid uuid.UUID, afterSeq int) (<-chan SeqEvent, srvcerror.E)
 	Get(ctx context.Context, execUuid uuid.UUID) (Execution, srvcerror.E)
+	HasPending(ctx context.Context, execUuid uuid.UUID) bool
+	ListPendingIDs(ctx context.Context) ([]uuid.UUID, error)
+	ListTesters(ctx context.Context) []TesterStatus
+	Cancel(ctx context.Context, execUuid uuid.UUID) srvcerror.E
 }
 
 var _ CodeExecutionService = &execSrvc{}
@@ -30,9 +36,22 @@ var _ CodeExecutionService = &execSrvc{}
 type ExecRepo interface {
 	Save(ctx context.Context, exec *Execution) error
 	Get(ctx context.Context, id uuid.UUID) (*Execution, error)
-}
 
-const NatsSubject = "tester.jobs"
+	// pending executions are recorded on enqueue and
+	// deleted once the finished execution is saved.
+	// SavePending returns ErrPendingLeased when another
+	// owner holds a live lease of the execution.
+	SavePending(ctx context.Context, p *PendingExec) error
+	DeletePending(ctx context.Context, id uuid.UUID) error
+	ListPending(ctx context.Context) ([]PendingExec, error)
+
+	// ClaimPending leases to owner until leaseUntil the pending
+	// executions that owner holds or whose lease ended before now.
+	// The returned records carry their previous owner.
+	ClaimPending(ctx context.Context, owner string, now time.Time, leaseUntil time.Time) ([]PendingExec, error)
+	// RenewPending extends every lease that owner holds.
+	RenewPending(ctx context.Context, owner string, leaseUntil time.Time) error
+}
 
 // execSrvc handles communication with testers
 // for code execution and result streaming
@@ -47,10 +66,21 @@ type execSrvc struct {
 	fileSubject   string
 	testfileStore TestFileStore
 	publishJob    func(*nats.Msg) error
+	publishCancel func(*nats.Msg) error
+
+	// owner of the pending executions this process publishes
+	replicaID string
+
+	// set by WithJetStream
+	js    jetstream.JetStream
+	jsCfg JetStreamConfig
+	// JetStream results of running executions, acked once saved
+	resultAcks map[uuid.UUID][]jetstream.Msg
 
 	mu sync.Mutex
-	// maps exec IDs to client result channels
-	notifiers map[uuid.UUID]chan Event
+	// maps exec IDs to their ordered event logs, kept for
+	// eventLogRetention after the execution finishes
+	logs map[uuid.UUID]*eventLog
 	// tracks completion status of executions
 	execWg sync.Map // notifies get listener when execution is finished
 
@@ -59,6 +89,13 @@ type execSrvc struct {
 	organizers map[uuid.UUID]*ExecResStreamOrganizer
 	executions map[uuid.UUID]*Execution
 	fileHashes map[uuid.UUID]map[string]struct{}
+
+	watchdog WatchdogConfig
+	attempts map[uuid.UUID]*execAttempt
+	// closed once the first publish of a job has completed
+	publishing map[uuid.UUID]chan struct{}
+
+	testers *testerRegistry
 }
 
 func (e *execSrvc) StartPollingResultQueue(ctx context.Context) error {
@@ -75,17 +112,25 @@ func (e *execSrvc) StartPollingResultQueue(ctx context.Context) error {
 		return fmt.Errorf("subscribe to test file subject: %w", err)
 	}
 
-	resultSub, err := e.natsConn.Subscribe(e.natsInbox, func(msg *nats.Msg) {
-		e.handleResultMessage(ctx, msg)
-	})
+	stopResults, err := e.consumeResults(ctx)
 	if err != nil {
 		_ = fileSub.Unsubscribe()
 		e.isPolling.Store(false)
-		return fmt.Errorf("subscribe to nats inbox: %w", err)
+		return err
 	}
+
+	heartbeatSub, err := e.natsConn.Subscribe(HeartbeatSubject, e.handleHeartbeat)
+	if err != nil {
+		stopResults()
+		_ = fileSub.Unsubscribe()
+		e.isPolling.Store(false)
+		return fmt.Errorf("subscribe to tester heartbeats: %w", err)
+	}
+	go e.superviseExecutions(ctx)
 	go func() {
 		<-ctx.Done()
-		_ = resultSub.Unsubscribe()
+		_ = heartbeatSub.Unsubscribe()
+		stopResults()
 		_ = fileSub.Unsubscribe()
 		e.isPolling.Store(false)
 	}()
@@ -93,23 +138,51 @@ func (e *execSrvc) StartPollingResultQueue(ctx context.Context) error {
 	return nil
 }
 
+// consumeResults receives tester results on the process inbox, or
+// from the durable consumer with the JetStream transport.
+func (e *execSrvc) consumeResults(ctx context.Context) (stop func(), err error) {
+	if e.js != nil {
+		return e.consumeJetStreamResults(ctx)
+	}
+	resultSub, err := e.natsConn.Subscribe(e.natsInbox, func(msg *nats.Msg) {
+		e.handleResultMessage(ctx, msg)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("subscribe to nats inbox: %w", err)
+	}
+	return func() { _ = resultSub.Unsubscribe() }, nil
+}
+
 func (e *execSrvc) handleResultMessage(ctx context.Context, msg *nats.Msg) {
+	execUUID, event, ok := e.parseResult(msg.Data)
+	if !ok {
+		return
+	}
+	e.handleEvent(ctx, execUUID, event)
+}
+
+func (e *execSrvc) parseResult(data []byte) (uuid.UUID, Event, bool) {
 	var header testerapi.Header
-	if err := json.Unmarshal(msg.Data, &header); err != nil {
+	if err := json.Unmarshal(data, &header); err != nil {
 		e.logger.Error("unsmarshall NATS msg header", "error", err)
-		return
+		return uuid.Nil, nil, false
 	}
 	execUUID, err := uuid.Parse(header.EvalUuid)
 	if err != nil {
 		e.logger.Error("parse eval uuid", "error", err)
-		return
+		return uuid.Nil, nil, false
 	}
-	event, err := mapTesterMsgJsonToEvent(msg.Data, header.MsgType)
+	event, err := mapTesterMsgJsonToEvent(data, header.MsgType)
 	if err != nil {
 		e.logger.Error("map tester msg json to event", "error", err)
-		return
+		return uuid.Nil, nil, false
 	}
+	return execUUID, event, true
+}
 
+// handleEvent orders the event, applies it to the execution and
+// notifies the listener. The finished execution is saved.
+func (e *execSrvc) handleEvent(ctx context.Context, execUUID uuid.UUID, event Event) {
 	e.mu.Lock()
 	org, exists := e.organizers[execUUID]
 	if !exists || org == nil || org.HasFinished() {
@@ -124,8 +197,8 @@ func (e *execSrvc) handleResultMessage(ctx context.Context, msg *nats.Msg) {
 		return
 	}
 	execution := e.executions[execUUID]
-	notifier := e.notifiers[execUUID]
-	if execution == nil || notifier == nil {
+	log := e.logs[execUUID]
+	if execution == nil || log == nil {
 		e.mu.Unlock()
 		e.logger.Error("execution state unavailable", "exec_uuid", execUUID)
 		return
@@ -135,26 +208,38 @@ func (e *execSrvc) handleResultMessage(ctx context.Context, msg *nats.Msg) {
 			e.logger.Error("apply ev to exec", "error", err)
 		}
 	}
+	if attempt := e.attempts[execUUID]; attempt != nil {
+		attempt.lastEventAt = time.Now()
+		if attempt.startedAt.IsZero() {
+			attempt.startedAt = attempt.lastEventAt
+		}
+	}
+	// appended under the lock so that concurrent handlers keep the order
+	log.append(events...)
 	finished := org.HasFinished()
 	if finished {
-		delete(e.notifiers, execUUID)
 		delete(e.organizers, execUUID)
 		delete(e.executions, execUUID)
 		delete(e.fileHashes, execUUID)
+		delete(e.attempts, execUUID)
 	}
 	e.mu.Unlock()
 
-	for _, event := range events {
-		notifier <- event
-	}
 	if !finished {
 		return
 	}
-	close(notifier)
+	// subscribers may see the end of the log only after the
+	// result is stored, so that Get does not miss it
+	defer e.retireLog(execUUID, log)
 	if err := e.execRepo.Save(ctx, execution); err != nil {
 		e.logger.Error("save exec", "error", err)
+		e.settleResults(execUUID, false)
 		return
 	}
+	e.settleResults(execUUID, true)
+	if err := e.execRepo.DeletePending(ctx, execUUID); err != nil {
+		e.logger.Error("delete pending execution", "exec_uuid", execUUID, "error", err)
+	}
 	wgVal, exists := e.execWg.Load(execUUID)
 	if !exists {
 		e.logger.Error("wait group not found", "exec_uuid", execUUID)
@@ -265,19 +350,38 @@ func mapTesterRuntimeData(rd *testerapi.RuntimeData) *RunData {
 	}
 }
 
-func NewExecSrvc(ctx context.Context, repo ExecRepo, natsConn *nats.Conn, testfileStore TestFileStore) *execSrvc {
+type ExecSrvcOption func(*execSrvc)
+
+// WithWatchdog overrides the deadlines that supervise published jobs.
+func WithWatchdog(cfg WatchdogConfig) ExecSrvcOption {
+	return func(e *execSrvc) {
+		e.watchdog = cfg
+	}
+}
+
+func NewExecSrvc(ctx context.Context, repo ExecRepo, natsConn *nats.Conn, testfileStore TestFileStore, opts ...ExecSrvcOption) *execSrvc {
 	esrvc := &execSrvc{
 		logger:        ctxlog.FromContext(ctx),
 		natsConn:      natsConn,
+		replicaID:     uuid.NewString(),
 		natsInbox:     nats.NewInbox(),
 		fileSubject:   nats.NewInbox(),
 		testfileStore: testfileStore,
 		publishJob:    natsConn.PublishMsg,
+		publishCancel: natsConn.PublishMsg,
 		execRepo:      repo,
-		notifiers:     make(map[uuid.UUID]chan Event),
+		logs:          make(map[uuid.UUID]*eventLog),
 		organizers:    make(map[uuid.UUID]*ExecResStreamOrganizer),
 		executions:    make(map[uuid.UUID]*Execution),
 		fileHashes:    make(map[uuid.UUID]map[string]struct{}),
+		watchdog:      DefaultWatchdogConfig,
+		attempts:      make(map[uuid.UUID]*execAttempt),
+		publishing:    make(map[uuid.UUID]chan struct{}),
+		testers:       newTesterRegistry(),
+		resultAcks:    make(map[uuid.UUID][]jetstream.Msg),
+	}
+	for _, opt := range opts {
+		opt(esrvc)
 	}
 
 	return esrvc
@@ -286,9 +390,10 @@ func NewExecSrvc(ctx context.Context, repo ExecRepo, natsConn *nats.Conn, testfi
 // Enqueue processes a code execution request by:
 //  1. Validating the programming language and
 //     constraints
-//  2. Setting up result handlers and notification
+//  2. Durably recording the execution as pending
+//  3. Setting up result handlers and notification
 //     channels
-//  3. Sending the execution request to the
+//  4. Sending the execution request to the
 //     processing queue
 //
 // Returns the execution UUID for tracking
@@ -302,10 +407,79 @@ func (e *execSrvc) Enqueue(
 ) srvcerror.E {
 	l := ctxlog.FromContext(ctx).With("cmd", "enqueue execution")
 
+	now := time.Now()
+	pending := PendingExec{
+		UUID:       execUuid,
+		SrcCode:    srcCode,
+		LangId:     langId,
+		Tests:      tests,
+		Params:     params,
+		EnqueuedAt: now,
+		Owner:      e.replicaID,
+		LeaseUntil: now.Add(PendingLeaseTTL),
+	}
+	job, err := e.prepareJob(ctx, pending)
+	if err != nil {
+		return err
+	}
+
+	if saveErr := e.execRepo.SavePending(ctx, &pending); saveErr != nil {
+		l.Error("save pending execution", "exec_uuid", execUuid, "error", saveErr)
+		return srvcerror.InternalServerError()
+	}
+
+	if err := e.sendJob(ctx, job); err != nil {
+		if delErr := e.execRepo.DeletePending(ctx, execUuid); delErr != nil {
+			l.Error("delete pending execution", "exec_uuid", execUuid, "error", delErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// publish prepares and sends a pending execution without
+// recording it again.
+func (e *execSrvc) publish(ctx context.Context, pending PendingExec) srvcerror.E {
+	job, err := e.prepareJob(ctx, pending)
+	if err != nil {
+		return err
+	}
+	return e.sendJob(ctx, job)
+}
+
+// track restores the state of a pending execution whose job is
+// still queued, without publishing it again.
+func (e *execSrvc) track(ctx context.Context, pending PendingExec) srvcerror.E {
+	job, err := e.prepareJob(ctx, pending)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.trackJob(job)
+	e.mu.Unlock()
+	return nil
+}
+
+// preparedJob is a validated execution request together with
+// the in-memory state that tracks it once published.
+type preparedJob struct {
+	pending PendingExec
+	exec    *Execution
+	org     *ExecResStreamOrganizer
+	hashes  map[string]struct{}
+	data    []byte
+}
+
+func (e *execSrvc) prepareJob(ctx context.Context, pending PendingExec) (preparedJob, srvcerror.E) {
+	l := ctxlog.FromContext(ctx).With("cmd", "enqueue execution")
+	params := pending.Params
+	tests := pending.Tests
+
 	// 1. construct execution request
 	execReq := ExecRequest{
-		UUID:       execUuid,
-		Code:       srcCode,
+		UUID:       pending.UUID,
+		Code:       pending.SrcCode,
 		Lang:       PrLang{},
 		Tests:      tests,
 		CpuMs:      params.CpuMs,
@@ -314,10 +488,10 @@ func (e *execSrvc) Enqueue(
 		Interactor: params.Interactor,
 		Groups:     params.Groups,
 	}
-	lang, err := getPrLangById(langId)
+	lang, err := getPrLangById(pending.LangId)
 	if err != nil {
-		l.Error("get programming language", "lang_id", langId, "error", err)
-		return srvcerror.InternalServerError()
+		l.Error("get programming language", "lang_id", pending.LangId, "error", err)
+		return preparedJob{}, srvcerror.InternalServerError()
 	}
 	execReq.Lang = lang
 
@@ -326,10 +500,10 @@ func (e *execSrvc) Enqueue(
 	if validationErr != nil {
 		// IsValid returns srvcerror.E for validation errors
 		if se, ok := validationErr.(srvcerror.E); ok {
-			return se
+			return preparedJob{}, se
 		}
 		l.Error("validate exec request", "error", validationErr)
-		return srvcerror.InternalServerError()
+		return preparedJob{}, srvcerror.InternalServerError()
 	}
 
 	// 3. setup stream organizer
@@ -338,11 +512,11 @@ func (e *execSrvc) Enqueue(
 	org, orgErr := newResultStreamOrganizer(hasCompile, noOfTests)
 	if orgErr != nil {
 		l.Error("setup result stream organizer", "error", orgErr)
-		return srvcerror.InternalServerError()
+		return preparedJob{}, srvcerror.InternalServerError()
 	}
 	// 4. initialize empty execution
 	exec := Execution{
-		UUID:      execUuid,
+		UUID:      pending.UUID,
 		Stage:     StageWaiting,
 		TestRes:   []TestRes{},
 		PrLang:    lang,
@@ -361,67 +535,178 @@ func (e *execSrvc) Enqueue(
 	reqJson, marshalErr := json.Marshal(testerReq)
 	if marshalErr != nil {
 		l.Error("marshal eval request in tester format", "error", marshalErr)
-		return srvcerror.InternalServerError()
+		return preparedJob{}, srvcerror.InternalServerError()
 	}
 	zstdEncoder, zstdErr := zstd.NewWriter(nil)
 	if zstdErr != nil {
 		l.Error("create zstd encoder", "error", zstdErr)
-		return srvcerror.InternalServerError()
+		return preparedJob{}, srvcerror.InternalServerError()
 	}
 	defer zstdEncoder.Close()
 	compressed := zstdEncoder.EncodeAll(reqJson, make([]byte, 0, len(reqJson)))
 	encoded := base64.StdEncoding.EncodeToString(compressed)
-	encodedBytes := []byte(encoded)
+
+	return preparedJob{
+		pending: pending,
+		exec:    &exec,
+		org:     org,
+		hashes:  allowedFileHashes(tests),
+		data:    []byte(encoded),
+	}, nil
+}
+
+func (e *execSrvc) sendJob(ctx context.Context, job preparedJob) srvcerror.E {
+	l := ctxlog.FromContext(ctx).With("cmd", "enqueue execution")
+	execUuid := job.exec.UUID
 
 	// 6. setup execution state
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
 	e.mu.Lock()
-	e.execWg.Store(execUuid, wg)
-	e.notifiers[execUuid] = make(chan Event, 1000)
-	e.organizers[execUuid] = org
-	e.executions[execUuid] = &exec
-	e.fileHashes[execUuid] = allowedFileHashes(tests)
+	e.trackJob(job)
+	published := make(chan struct{})
+	e.publishing[execUuid] = published
+	e.mu.Unlock()
 
-	// 7. send encoded message to job queue
-	msg := nats.NewMsg(NatsSubject)
-	msg.Reply = e.natsInbox
-	msg.Header.Set(fileSubjectHeader, e.fileSubject)
-	msg.Data = encodedBytes
-	pubErr := e.publishJob(msg)
+	// 7. send encoded message to job queue; a JetStream publish waits
+	// for the ack, so it must not hold up event handling
+	pubErr := e.sendJobMsg(ctx, job)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.publishing, execUuid)
+	close(published)
 	if pubErr != nil {
-		delete(e.notifiers, execUuid)
+		delete(e.logs, execUuid)
 		delete(e.organizers, execUuid)
 		delete(e.executions, execUuid)
 		delete(e.fileHashes, execUuid)
+		delete(e.attempts, execUuid)
 		e.execWg.Delete(execUuid)
-		e.mu.Unlock()
 		l.Error("publish eval req to nats", "error", pubErr)
 		return srvcerror.InternalServerError()
 	}
-	e.mu.Unlock()
 
 	return nil
 }
 
-// Listen returns a channel that streams execution
-// events to clients. The channel is automatically
-// closed once the execution is complete
+// awaitPublish waits until the first publish of the execution's job
+// has completed, so that observers never see a job that failed to
+// publish. Callers must not hold e.mu.
+func (e *execSrvc) awaitPublish(ctx context.Context, execUuid uuid.UUID) {
+	e.mu.Lock()
+	published, ok := e.publishing[execUuid]
+	e.mu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case <-published:
+	case <-ctx.Done():
+	}
+}
+
+// trackJob sets up the in-memory state of a published job.
+// Callers hold e.mu.
+func (e *execSrvc) trackJob(job preparedJob) {
+	execUuid := job.exec.UUID
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	e.execWg.Store(execUuid, wg)
+	e.logs[execUuid] = newEventLog()
+	e.organizers[execUuid] = job.org
+	e.executions[execUuid] = job.exec
+	e.fileHashes[execUuid] = job.hashes
+	e.attempts[execUuid] = &execAttempt{
+		pending:     job.pending,
+		publishedAt: time.Now(),
+	}
+}
+
+// sendJobMsg publishes the encoded job of a tracked execution.
+func (e *execSrvc) sendJobMsg(ctx context.Context, job preparedJob) error {
+	msg := e.jobMsg(job.pending.Params.Lane, job.data)
+	if e.js != nil {
+		return e.publishJetStreamJob(ctx, job.pending.UUID, msg)
+	}
+	return e.publishJob(msg)
+}
+
+// jobMsg addresses an encoded job to the tester queue
+// with replies going to this process.
+func (e *execSrvc) jobMsg(lane Lane, data []byte) *nats.Msg {
+	msg := nats.NewMsg(NatsSubject)
+	msg.Reply = e.natsInbox
+	if e.js != nil {
+		msg.Subject = lane.subject()
+		msg.Header.Set(replySubjectHeader, e.natsInbox)
+	}
+	msg.Header.Set(laneHeader, lane.label())
+	msg.Header.Set(fileSubjectHeader, e.fileSubject)
+	msg.Data = data
+	return msg
+}
+
+// Listen streams every event of the execution from the start.
+// The channel is closed once the execution is complete or ctx ends.
 func (e *execSrvc) Listen(
 	ctx context.Context,
 	execId uuid.UUID,
 ) (<-chan Event, srvcerror.E) {
-	l := ctxlog.FromContext(ctx).With("query", "listen to execution")
+	seqCh, err := e.Subscribe(ctx, execId, 0)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan Event)
+	go func() {
+		defer close(ch)
+		for ev := range seqCh {
+			select {
+			case ch <- ev.Event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
 
+// Subscribe streams the execution's events after the sequence number
+// afterSeq, then follows the live tail. Any number of subscribers may
+// follow one execution. Once a finished execution's log is retired,
+// subscribers replay a snapshot of the stored result instead.
+func (e *execSrvc) Subscribe(
+	ctx context.Context,
+	execId uuid.UUID,
+	afterSeq int,
+) (<-chan SeqEvent, srvcerror.E) {
+	l := ctxlog.FromContext(ctx).With("query", "subscribe to execution")
+
+	e.awaitPublish(ctx, execId)
 	e.mu.Lock()
-	defer e.mu.Unlock()
+	log, ok := e.logs[execId]
+	e.mu.Unlock()
+	if ok {
+		return log.subscribe(ctx, afterSeq), nil
+	}
 
-	ch, ok := e.notifiers[execId]
-	if !ok {
-		l.Error("no listener for exec", "exec_id", execId)
-		return nil, srvcerror.InternalServerError()
+	exec, err := e.execRepo.Get(ctx, execId)
+	if err != nil {
+		l.Warn("get execution from repo", "exec_id", execId, "error", err)
+		return nil, ErrEvalNotFound
 	}
-	return ch, nil
+	return newClosedEventLog(snapshotEvents(exec)).subscribe(ctx, afterSeq), nil
+}
+
+// retireLog closes a finished execution's log and forgets it
+// after the retention period.
+func (e *execSrvc) retireLog(execId uuid.UUID, log *eventLog) {
+	log.close()
+	time.AfterFunc(eventLogRetention, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.logs[execId] == log {
+			delete(e.logs, execId)
+		}
+	})
 }
 
 // Get retrieves the execution results for a given
@@ -433,6 +718,7 @@ func (e *execSrvc) Get(
 ) (Execution, srvcerror.E) {
 	l := ctxlog.FromContext(ctx).With("query", "get execution")
 
+	e.awaitPublish(ctx, execId)
 	e.mu.Lock()
 	wgVal, exists := e.execWg.Load(execId)
 	e.mu.Unlock()
@@ -504,6 +790,8 @@ func applyEventToExec(
 	case CompilationError:
 		exec.Stage = StageCompileError
 		exec.ErrorMsg = e.ErrorMsg
+	case Cancelled:
+		exec.Stage = StageCancelled
 	}
 	return nil
 }
@@ -0,0 +1,219 @@
+package exec
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/common/srvcerror"
+)
+
+const (
+	// maxStressSeeds bounds the seed range of one stress test
+	maxStressSeeds = 1000
+	// stressBatchSize seeds are tested per execution, so a stress test
+	// stops within one batch of its first mismatch
+	stressBatchSize = 20
+	// maxStressOutput bounds generated inputs and reference answers.
+	// Tester events are capped at 256 KiB including stdin and stderr,
+	// so longer output may arrive cut off.
+	maxStressOutput = 128 * 1024
+)
+
+// Program is source code in a supported programming language.
+type Program struct {
+	SrcCode string `json:"src_code"`
+	LangId  string `json:"lang_id"`
+}
+
+// StressTestReq describes a search for a counter-example. For every
+// seed the generator reads the seed from stdin and prints a test input,
+// the reference solution prints its answer, and the candidate is graded
+// against that answer with the checker. The checker and limits are
+// those of the task under test.
+type StressTestReq struct {
+	Generator Program
+	Reference Program
+	Candidate Program
+	// Checker grades the candidate; without one the outputs
+	// are compared token by token
+	Checker  *string
+	CpuMs    int
+	MemKiB   int
+	SeedFrom int
+	SeedTo   int // inclusive
+}
+
+// Stress test programs.
+const (
+	StressGenerator = "generator"
+	StressReference = "reference"
+	StressCandidate = "candidate"
+)
+
+// Reasons why a stress test stopped.
+const (
+	StressCompileError  = "compilation_error"
+	StressInternalError = "internal_error"
+	StressRuntimeError  = "runtime_error"
+	StressTimeLimit     = "time_limit"
+	StressMemoryLimit   = "memory_limit"
+	StressWrongAnswer   = "wrong_answer"
+	// the generator or reference output is too long to be
+	// received intact, see maxStressOutput
+	StressOutputTooLarge = "output_too_large"
+)
+
+// StressFailure is the first seed on which a program failed.
+// A wrong answer of the candidate is the counter-example.
+type StressFailure struct {
+	Seed     int       `json:"seed"`
+	Program  string    `json:"program"`
+	Reason   string    `json:"reason"`
+	ExecUuid uuid.UUID `json:"exec_uuid"`
+	Input    *string   `json:"input"`
+	Answer   *string   `json:"answer"`
+	Output   *string   `json:"output"`
+}
+
+type StressTestResult struct {
+	// SeedsPassed counts the seeds before the failure
+	SeedsPassed int            `json:"seeds_passed"`
+	Failure     *StressFailure `json:"failure"`
+}
+
+// StressTest runs the seed range in batches through the execution
+// service and stops at the first batch containing a failure.
+func StressTest(ctx context.Context, execSrvc CodeExecutionService, req StressTestReq) (StressTestResult, srvcerror.E) {
+	if req.SeedTo < req.SeedFrom || req.SeedTo-req.SeedFrom >= maxStressSeeds {
+		return StressTestResult{}, ErrInvalidSeedRange
+	}
+
+	var res StressTestResult
+	for from := req.SeedFrom; from <= req.SeedTo; from += stressBatchSize {
+		to := min(from+stressBatchSize-1, req.SeedTo)
+		failure, err := stressBatch(ctx, execSrvc, req, from, to)
+		if err != nil {
+			return StressTestResult{}, err
+		}
+		if failure != nil {
+			res.SeedsPassed += failure.Seed - from
+			res.Failure = failure
+			return res, nil
+		}
+		res.SeedsPassed += to - from + 1
+	}
+	return res, nil
+}
+
+func stressBatch(ctx context.Context, execSrvc CodeExecutionService, req StressTestReq, from, to int) (*StressFailure, srvcerror.E) {
+	empty := ""
+	tests := make([]TestFile, 0, to-from+1)
+	for seed := from; seed <= to; seed++ {
+		in := strconv.Itoa(seed) + "\n"
+		tests = append(tests, TestFile{InContent: &in, AnsContent: &empty})
+	}
+	params := TestingParams{CpuMs: req.CpuMs, MemKiB: req.MemKiB, Lane: LaneBulk}
+
+	gen, err := runStressProgram(ctx, execSrvc, req.Generator, tests, params)
+	if err != nil {
+		return nil, err
+	}
+	if f := stressRunFailure(gen, tests, params, from, StressGenerator); f != nil {
+		return f, nil
+	}
+	for i := range tests {
+		tests[i].InContent = &gen.TestRes[i].Subm.StdOut
+	}
+
+	ref, err := runStressProgram(ctx, execSrvc, req.Reference, tests, params)
+	if err != nil {
+		return nil, err
+	}
+	if f := stressRunFailure(ref, tests, params, from, StressReference); f != nil {
+		return f, nil
+	}
+	for i := range tests {
+		tests[i].AnsContent = &ref.TestRes[i].Subm.StdOut
+	}
+
+	params.Checker = req.Checker
+	cand, err := runStressProgram(ctx, execSrvc, req.Candidate, tests, params)
+	if err != nil {
+		return nil, err
+	}
+	return stressRunFailure(cand, tests, params, from, StressCandidate), nil
+}
+
+func runStressProgram(ctx context.Context, execSrvc CodeExecutionService, p Program, tests []TestFile, params TestingParams) (Execution, srvcerror.E) {
+	id := uuid.New()
+	if err := execSrvc.Enqueue(ctx, id, p.SrcCode, p.LangId, tests, params); err != nil {
+		return Execution{}, err
+	}
+	return execSrvc.Get(ctx, id)
+}
+
+// stressRunFailure returns the first failed test of the execution.
+// Only the candidate's output is graded.
+func stressRunFailure(e Execution, tests []TestFile, params TestingParams, firstSeed int, program string) *StressFailure {
+	failure := &StressFailure{Seed: firstSeed, Program: program, ExecUuid: e.UUID}
+	switch {
+	case e.Stage == StageCompileError:
+		failure.Reason = StressCompileError
+		return failure
+	case e.Stage != StageFinished || len(e.TestRes) != len(tests):
+		failure.Reason = StressInternalError
+		return failure
+	}
+
+	graded := program == StressCandidate
+	for i, t := range e.TestRes {
+		var answer *string
+		if graded {
+			answer = tests[i].AnsContent
+		}
+		reason := stressTestFailure(t, answer, params)
+		if reason == "" && !graded && len(t.Subm.StdOut) > maxStressOutput {
+			reason = StressOutputTooLarge
+		}
+		if reason == "" {
+			continue
+		}
+		failure.Seed = firstSeed + i
+		failure.Reason = reason
+		failure.Input = tests[i].InContent
+		failure.Answer = answer
+		if t.Subm != nil {
+			failure.Output = &t.Subm.StdOut
+		}
+		return failure
+	}
+	return nil
+}
+
+// stressTestFailure grades the run against answer unless it is nil.
+func stressTestFailure(t TestRes, answer *string, params TestingParams) string {
+	r := t.Subm
+	switch {
+	case !t.Finished || r == nil:
+		return StressInternalError
+	case r.IsOomKilled || r.MemKiB >= int64(params.MemKiB):
+		return StressMemoryLimit
+	case r.ExitCode != 0 || r.Signal != nil:
+		return StressRuntimeError
+	case r.CpuMs >= int64(params.CpuMs):
+		return StressTimeLimit
+	case answer == nil:
+		return ""
+	case t.Checker != nil:
+		if CheckerScore(t.Checker.ExitCode, t.Checker.StdOut) < 1 {
+			return StressWrongAnswer
+		}
+		return ""
+	case !slices.Equal(strings.Fields(r.StdOut), strings.Fields(*answer)):
+		return StressWrongAnswer
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package exec
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/common/srvcerror"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner runs programs written as Go functions from stdin to stdout.
+// A negative result is reported as the exit code.
+type fakeRunner struct {
+	CodeExecutionService
+	programs map[string]func(in string) (string, int64)
+	execs    map[uuid.UUID]Execution
+}
+
+func (f *fakeRunner) Enqueue(_ context.Context, id uuid.UUID, src string, _ string, tests []TestFile, params TestingParams) srvcerror.E {
+	program := f.programs[src]
+	e := Execution{UUID: id, Stage: StageFinished, Params: params}
+	for i, t := range tests {
+		out, exitCode := program(*t.InContent)
+		e.TestRes = append(e.TestRes, TestRes{ID: i + 1, Reached: true, Finished: true,
+			Subm: &RunData{StdOut: out, ExitCode: exitCode}})
+	}
+	f.execs[id] = e
+	return nil
+}
+
+func (f *fakeRunner) Get(_ context.Context, id uuid.UUID) (Execution, srvcerror.E) {
+	return f.execs[id], nil
+}
+
+func newFakeRunner() *fakeRunner {
+	seed := func(in string) int {
+		n, _ := strconv.Atoi(strings.TrimSpace(in))
+		return n
+	}
+	return &fakeRunner{
+		programs: map[string]func(string) (string, int64){
+			"gen": func(in string) (string, int64) {
+				return strconv.Itoa(2*seed(in)) + "\n", 0
+			},
+			"gen-crash-3": func(in string) (string, int64) {
+				if seed(in) == 3 {
+					return "", 1
+				}
+				return in, 0
+			},
+			"ref": func(in string) (string, int64) { return in, 0 },
+			"gen-huge-5": func(in string) (string, int64) {
+				if seed(in) == 5 {
+					return strings.Repeat("1 ", maxStressOutput), 0
+				}
+				return in, 0
+			},
+			"cand-wrong-14": func(in string) (string, int64) {
+				if seed(in) == 14 {
+					return "0\n", 0
+				}
+				return strings.TrimSpace(in), 0
+			},
+		},
+		execs: make(map[uuid.UUID]Execution),
+	}
+}
+
+func stressReq(gen, cand string, from, to int) StressTestReq {
+	return StressTestReq{
+		Generator: Program{SrcCode: gen, LangId: "python3.13"},
+		Reference: Program{SrcCode: "ref", LangId: "python3.13"},
+		Candidate: Program{SrcCode: cand, LangId: "python3.13"},
+		CpuMs:     1000, MemKiB: 65536,
+		SeedFrom: from, SeedTo: to,
+	}
+}
+
+func TestStressTestStopsAtFirstMismatch(t *testing.T) {
+	runner := newFakeRunner()
+	res, err := StressTest(t.Context(), runner, stressReq("gen", "cand-wrong-14", 1, 100))
+	require.Nil(t, err)
+
+	require.Equal(t, 6, res.SeedsPassed)
+	require.NotNil(t, res.Failure)
+	require.Equal(t, 7, res.Failure.Seed)
+	require.Equal(t, StressCandidate, res.Failure.Program)
+	require.Equal(t, StressWrongAnswer, res.Failure.Reason)
+	require.Equal(t, "14\n", *res.Failure.Input)
+	require.Equal(t, "14\n", *res.Failure.Answer)
+	require.Equal(t, "0\n", *res.Failure.Output)
+	// the generator, reference and candidate of the first batch
+	require.Len(t, runner.execs, 3)
+	for _, e := range runner.execs {
+		require.Equal(t, LaneBulk, e.Params.Lane)
+	}
+}
+
+func TestStressTestReportsGeneratorFailure(t *testing.T) {
+	res, err := StressTest(t.Context(), newFakeRunner(), stressReq("gen-crash-3", "cand-wrong-14", 1, 10))
+	require.Nil(t, err)
+	require.Equal(t, 2, res.SeedsPassed)
+	require.Equal(t, &StressFailure{
+		Seed: 3, Program: StressGenerator, Reason: StressRuntimeError,
+		ExecUuid: res.Failure.ExecUuid, Input: res.Failure.Input, Output: res.Failure.Output,
+	}, res.Failure)
+	require.Equal(t, "3\n", *res.Failure.Input)
+}
+
+func TestStressTestPassesWholeRange(t *testing.T) {
+	res, err := StressTest(t.Context(), newFakeRunner(), stressReq("gen", "ref", 0, 44))
+	require.Nil(t, err)
+	require.Equal(t, StressTestResult{SeedsPassed: 45}, res)
+
+	_, err = StressTest(t.Context(), newFakeRunner(), stressReq("gen", "ref", 5, 4))
+	require.Equal(t, ErrInvalidSeedRange, err)
+}
+
+func TestStressTestRejectsTruncatedInput(t *testing.T) {
+	res, err := StressTest(t.Context(), newFakeRunner(), stressReq("gen-huge-5", "ref", 1, 10))
+	require.Nil(t, err)
+	require.Equal(t, 4, res.SeedsPassed)
+	require.Equal(t, StressGenerator, res.Failure.Program)
+	require.Equal(t, StressOutputTooLarge, res.Failure.Reason)
+}
+
+func TestStressTestFailureGradesPartialScores(t *testing.T) {
+	params := TestingParams{CpuMs: 1000, MemKiB: 65536}
+	answer := "42"
+	for _, tt := range []struct {
+		exitCode int64
+		stdout   string
+		want     string
+	}{
+		{0, "", ""},
+		{7, "1", ""},
+		{7, "0.5", StressWrongAnswer},
+		{60, "", StressWrongAnswer},
+		{1, "", StressWrongAnswer},
+	} {
+		res := TestRes{Finished: true, Subm: &RunData{StdOut: "41"},
+			Checker: &RunData{ExitCode: tt.exitCode, StdOut: tt.stdout}}
+		require.Equal(t, tt.want, stressTestFailure(res, &answer, params), "exit %d", tt.exitCode)
+	}
+}
@@ -45,6 +45,7 @@ const (
 	StageFinished      ExecStage = "finished"
 	StageCompileError  ExecStage = "compile_error"
 	StageInternalError ExecStage = "internal_error"
+	StageCancelled     ExecStage = "cancelled"
 )
 
 type Execution struct {
@@ -113,6 +114,9 @@ type TestingParams struct {
 	// Groups are scoring units as 1-based test IDs.
 	// Empty or omitted: tester runs every test.
 	Groups [][]int `json:"groups,omitempty"`
+
+	// Lane is the job queue priority. Empty means LaneHigh.
+	Lane Lane `json:"lane,omitempty"`
 }
 
 func (p *TestingParams) IsValid() error {
@@ -0,0 +1,129 @@
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+)
+
+// HeartbeatSubject receives periodic Heartbeat messages from every
+// tester, independent of the job queue group.
+const HeartbeatSubject = "tester.heartbeat"
+
+const (
+	// testers publish a heartbeat every 5 seconds
+	testerOfflineAfter = 15 * time.Second
+	testerForgetAfter  = time.Hour
+)
+
+// Heartbeat is the JSON payload a tester publishes to HeartbeatSubject.
+type Heartbeat struct {
+	TesterID string      `json:"tester_id"`
+	SysInfo  string      `json:"system_info"`
+	Running  []uuid.UUID `json:"running"`
+}
+
+// TesterStatus is the registry's view of one tester.
+type TesterStatus struct {
+	ID       string      `json:"id"`
+	SysInfo  string      `json:"system_info"`
+	LastSeen time.Time   `json:"last_seen"`
+	Running  []uuid.UUID `json:"running"`
+	Online   bool        `json:"online"`
+}
+
+// testerRegistry remembers the latest heartbeat of each tester.
+// Last-seen times use the backend clock.
+type testerRegistry struct {
+	mu      sync.Mutex
+	testers map[string]TesterStatus
+}
+
+func newTesterRegistry() *testerRegistry {
+	return &testerRegistry{testers: make(map[string]TesterStatus)}
+}
+
+func (r *testerRegistry) record(hb Heartbeat, now time.Time) error {
+	if strings.TrimSpace(hb.TesterID) == "" {
+		return fmt.Errorf("heartbeat without tester id")
+	}
+	running := hb.Running
+	if running == nil {
+		running = []uuid.UUID{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.testers[hb.TesterID] = TesterStatus{
+		ID:       hb.TesterID,
+		SysInfo:  hb.SysInfo,
+		LastSeen: now,
+		Running:  running,
+	}
+	return nil
+}
+
+// list returns testers ordered by ID and forgets long-gone ones.
+func (r *testerRegistry) list(now time.Time) []TesterStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make([]TesterStatus, 0, len(r.testers))
+	for id, t := range r.testers {
+		silence := now.Sub(t.LastSeen)
+		if silence > testerForgetAfter {
+			delete(r.testers, id)
+			continue
+		}
+		t.Online = silence <= testerOfflineAfter
+		t.Running = slices.Clone(t.Running)
+		res = append(res, t)
+	}
+	slices.SortFunc(res, func(a, b TesterStatus) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return res
+}
+
+// idle reports whether some tester is online and no online tester
+// runs a job, so that a job waiting since before the latest
+// heartbeats is not held by any tester.
+func (r *testerRegistry) idle(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	online := false
+	for _, t := range r.testers {
+		if now.Sub(t.LastSeen) > testerOfflineAfter {
+			continue
+		}
+		if len(t.Running) > 0 {
+			return false
+		}
+		online = true
+	}
+	return online
+}
+
+func (e *execSrvc) handleHeartbeat(msg *nats.Msg) {
+	var hb Heartbeat
+	if err := json.Unmarshal(msg.Data, &hb); err != nil {
+		e.logger.Warn("unmarshal tester heartbeat", "error", err)
+		return
+	}
+	if err := e.testers.record(hb, time.Now()); err != nil {
+		e.logger.Warn("record tester heartbeat", "error", err)
+	}
+}
+
+// ListTesters reports every tester that sent a heartbeat recently.
+func (e *execSrvc) ListTesters(ctx context.Context) []TesterStatus {
+	return e.testers.list(time.Now())
+}
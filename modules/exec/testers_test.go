@@ -0,0 +1,29 @@
+package exec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTesterRegistryTracksHeartbeats(t *testing.T) {
+	r := newTesterRegistry()
+	now := time.Now()
+	job := uuid.New()
+
+	require.NoError(t, r.record(Heartbeat{TesterID: "b", SysInfo: "8 cores"}, now.Add(-time.Minute)))
+	require.NoError(t, r.record(Heartbeat{TesterID: "a", SysInfo: "4 cores", Running: []uuid.UUID{job}}, now))
+	require.NoError(t, r.record(Heartbeat{TesterID: "gone"}, now.Add(-2*time.Hour)))
+	require.Error(t, r.record(Heartbeat{TesterID: " "}, now))
+
+	testers := r.list(now)
+	require.Len(t, testers, 2)
+	require.Equal(t, "a", testers[0].ID)
+	require.True(t, testers[0].Online)
+	require.Equal(t, []uuid.UUID{job}, testers[0].Running)
+	require.Equal(t, "b", testers[1].ID)
+	require.False(t, testers[1].Online)
+	require.Empty(t, testers[1].Running)
+}
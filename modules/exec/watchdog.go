@@ -0,0 +1,158 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WatchdogConfig bounds how long an execution may run before the
+// tester that received it is considered dead.
+type WatchdogConfig struct {
+	// TotalTimeout limits one attempt, measured from the first
+	// tester event. Time spent waiting in the queue is not counted.
+	TotalTimeout time.Duration
+	// EventTimeout limits the silence between consecutive events
+	// once the tester has sent the first one.
+	EventTimeout time.Duration
+	// QueueTimeout limits how long a job may stay unstarted while every
+	// online tester reports that it runs nothing. Such a job was lost,
+	// e.g. published while no tester was subscribed.
+	QueueTimeout time.Duration
+	// MaxRetries is how many times the job is re-published before
+	// the execution finishes with an internal error.
+	MaxRetries int
+}
+
+var DefaultWatchdogConfig = WatchdogConfig{
+	TotalTimeout: 5 * time.Minute,
+	EventTimeout: 20 * time.Second,
+	QueueTimeout: time.Minute,
+	MaxRetries:   2,
+}
+
+const watchdogInterval = time.Second
+
+// execAttempt tracks deadlines of the currently published job.
+type execAttempt struct {
+	pending     PendingExec
+	retries     int
+	publishedAt time.Time
+	// zero until the tester sends the first event
+	startedAt   time.Time
+	lastEventAt time.Time
+}
+
+// expired returns a non-empty reason when the attempt missed a deadline.
+// testersIdle reports whether online testers exist and all are idle.
+func (a *execAttempt) expired(cfg WatchdogConfig, now time.Time, testersIdle bool) string {
+	if a.startedAt.IsZero() {
+		if testersIdle && now.Sub(a.publishedAt) > cfg.QueueTimeout {
+			return fmt.Sprintf("job not started for %s while testers were idle", cfg.QueueTimeout)
+		}
+		return ""
+	}
+	if now.Sub(a.startedAt) > cfg.TotalTimeout {
+		return fmt.Sprintf("execution exceeded %s", cfg.TotalTimeout)
+	}
+	if now.Sub(a.lastEventAt) > cfg.EventTimeout {
+		return fmt.Sprintf("tester sent no events for %s", cfg.EventTimeout)
+	}
+	return ""
+}
+
+func (e *execSrvc) superviseExecutions(ctx context.Context) {
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+	leases := time.NewTicker(PendingLeaseTTL / 3)
+	defer leases.Stop()
+	results := time.NewTicker(resultProgressInterval)
+	defer results.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			e.checkDeadlines(ctx, now)
+			e.reportQueueDepth()
+		case now := <-leases.C:
+			e.renewLeases(ctx, now)
+		case <-results.C:
+			e.holdResults()
+		}
+	}
+}
+
+// checkDeadlines re-publishes or fails every execution
+// whose current attempt has missed a deadline.
+func (e *execSrvc) checkDeadlines(ctx context.Context, now time.Time) {
+	type expiredExec struct {
+		id      uuid.UUID
+		reason  string
+		retries int
+	}
+	var expired []expiredExec
+
+	// a queued JetStream job stays in the work-queue stream until a
+	// tester fetches it, so publishing it again would only duplicate it
+	testersIdle := e.js == nil && e.testers.idle(now)
+	e.mu.Lock()
+	for id, a := range e.attempts {
+		if reason := a.expired(e.watchdog, now, testersIdle); reason != "" {
+			expired = append(expired, expiredExec{id: id, reason: reason, retries: a.retries})
+		}
+	}
+	e.mu.Unlock()
+
+	for _, x := range expired {
+		if x.retries < e.watchdog.MaxRetries {
+			e.logger.Warn("re-publishing silent execution",
+				"exec_uuid", x.id, "reason", x.reason, "retry", x.retries+1)
+			err := e.retry(ctx, x.id, now)
+			if err == nil {
+				continue
+			}
+			e.logger.Error("re-publish execution", "exec_uuid", x.id, "error", err)
+		}
+		e.logger.Error("giving up on silent execution",
+			"exec_uuid", x.id, "reason", x.reason, "retries", x.retries)
+		msg := fmt.Sprintf("%s after %d retries", x.reason, x.retries)
+		e.handleEvent(ctx, x.id, InternalServerError{ErrorMsg: &msg})
+	}
+}
+
+// retry publishes the job again under the same UUID. Listeners keep
+// their channel and receive the events of the new attempt, starting
+// with ReceivedSubmission. Late events of the abandoned attempt share
+// the UUID and cannot be told apart, so they are accepted as well.
+func (e *execSrvc) retry(ctx context.Context, execUuid uuid.UUID, now time.Time) error {
+	e.mu.Lock()
+	a, ok := e.attempts[execUuid]
+	e.mu.Unlock()
+	if !ok {
+		return nil // finished meanwhile
+	}
+
+	job, err := e.prepareJob(ctx, a.pending)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	if _, ok := e.attempts[execUuid]; !ok {
+		e.mu.Unlock()
+		return nil
+	}
+	e.organizers[execUuid] = job.org
+	e.executions[execUuid] = job.exec
+	e.fileHashes[execUuid] = job.hashes
+	a.retries++
+	a.publishedAt = now
+	a.startedAt = time.Time{}
+	a.lastEventAt = time.Time{}
+	e.mu.Unlock()
+
+	return e.sendJobMsg(ctx, job)
+}
@@ -0,0 +1,138 @@
+package exec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/require"
+)
+
+func enqueueSupervised(t *testing.T, cfg WatchdogConfig) (*execSrvc, *InMemExecRepo, uuid.UUID, *[]*nats.Msg) {
+	t.Helper()
+	repo := NewInMemExecRepo()
+	srvc := NewExecSrvc(t.Context(), repo, &nats.Conn{}, nil, WithWatchdog(cfg))
+	published := []*nats.Msg{}
+	srvc.publishJob = func(msg *nats.Msg) error {
+		published = append(published, msg)
+		return nil
+	}
+
+	id := uuid.New()
+	content := "test"
+	err := srvc.Enqueue(t.Context(), id, "print(1)", "python3.13", []TestFile{{
+		InContent: &content, AnsContent: &content,
+	}}, TestingParams{CpuMs: 1000, MemKiB: 1024})
+	require.NoError(t, err)
+	return srvc, repo, id, &published
+}
+
+func TestWatchdogRepublishesAfterTotalTimeout(t *testing.T) {
+	cfg := WatchdogConfig{TotalTimeout: time.Minute, EventTimeout: time.Hour, QueueTimeout: time.Hour, MaxRetries: 1}
+	srvc, _, id, published := enqueueSupervised(t, cfg)
+
+	// the total timeout starts with the first tester event
+	srvc.checkDeadlines(t.Context(), time.Now().Add(2*time.Minute))
+	require.Len(t, *published, 1)
+
+	srvc.handleEvent(t.Context(), id, ReceivedSubmission{StartedAt: time.Now()})
+	srvc.checkDeadlines(t.Context(), time.Now().Add(30*time.Second))
+	require.Len(t, *published, 1)
+
+	srvc.checkDeadlines(t.Context(), time.Now().Add(2*time.Minute))
+	require.Len(t, *published, 2)
+	require.Equal(t, NatsSubject, (*published)[1].Subject)
+	require.Equal(t, (*published)[0].Data, (*published)[1].Data)
+
+	srvc.mu.Lock()
+	require.Contains(t, srvc.executions, id)
+	require.Equal(t, 1, srvc.attempts[id].retries)
+	require.True(t, srvc.attempts[id].startedAt.IsZero())
+	srvc.mu.Unlock()
+}
+
+func TestWatchdogRepublishesAfterSilence(t *testing.T) {
+	cfg := WatchdogConfig{TotalTimeout: time.Hour, EventTimeout: 20 * time.Second, QueueTimeout: time.Hour, MaxRetries: 1}
+	srvc, _, id, published := enqueueSupervised(t, cfg)
+
+	srvc.handleEvent(t.Context(), id, ReceivedSubmission{StartedAt: time.Now()})
+	srvc.checkDeadlines(t.Context(), time.Now().Add(10*time.Second))
+	require.Len(t, *published, 1)
+
+	srvc.checkDeadlines(t.Context(), time.Now().Add(time.Minute))
+	require.Len(t, *published, 2)
+}
+
+func TestWatchdogKeepsQueuedJobs(t *testing.T) {
+	cfg := WatchdogConfig{TotalTimeout: time.Minute, EventTimeout: time.Second, QueueTimeout: time.Minute, MaxRetries: 1}
+	srvc, _, id, published := enqueueSupervised(t, cfg)
+
+	// no tester online: nothing could run the job again
+	later := time.Now().Add(time.Hour)
+	srvc.checkDeadlines(t.Context(), later)
+	require.Len(t, *published, 1)
+
+	// busy testers may still hold the job in their queue
+	require.NoError(t, srvc.testers.record(Heartbeat{TesterID: "a", Running: []uuid.UUID{uuid.New()}}, later))
+	require.NoError(t, srvc.testers.record(Heartbeat{TesterID: "b"}, later))
+	srvc.checkDeadlines(t.Context(), later)
+	require.Len(t, *published, 1)
+
+	// every online tester is idle, so the job was lost
+	require.NoError(t, srvc.testers.record(Heartbeat{TesterID: "a"}, later))
+	srvc.checkDeadlines(t.Context(), later)
+	require.Len(t, *published, 2)
+
+	srvc.mu.Lock()
+	require.Equal(t, 1, srvc.attempts[id].retries)
+	srvc.mu.Unlock()
+}
+
+func TestWatchdogLeavesQueuedJetStreamJobs(t *testing.T) {
+	cfg := WatchdogConfig{TotalTimeout: time.Minute, EventTimeout: time.Second, QueueTimeout: time.Minute, MaxRetries: 1}
+	srvc, _, _, published := enqueueSupervised(t, cfg)
+	js, err := jetstream.New(&nats.Conn{})
+	require.NoError(t, err)
+	srvc.js = js
+
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, srvc.testers.record(Heartbeat{TesterID: "a"}, later))
+	srvc.checkDeadlines(t.Context(), later)
+	require.Len(t, *published, 1)
+}
+
+func TestWatchdogFailsExecutionAfterRetries(t *testing.T) {
+	cfg := WatchdogConfig{TotalTimeout: time.Minute, EventTimeout: time.Second, MaxRetries: 0}
+	srvc, repo, id, published := enqueueSupervised(t, cfg)
+
+	events, err := srvc.Listen(t.Context(), id)
+	require.NoError(t, err)
+
+	srvc.handleEvent(t.Context(), id, ReceivedSubmission{StartedAt: time.Now()})
+	srvc.checkDeadlines(t.Context(), time.Now().Add(2*time.Minute))
+	require.Len(t, *published, 1)
+
+	ev, ok := <-events
+	require.True(t, ok)
+	require.IsType(t, ReceivedSubmission{}, ev)
+	ev, ok = <-events
+	require.True(t, ok)
+	ise, ok := ev.(InternalServerError)
+	require.True(t, ok)
+	require.NotNil(t, ise.ErrorMsg)
+	_, ok = <-events
+	require.False(t, ok)
+
+	exec, err := srvc.Get(t.Context(), id)
+	require.NoError(t, err)
+	require.Equal(t, StageInternalError, exec.Stage)
+
+	pending, listErr := repo.ListPending(t.Context())
+	require.NoError(t, listErr)
+	require.Empty(t, pending)
+	srvc.mu.Lock()
+	require.NotContains(t, srvc.attempts, id)
+	srvc.mu.Unlock()
+}
@@ -1,6 +1,7 @@
 package plang
 
 import (
+	"path"
 	"strings"
 
 	"github.com/programme-lv/backend/common/srvcerror"
@@ -37,6 +38,22 @@ func GetProgrLangById(langId string) (*ProgrammingLang, srvcerror.E) {
 	return nil, ErrInvalidProgLang
 }
 
+// GetEnabledProgrLangByFname returns the enabled language whose source
+// files have the extension of fname, e.g. C++17 for "sol.cc".
+func GetEnabledProgrLangByFname(fname string) (*ProgrammingLang, srvcerror.E) {
+	ext := strings.ToLower(path.Ext(fname))
+	switch ext {
+	case ".cc", ".cxx":
+		ext = ".cpp"
+	}
+	for _, lang := range getHardcodedLanguageList() {
+		if lang.Enabled && ext != "" && path.Ext(lang.CodeFilename) == ext {
+			return &lang, nil
+		}
+	}
+	return nil, ErrInvalidProgLang
+}
+
 func SearchProgrLangByName(name string) ([]string, error) {
 	langs := getHardcodedLanguageList()
 	name = strings.ToLower(name)
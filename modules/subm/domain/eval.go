@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/programme-lv/backend/modules/exec"
 	"github.com/programme-lv/backend/modules/task/srvc"
 )
 
@@ -42,6 +43,12 @@ type Eval struct {
 	CpuLimMs   int
 	MemLimKiB  int
 
+	// revision of the task content the evaluation was created from
+	TaskRevision int
+
+	// job queue lane the evaluation is executed in
+	Lane exec.Lane
+
 	CreatedAt time.Time
 }
 
@@ -55,6 +62,7 @@ type EvalErrorType string
 const (
 	ErrorTypeCompilation EvalErrorType = "compilation"
 	ErrorTypeInternal    EvalErrorType = "internal"
+	ErrorTypeCancelled   EvalErrorType = "cancelled"
 )
 
 type Test struct {
@@ -63,6 +71,7 @@ type Test struct {
 	Tle bool // time limit exceeded
 	Mle bool // memory limit exceeded
 	Re  bool // runtime error
+	Pc  bool // partially correct
 	Ig  bool // ignored
 
 	Reached  bool // reached by tester
@@ -73,6 +82,24 @@ type Test struct {
 
 	CpuMs  *int // cpu exec 'user' time in milliseconds (nullable)
 	MemKiB *int // rss memory in kibibytes (nullable)
+
+	Score *float64 // checker's share of the test's points in [0, 1] (nullable)
+}
+
+// Fraction returns the share of the test's points the submission
+// received. Only accepted and partially correct tests score.
+func (t Test) Fraction() float64 {
+	if t.Ac {
+		return 1
+	}
+	if t.Pc && t.Score != nil {
+		return *t.Score
+	}
+	return 0
+}
+
+func (t Test) failed() bool {
+	return t.Wa || t.Tle || t.Mle || t.Re
 }
 
 type Subtask struct {
@@ -114,6 +141,7 @@ func NewEval(uuid uuid.UUID, submUuid uuid.UUID, task srvc.Task) Eval {
 			Tle:       false,
 			Mle:       false,
 			Re:        false,
+			Pc:        false,
 			Ig:        false,
 			Reached:   false,
 			Finished:  false,
@@ -144,5 +172,23 @@ func NewEval(uuid uuid.UUID, submUuid uuid.UUID, task srvc.Task) Eval {
 		CpuLimMs:   task.CpuMillis(),
 		MemLimKiB:  task.MemoryKiB(),
 		CreatedAt:  time.Now(),
+
+		TaskRevision: task.Revision,
+	}
+}
+
+// ResetProgress returns the evaluation to the waiting stage and
+// clears every test verdict, keeping the snapshotted tests and
+// scoring units. Used when the execution restarts from scratch.
+func (e *Eval) ResetProgress() {
+	e.Stage = EvalStageWaiting
+	e.Error = nil
+	tests := make([]Test, len(e.Tests))
+	for i, test := range e.Tests {
+		tests[i] = Test{
+			InpSha256: test.InpSha256,
+			AnsSha256: test.AnsSha256,
+		}
 	}
+	e.Tests = tests
 }
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvalResetProgress(t *testing.T) {
+	cpuMs := 12
+	eval := Eval{
+		Stage: EvalStageTesting,
+		Error: &EvalError{Type: ErrorTypeInternal},
+		Tests: []Test{
+			{Ac: true, Reached: true, Finished: true, InpSha256: "in", AnsSha256: "ans", CpuMs: &cpuMs},
+			{Ig: true},
+		},
+	}
+	original := eval.Tests
+
+	eval.ResetProgress()
+
+	assert.Equal(t, EvalStageWaiting, eval.Stage)
+	assert.Nil(t, eval.Error)
+	assert.Equal(t, []Test{{InpSha256: "in", AnsSha256: "ans"}, {}}, eval.Tests)
+	assert.True(t, original[0].Ac, "reset must not alias the previous tests")
+}
@@ -9,7 +9,7 @@ import (
 // max score the user has received on a subm for a specific task
 type MaxScore struct {
 	SubmUuid  uuid.UUID
-	Received  int
+	Received  float64
 	Possible  int
 	FirstTime time.Time // first time the user got a score this high
 }
@@ -1,5 +1,7 @@
 package domain
 
+import "math"
+
 // ScoreBarInfo contains the percentage distribution of test results
 type ScoreBarInfo struct {
 	Green  int // accepted tests
@@ -12,7 +14,7 @@ type ScoreBarInfo struct {
 // ScoreInfo contains all scoring related information for an evaluation
 type ScoreInfo struct {
 	ScoreBar      ScoreBarInfo
-	ReceivedScore int
+	ReceivedScore float64 // fractional when checkers award partial points
 	PossibleScore int
 	MaxCpuMs      int
 	MaxMemKiB     int
@@ -22,7 +24,7 @@ type ScoreInfo struct {
 
 // CalculateScore calculates scoring information from an evaluation
 func (e *Eval) CalculateScore() ScoreInfo {
-	gotScore := 0
+	gotScore := 0.0
 	maxScore := 0
 	green := 0
 	red := 0
@@ -37,31 +39,28 @@ func (e *Eval) CalculateScore() ScoreInfo {
 		}
 		if e.Error == nil {
 			for _, testGroup := range e.Groups {
-				allUncreached := true
-				allAccepted := true
-				hasWrong := false
-				for _, testIdx := range testGroup.TgTests {
-					test := e.Tests[testIdx-1]
-					if test.Reached {
-						allUncreached = false
-					}
-					if !test.Ac {
-						allAccepted = false
-					}
-					if test.Wa || test.Tle || test.Mle || test.Re {
-						hasWrong = true
-					}
-				}
-				if allUncreached {
-					gray += testGroup.Points
-				} else if allAccepted {
-					green += testGroup.Points
-					gotScore += testGroup.Points
-				} else if hasWrong {
-					red += testGroup.Points
-				} else {
-					yellow += testGroup.Points
-				}
+				bar, received := e.scoreTestSet(testGroup.Points, testGroup.TgTests)
+				gotScore += received
+				green += bar.Green
+				red += bar.Red
+				gray += bar.Gray
+				yellow += bar.Yellow
+			}
+		} else {
+			purple = 100
+		}
+	case ScoreUnitSubtask:
+		for _, subtask := range e.Subtasks {
+			maxScore += subtask.Points
+		}
+		if e.Error == nil {
+			for _, subtask := range e.Subtasks {
+				bar, received := e.scoreTestSet(subtask.Points, subtask.StTests)
+				gotScore += received
+				green += bar.Green
+				red += bar.Red
+				gray += bar.Gray
+				yellow += bar.Yellow
 			}
 		} else {
 			purple = 100
@@ -71,14 +70,19 @@ func (e *Eval) CalculateScore() ScoreInfo {
 		if e.Error == nil {
 			for _, test := range e.Tests {
 				if test.Ac {
-					green += 1
+					green += barScale
 					gotScore += 1
-				} else if test.Wa || test.Tle || test.Mle || test.Re {
-					red += 1
+				} else if test.Pc {
+					part := splitBar(barScale, test.Fraction())
+					green += part.Green
+					red += part.Red
+					gotScore += test.Fraction()
+				} else if test.failed() {
+					red += barScale
 				} else if test.Reached {
-					yellow += 1
+					yellow += barScale
 				} else {
-					gray += 1
+					gray += barScale
 				}
 			}
 		} else {
@@ -134,8 +138,60 @@ func (e *Eval) CalculateScore() ScoreInfo {
 	}
 }
 
+// barScale multiplies score bar units before normalization so that
+// partial points can be split between green and red.
+const barScale = 100
+
+// scoreTestSet scores a scoring unit (test group or subtask) by its
+// weakest 1-based test: the unit receives the lowest test fraction of
+// its points once every test is accepted or partially correct.
+func (e *Eval) scoreTestSet(points int, testIDs []int) (ScoreBarInfo, float64) {
+	units := points * barScale
+	allUnreached := true
+	allScored := true
+	hasWrong := false
+	minFraction := 1.0
+	for _, testID := range testIDs {
+		if testID < 1 || testID > len(e.Tests) {
+			allScored = false
+			continue
+		}
+		test := e.Tests[testID-1]
+		if test.Reached {
+			allUnreached = false
+		}
+		if !test.Ac && !test.Pc {
+			allScored = false
+		}
+		if test.failed() {
+			hasWrong = true
+		}
+		minFraction = min(minFraction, test.Fraction())
+	}
+	switch {
+	case allUnreached:
+		return ScoreBarInfo{Gray: units}, 0
+	case allScored:
+		return splitBar(units, minFraction), float64(points) * minFraction
+	case hasWrong:
+		return ScoreBarInfo{Red: units}, 0
+	default:
+		return ScoreBarInfo{Yellow: units}, 0
+	}
+}
+
+// splitBar colors the received share of units green and the rest red.
+func splitBar(units int, fraction float64) ScoreBarInfo {
+	green := int(math.Round(float64(units) * fraction))
+	return ScoreBarInfo{Green: green, Red: units - green}
+}
+
 func normalizeColors(green *int, red *int, gray *int, yellow *int, purple *int) {
 	total := *green + *red + *gray + *yellow + *purple
+	if total == 0 {
+		*gray = 100
+		return
+	}
 	newGreen := *green * 100 / total
 	newRed := *red * 100 / total
 	newYellow := *yellow * 100 / total
@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateScore_Subtasks(t *testing.T) {
+	eval := Eval{
+		ScoreUnit: ScoreUnitSubtask,
+		Subtasks: []Subtask{
+			{Points: 20, StTests: []int{1, 2}},
+			{Points: 30, StTests: []int{3}},
+			{Points: 50, StTests: []int{4, 5}},
+		},
+		Tests: []Test{
+			{Ac: true, Reached: true, Finished: true},
+			{Ac: true, Reached: true, Finished: true},
+			{Wa: true, Reached: true, Finished: true},
+			{Reached: true},
+			{},
+		},
+		CpuLimMs:  1000,
+		MemLimKiB: 256 * 1024,
+	}
+
+	info := eval.CalculateScore()
+	assert.Equal(t, 20.0, info.ReceivedScore)
+	assert.Equal(t, 100, info.PossibleScore)
+	assert.Equal(t, ScoreBarInfo{Green: 20, Red: 30, Yellow: 50}, info.ScoreBar)
+}
+
+func TestCalculateScore_SubtasksNotReached(t *testing.T) {
+	eval := Eval{
+		ScoreUnit: ScoreUnitSubtask,
+		Subtasks: []Subtask{
+			{Points: 40, StTests: []int{1}},
+			{Points: 60, StTests: []int{2}},
+		},
+		Tests: []Test{{}, {}},
+	}
+
+	info := eval.CalculateScore()
+	assert.Equal(t, 0.0, info.ReceivedScore)
+	assert.Equal(t, 100, info.PossibleScore)
+	assert.Equal(t, ScoreBarInfo{Gray: 100}, info.ScoreBar)
+}
+
+func TestCalculateScore_SubtasksError(t *testing.T) {
+	eval := Eval{
+		ScoreUnit: ScoreUnitSubtask,
+		Error:     &EvalError{Type: ErrorTypeCompilation},
+		Subtasks:  []Subtask{{Points: 7, StTests: []int{1}}},
+		Tests:     []Test{{}},
+	}
+
+	info := eval.CalculateScore()
+	assert.Equal(t, 0.0, info.ReceivedScore)
+	assert.Equal(t, 7, info.PossibleScore)
+	assert.Equal(t, ScoreBarInfo{Purple: 100}, info.ScoreBar)
+}
+
+func TestCalculateScore_TestGroups(t *testing.T) {
+	eval := Eval{
+		ScoreUnit: ScoreUnitTestGroup,
+		Groups: []TestGroup{
+			{Points: 25, TgTests: []int{1}},
+			{Points: 75, TgTests: []int{2, 3}},
+		},
+		Tests: []Test{
+			{Ac: true, Reached: true, Finished: true},
+			{Ac: true, Reached: true, Finished: true},
+			{Tle: true, Reached: true, Finished: true},
+		},
+	}
+
+	info := eval.CalculateScore()
+	assert.Equal(t, 25.0, info.ReceivedScore)
+	assert.Equal(t, 100, info.PossibleScore)
+	assert.Equal(t, ScoreBarInfo{Green: 25, Red: 75}, info.ScoreBar)
+}
+
+func TestCalculateScore_NoScoringUnits(t *testing.T) {
+	eval := Eval{ScoreUnit: ScoreUnitSubtask}
+
+	info := eval.CalculateScore()
+	assert.Equal(t, 0, info.PossibleScore)
+	assert.Equal(t, ScoreBarInfo{Gray: 100}, info.ScoreBar)
+}
+
+func TestCalcMaxScores_Subtasks(t *testing.T) {
+	eval := Eval{
+		ScoreUnit: ScoreUnitSubtask,
+		Subtasks:  []Subtask{{Points: 10, StTests: []int{1}}, {Points: 90, StTests: []int{2}}},
+		Tests:     []Test{{Ac: true, Reached: true}, {Wa: true, Reached: true}},
+	}
+
+	scores := CalcMaxScores([]SubmJoinScoreInfo{
+		{TaskShortID: "sum", ScoreInfo: eval.CalculateScore()},
+	})
+	assert.Equal(t, 10.0, scores["sum"].Received)
+	assert.Equal(t, 100, scores["sum"].Possible)
+}
+
+func TestCalculateScore_PartialTests(t *testing.T) {
+	half := 0.5
+	eval := Eval{
+		ScoreUnit: ScoreUnitTest,
+		Tests: []Test{
+			{Ac: true, Reached: true, Finished: true},
+			{Pc: true, Score: &half, Reached: true, Finished: true},
+			{Wa: true, Reached: true, Finished: true},
+			{},
+		},
+	}
+
+	info := eval.CalculateScore()
+	assert.Equal(t, 1.5, info.ReceivedScore)
+	assert.Equal(t, 4, info.PossibleScore)
+	assert.Equal(t, ScoreBarInfo{Green: 37, Red: 37, Gray: 26}, info.ScoreBar)
+}
+
+func TestCalculateScore_GroupTakesWeakestTest(t *testing.T) {
+	low, high := 0.25, 0.75
+	eval := Eval{
+		ScoreUnit: ScoreUnitTestGroup,
+		Groups: []TestGroup{
+			{Points: 40, TgTests: []int{1, 2, 3}},
+			{Points: 60, TgTests: []int{4, 5}},
+		},
+		Tests: []Test{
+			{Ac: true, Reached: true, Finished: true},
+			{Pc: true, Score: &high, Reached: true, Finished: true},
+			{Pc: true, Score: &low, Reached: true, Finished: true},
+			{Pc: true, Score: &high, Reached: true, Finished: true},
+			{Wa: true, Reached: true, Finished: true},
+		},
+	}
+
+	info := eval.CalculateScore()
+	assert.Equal(t, 10.0, info.ReceivedScore)
+	assert.Equal(t, ScoreBarInfo{Green: 10, Red: 90}, info.ScoreBar)
+}
@@ -25,6 +25,7 @@ type SubmHttpHandler struct {
 
 	// solution submission rate limit
 	lastSubmTime map[string]time.Time // username -> last submission time
+	lastRunTime  map[string]time.Time // username -> last custom run time
 	rateLock     sync.Mutex
 
 	// submCache and singleflight for preventing submCache stampedes
@@ -42,10 +43,30 @@ func NewSubmHttpHandler(
 		taskSrvc:     taskSrvc,
 		userSrvc:     userSrvc,
 		lastSubmTime: make(map[string]time.Time),
+		lastRunTime:  make(map[string]time.Time),
 		submCache:    cache.New(1*time.Second, 1*time.Minute),
 	}
 }
 
+// StartCacheInvalidation flushes the submission list cache whenever
+// a submission is created or finishes evaluating on any replica,
+// until ctx ends.
+func (h *SubmHttpHandler) StartCacheInvalidation(ctx context.Context) error {
+	updates, err := h.submSrvc.SubscribeUpdates(ctx, "")
+	if err != nil {
+		return err
+	}
+	go func() {
+		for update := range updates {
+			finished := update.EvalUpdate != nil && update.EvalUpdate.Stage == domain.EvalStageFinished
+			if update.SubmCreated != nil || finished {
+				h.submCache.Flush()
+			}
+		}
+	}()
+	return nil
+}
+
 func (h *SubmHttpHandler) newLogger(ctx context.Context) *slog.Logger {
 	return ctxlog.FromContext(ctx).With("module", "subm", "layer", "http")
 }
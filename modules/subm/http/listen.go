@@ -2,6 +2,7 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -35,13 +36,7 @@ func (h *SubmHttpHandler) ListenToSubmListUpdates(w http.ResponseWriter, r *http
 		return
 	}
 
-	submCreatedCh, err := h.submSrvc.SubscribeNewSubms(r.Context())
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	evalUpdateCh, err := h.submSrvc.SubscribeEvalUpds(r.Context())
+	updateCh, err := h.submSrvc.SubscribeUpdates(r.Context(), r.Header.Get("Last-Event-ID"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -67,39 +62,29 @@ func (h *SubmHttpHandler) ListenToSubmListUpdates(w http.ResponseWriter, r *http
 		select {
 		case <-keepAliveTicker.C:
 			safeWrite(": keep-alive\n\n")
-		case submCreated, ok := <-submCreatedCh:
+		case update, ok := <-updateCh:
 			if !ok {
 				return
 			}
-			entry, err := h.mapSubmListEntry(r.Context(), submCreated)
-			if err != nil {
-				slog.Default().Warn("map subm list entry", "error", err, "subm_uuid", submCreated.UUID)
-				continue
-			}
-			message := SubmissionListUpdate{
-				SubmCreated: &entry,
-			}
-			marshalled, err := json.Marshal(message)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-			safeWrite("data: " + string(marshalled) + "\n\n")
-		case evalUpdate, ok := <-evalUpdateCh:
-			if !ok {
-				return
+			var message SubmissionListUpdate
+			if update.SubmCreated != nil {
+				entry, err := h.mapSubmListEntry(r.Context(), *update.SubmCreated)
+				if err != nil {
+					slog.Default().Warn("map subm list entry", "error", err, "subm_uuid", update.SubmCreated.UUID)
+					continue
+				}
+				message.SubmCreated = &entry
 			}
-			mappedEval := mapSubmEval(evalUpdate)
-			message := SubmissionListUpdate{
-				EvalUpdate: &mappedEval,
+			if update.EvalUpdate != nil {
+				mappedEval := mapSubmEval(*update.EvalUpdate)
+				message.EvalUpdate = &mappedEval
 			}
 			marshalled, err := json.Marshal(message)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
-			// slog.Info("eval update", "message", string(marshalled))
-			safeWrite("data: " + string(marshalled) + "\n\n")
+			safeWrite(fmt.Sprintf("id: %s\ndata: %s\n\n", update.EventID, marshalled))
 		}
 	}
 }
@@ -197,6 +197,8 @@ func mapSubmEval(eval domain.Eval) Eval {
 				verdicts += "M" // memory limit exceeded
 			} else if test.Re {
 				verdicts += "R" // runtime error
+			} else if test.Pc {
+				verdicts += "P" // partially correct
 			} else {
 				verdicts += "U" // unknown
 			}
@@ -210,14 +212,15 @@ func mapSubmEval(eval domain.Eval) Eval {
 	scoreInfo := eval.CalculateScore()
 
 	return Eval{
-		EvalUUID:   eval.UUID.String(),
-		SubmUUID:   eval.SubmUUID.String(),
-		EvalStage:  string(eval.Stage),
-		ScoreUnit:  string(eval.ScoreUnit),
-		EvalError:  errType,
-		Subtasks:   subtasks,
-		TestGroups: testGroups,
-		Verdicts:   verdicts,
+		EvalUUID:     eval.UUID.String(),
+		SubmUUID:     eval.SubmUUID.String(),
+		EvalStage:    string(eval.Stage),
+		ScoreUnit:    string(eval.ScoreUnit),
+		EvalError:    errType,
+		TaskRevision: eval.TaskRevision,
+		Subtasks:     subtasks,
+		TestGroups:   testGroups,
+		Verdicts:     verdicts,
 		ScoreInfo: ScoreInfo{
 			ScoreBar: struct {
 				Green  int `json:"green"`
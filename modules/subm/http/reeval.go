@@ -2,6 +2,7 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -35,3 +36,37 @@ func (h *SubmHttpHandler) ReevalSubms(w http.ResponseWriter, r *http.Request) {
 
 	jsonresp.Success(w, "reevaluation enqueued for all provided submissions")
 }
+
+// ReevalOutdatedSubms re-evaluates submissions to task_id whose
+// evaluation predates the task's current revision.
+func (h *SubmHttpHandler) ReevalOutdatedSubms(w http.ResponseWriter, r *http.Request) {
+	l := h.newLogger(r.Context())
+
+	type reevalOutdatedRequest struct {
+		TaskID string `json:"task_id"`
+	}
+	type reevalOutdatedResponse struct {
+		Count int `json:"count"`
+	}
+
+	var request reevalOutdatedRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		jsonresp.BadRequest(w, "nederīgs JSON")
+		return
+	}
+	if request.TaskID == "" {
+		jsonresp.BadRequest(w, "no task id provided")
+		return
+	}
+
+	count, err := h.submSrvc.ReEvalOutdated(r.Context(), request.TaskID)
+	if err != nil {
+		if count > 0 {
+			err = err.WithMsg(fmt.Sprintf("%s (atkārtoti novērtēti %d iesūtījumi)", err.Error(), count))
+		}
+		jsonresp.HandleSrvcError(l, w, err)
+		return
+	}
+
+	jsonresp.Success(w, reevalOutdatedResponse{Count: count})
+}
@@ -13,6 +13,7 @@ func (h *SubmHttpHandler) RegisterRoutes(r *chi.Mux, jwtKey, adminAPIKey []byte,
 			auth.WithPasswordChangedAtLookup(pwdChangedAt),
 		))
 		r.Post("/subm", h.PostSubm)
+		r.Post("/run", h.PostRun)
 		r.Get("/subm", h.GetSubmList)
 		r.Get("/subm/{subm-id}", h.GetFullSubm)
 		r.Get("/subm/scores/{username}", h.GetMaxScorePerTask)
@@ -22,6 +23,7 @@ func (h *SubmHttpHandler) RegisterRoutes(r *chi.Mux, jwtKey, adminAPIKey []byte,
 		r.Group(func(r chi.Router) {
 			r.Use(auth.HttpAllowOnlyAdmins(adminAPIKey))
 			r.Post("/reeval", h.ReevalSubms)
+			r.Post("/reeval/outdated", h.ReevalOutdatedSubms)
 		})
 	})
 }
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/programme-lv/backend/common/ctxlog"
+	"github.com/programme-lv/backend/common/jsonresp"
+	"github.com/programme-lv/backend/common/srvcerror"
+	"github.com/programme-lv/backend/modules/exec"
+	"github.com/programme-lv/backend/modules/subm/srvc"
+	"github.com/programme-lv/backend/modules/user/auth"
+)
+
+const customRunInterval = 5 * time.Second
+
+var ErrCustomRunTooFrequent = srvcerror.New(
+	"custom_run_too_frequent",
+	"Uzgaidiet pirms nākamās izpildes!",
+).SetHttpStatusCode(http.StatusTooManyRequests)
+
+// RunOutput is the program's runtime data for one stage.
+type RunOutput struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int64  `json:"exit_code"`
+	CpuMs    int64  `json:"cpu_ms"`
+	WallMs   int64  `json:"wall_ms"`
+	MemKiB   int64  `json:"mem_kib"`
+}
+
+// RunUpdate is one server-sent event of a custom run.
+// Type is compiled, test, compile_error, internal_error or finished.
+type RunUpdate struct {
+	Type     string     `json:"type"`
+	TestID   int        `json:"test_id,omitempty"`
+	Output   *RunOutput `json:"output,omitempty"`
+	ErrorMsg *string    `json:"error_msg,omitempty"`
+}
+
+func (h *SubmHttpHandler) PostRun(w http.ResponseWriter, r *http.Request) {
+	log := ctxlog.FromContext(r.Context())
+
+	type runRequest struct {
+		SrcCode     string   `json:"src_code"`
+		ProgrLangID string   `json:"programming_lang_id"`
+		Stdins      []string `json:"stdins"`
+		TaskCodeID  *string  `json:"task_code_id"`
+	}
+
+	claims, _ := r.Context().Value(auth.CtxJwtClaimsKey).(*auth.JwtClaims)
+	if claims == nil {
+		log.Warn("JWT token missing")
+		jsonresp.HandleErrorWithContext(r.Context(), w, ErrJwtTokenMissing)
+		return
+	}
+
+	var request runRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Warn("decode request body", "error", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	h.rateLock.Lock()
+	lastTime, exists := h.lastRunTime[claims.Username]
+	now := time.Now()
+	if exists && now.Sub(lastTime) < customRunInterval {
+		h.rateLock.Unlock()
+		log.Warn("custom run too frequent", "username", claims.Username, "last_time", lastTime)
+		jsonresp.HandleErrorWithContext(r.Context(), w, ErrCustomRunTooFrequent)
+		return
+	}
+	h.lastRunTime[claims.Username] = now
+	h.rateLock.Unlock()
+
+	events, err := h.submSrvc.RunCustom(r.Context(), srvc.RunCustomParams{
+		SrcCode:     request.SrcCode,
+		ProgrLangID: request.ProgrLangID,
+		Stdins:      request.Stdins,
+		TaskShortID: request.TaskCodeID,
+	})
+	if err != nil {
+		jsonresp.HandleErrorWithContext(r.Context(), w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	flusher, _ := w.(http.Flusher)
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			update, ok := mapRunEvent(event)
+			if !ok {
+				continue
+			}
+			marshalled, err := json.Marshal(update)
+			if err != nil {
+				log.Error("marshal run update", "error", err)
+				return
+			}
+			io.WriteString(w, "data: "+string(marshalled)+"\n\n")
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// mapRunEvent keeps the events a custom run shows to its author.
+func mapRunEvent(event exec.Event) (RunUpdate, bool) {
+	switch e := event.(type) {
+	case exec.FinishedCompiling:
+		return RunUpdate{Type: "compiled", Output: mapRunOutput(e.RuntimeData)}, true
+	case exec.FinishedTest:
+		return RunUpdate{Type: "test", TestID: e.TestID, Output: mapRunOutput(e.Subm)}, true
+	case exec.CompilationError:
+		return RunUpdate{Type: "compile_error", ErrorMsg: e.ErrorMsg}, true
+	case exec.InternalServerError:
+		return RunUpdate{Type: "internal_error", ErrorMsg: e.ErrorMsg}, true
+	case exec.FinishedTesting:
+		return RunUpdate{Type: "finished"}, true
+	}
+	return RunUpdate{}, false
+}
+
+func mapRunOutput(rd *exec.RunData) *RunOutput {
+	if rd == nil {
+		return nil
+	}
+	return &RunOutput{
+		Stdout:   rd.StdOut,
+		Stderr:   rd.StdErr,
+		ExitCode: rd.ExitCode,
+		CpuMs:    rd.CpuMs,
+		WallMs:   rd.WallMs,
+		MemKiB:   rd.MemKiB,
+	}
+}
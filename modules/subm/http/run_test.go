@@ -0,0 +1,27 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/programme-lv/backend/modules/exec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapRunEvent(t *testing.T) {
+	update, ok := mapRunEvent(exec.FinishedTest{
+		TestID: 2,
+		Subm:   &exec.RunData{StdOut: "3\n", StdErr: "warn", CpuMs: 12, WallMs: 20, MemKiB: 4096},
+	})
+	require.True(t, ok)
+	assert.Equal(t, "test", update.Type)
+	assert.Equal(t, 2, update.TestID)
+	assert.Equal(t, &RunOutput{Stdout: "3\n", Stderr: "warn", CpuMs: 12, WallMs: 20, MemKiB: 4096}, update.Output)
+
+	_, ok = mapRunEvent(exec.ReachedTest{TestId: 1})
+	assert.False(t, ok)
+
+	update, ok = mapRunEvent(exec.FinishedTesting{})
+	require.True(t, ok)
+	assert.Equal(t, "finished", update.Type)
+}
@@ -41,10 +41,12 @@ type Eval struct {
 	EvalStage string `json:"eval_stage"`
 	ScoreUnit string `json:"score_unit"`
 	EvalError string `json:"eval_error"`
+	// TaskRevision is the task revision the evaluation was created from
+	TaskRevision int `json:"task_revision"`
 	// ErrorMsg   string      `json:"error_msg"`
 	Subtasks   []Subtask   `json:"subtasks"`
 	TestGroups []TestGroup `json:"test_groups"`
-	Verdicts   string      `json:"verdicts"` // q,ac,wa,tle,mle,re,ig -> "QAWTMRI"
+	Verdicts   string      `json:"verdicts"` // q,ac,wa,tle,mle,re,pc,ig -> "QAWTMRPI"
 	ScoreInfo  ScoreInfo   `json:"score_info"`
 }
 
@@ -56,12 +58,12 @@ type ScoreInfo struct {
 		Yellow int `json:"yellow"`
 		Purple int `json:"purple"`
 	} `json:"score_bar"`
-	ReceivedScore int  `json:"received"`
-	PossibleScore int  `json:"possible"`
-	MaxCpuMs      int  `json:"max_cpu_ms"`  // milliseconds
-	MaxMemKiB     int  `json:"max_mem_kib"` // kibibytes
-	ExceededCpu   bool `json:"exceeded_cpu"`
-	ExceededMem   bool `json:"exceeded_mem"`
+	ReceivedScore float64 `json:"received"`
+	PossibleScore int     `json:"possible"`
+	MaxCpuMs      int     `json:"max_cpu_ms"`  // milliseconds
+	MaxMemKiB     int     `json:"max_mem_kib"` // kibibytes
+	ExceededCpu   bool    `json:"exceeded_cpu"`
+	ExceededMem   bool    `json:"exceeded_mem"`
 }
 
 type Subtask struct {
@@ -79,11 +81,11 @@ type TestGroup struct {
 }
 
 type MaxScore struct {
-	SubmUuid     string `json:"subm_uuid"`
-	Received     int    `json:"received"`
-	Possible     int    `json:"possible"`
-	CreatedAt    string `json:"created_at"`
-	TaskFullName string `json:"task_full_name"`
+	SubmUuid     string  `json:"subm_uuid"`
+	Received     float64 `json:"received"`
+	Possible     int     `json:"possible"`
+	CreatedAt    string  `json:"created_at"`
+	TaskFullName string  `json:"task_full_name"`
 }
 
 type TaskPreview struct {
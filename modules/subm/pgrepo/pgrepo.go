@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/programme-lv/backend/modules/exec"
 	"github.com/programme-lv/backend/modules/subm/domain"
 	"github.com/programme-lv/backend/modules/subm/srvc"
 )
@@ -43,8 +44,8 @@ func (r *pgEvalRepo) StoreEval(ctx context.Context, eval domain.Eval) error {
 			cpu_lim_ms, mem_lim_kib, error_type, error_message, created_at,
 			received_score, possible_score, scorebar_green, scorebar_red,
 			scorebar_gray, scorebar_yellow, scorebar_purple,
-			cpu_max_ms, mem_max_kib, exceeded_cpu, exceeded_mem
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+			cpu_max_ms, mem_max_kib, exceeded_cpu, exceeded_mem, task_revision, lane
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
 		ON CONFLICT (uuid) DO UPDATE SET
 			subm_uuid = EXCLUDED.subm_uuid,
 			stage = EXCLUDED.stage,
@@ -66,7 +67,9 @@ func (r *pgEvalRepo) StoreEval(ctx context.Context, eval domain.Eval) error {
 			cpu_max_ms = EXCLUDED.cpu_max_ms,
 			mem_max_kib = EXCLUDED.mem_max_kib,
 			exceeded_cpu = EXCLUDED.exceeded_cpu,
-			exceeded_mem = EXCLUDED.exceeded_mem
+			exceeded_mem = EXCLUDED.exceeded_mem,
+			task_revision = EXCLUDED.task_revision,
+			lane = EXCLUDED.lane
 	`
 	var errorType *string
 	var errorMessage *string
@@ -99,6 +102,8 @@ func (r *pgEvalRepo) StoreEval(ctx context.Context, eval domain.Eval) error {
 		scoreInfo.MaxMemKiB,
 		scoreInfo.ExceededCpu,
 		scoreInfo.ExceededMem,
+		eval.TaskRevision,
+		evalLane(eval.Lane),
 	)
 	if err != nil {
 		return fmt.Errorf("upsert evaluation: %w", err)
@@ -157,9 +162,9 @@ func (r *pgEvalRepo) StoreEval(ctx context.Context, eval domain.Eval) error {
 	for i, test := range eval.Tests {
 		testInsertQuery := `
 			INSERT INTO eval_test_results (
-				evaluation_uuid, test_id, ac, wa, tle, mle, re, ig, reached, finished,
-				inp_sha256, ans_sha256, cpu_ms, mem_kib
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+				evaluation_uuid, test_id, ac, wa, tle, mle, re, pc, ig, reached, finished,
+				inp_sha256, ans_sha256, cpu_ms, mem_kib, score
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		`
 		_, err = tx.Exec(ctx, testInsertQuery,
 			eval.UUID,
@@ -169,6 +174,7 @@ func (r *pgEvalRepo) StoreEval(ctx context.Context, eval domain.Eval) error {
 			test.Tle,
 			test.Mle,
 			test.Re,
+			test.Pc,
 			test.Ig,
 			test.Reached,
 			test.Finished,
@@ -176,6 +182,7 @@ func (r *pgEvalRepo) StoreEval(ctx context.Context, eval domain.Eval) error {
 			nullableString(test.AnsSha256),
 			test.CpuMs,
 			test.MemKiB,
+			test.Score,
 		)
 		if err != nil {
 			return fmt.Errorf("insert test: %w", err)
@@ -195,7 +202,7 @@ func (r *pgEvalRepo) GetEval(ctx context.Context, evalUUID uuid.UUID) (domain.Ev
 	// Fetch Evaluation
 	evalQuery := `
 		SELECT uuid, subm_uuid, stage, score_unit, checker, interactor, cpu_lim_ms, mem_lim_kib,
-			   error_type, error_message, created_at
+			   error_type, error_message, created_at, task_revision, lane
 		FROM evaluations
 		WHERE uuid = $1
 	`
@@ -215,6 +222,8 @@ func (r *pgEvalRepo) GetEval(ctx context.Context, evalUUID uuid.UUID) (domain.Ev
 		&errorType,
 		&errorMessage,
 		&eval.CreatedAt,
+		&eval.TaskRevision,
+		&eval.Lane,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -282,7 +291,7 @@ func (r *pgEvalRepo) GetEval(ctx context.Context, evalUUID uuid.UUID) (domain.Ev
 
 	// Fetch Tests
 	testsQuery := `
-		SELECT ac, wa, tle, mle, re, ig, reached, finished, inp_sha256, ans_sha256, cpu_ms, mem_kib
+		SELECT ac, wa, tle, mle, re, pc, ig, reached, finished, inp_sha256, ans_sha256, cpu_ms, mem_kib, score
 		FROM eval_test_results
 		WHERE evaluation_uuid = $1
 		ORDER BY id ASC
@@ -302,6 +311,7 @@ func (r *pgEvalRepo) GetEval(ctx context.Context, evalUUID uuid.UUID) (domain.Ev
 			&test.Tle,
 			&test.Mle,
 			&test.Re,
+			&test.Pc,
 			&test.Ig,
 			&test.Reached,
 			&test.Finished,
@@ -309,6 +319,7 @@ func (r *pgEvalRepo) GetEval(ctx context.Context, evalUUID uuid.UUID) (domain.Ev
 			&ansSha256,
 			&test.CpuMs,
 			&test.MemKiB,
+			&test.Score,
 		)
 		if err != nil {
 			return domain.Eval{}, fmt.Errorf("scan test: %w", err)
@@ -328,6 +339,62 @@ func (r *pgEvalRepo) GetEval(ctx context.Context, evalUUID uuid.UUID) (domain.Ev
 	return eval, nil
 }
 
+// ListUnfinishedEvals returns the current evaluations of submissions
+// that have not reached the finished stage, oldest first.
+func (r *pgEvalRepo) ListUnfinishedEvals(ctx context.Context) ([]domain.Eval, error) {
+	query := `
+		SELECT e.uuid
+		FROM evaluations e
+		INNER JOIN submissions s ON s.curr_eval_uuid = e.uuid
+		WHERE e.stage <> $1
+		ORDER BY e.created_at ASC
+	`
+	rows, err := r.pool.Query(ctx, query, domain.EvalStageFinished)
+	if err != nil {
+		return nil, fmt.Errorf("query unfinished evaluations: %w", err)
+	}
+	evalUUIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("scan unfinished evaluations: %w", err)
+	}
+
+	evals := make([]domain.Eval, 0, len(evalUUIDs))
+	for _, evalUUID := range evalUUIDs {
+		eval, err := r.GetEval(ctx, evalUUID)
+		if err != nil {
+			return nil, err
+		}
+		evals = append(evals, eval)
+	}
+	return evals, nil
+}
+
+// ListTestSha256s returns the distinct input and answer hashes
+// of all stored evaluation test results.
+func (r *pgEvalRepo) ListTestSha256s(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT inp_sha256 FROM eval_test_results WHERE inp_sha256 IS NOT NULL
+		UNION
+		SELECT ans_sha256 FROM eval_test_results WHERE ans_sha256 IS NOT NULL
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query evaluation test hashes: %w", err)
+	}
+	sha256s, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("scan evaluation test hashes: %w", err)
+	}
+	return sha256s, nil
+}
+
+// evalLane stores the default lane of an evaluation explicitly.
+func evalLane(lane exec.Lane) exec.Lane {
+	if lane == "" {
+		return exec.LaneHigh
+	}
+	return lane
+}
+
 // Helper function to handle nullable strings
 func nullableString(s string) *string {
 	if s == "" {
@@ -574,6 +641,26 @@ func (r *pgSubmRepo) ListSubms(ctx context.Context, limit int, offset int, searc
 	return submissions, nil
 }
 
+// ListOutdatedSubms returns submissions to the task whose current
+// evaluation was created from a task revision older than revision.
+func (r *pgSubmRepo) ListOutdatedSubms(ctx context.Context, taskShortID string, revision int) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT s.uuid
+		FROM submissions s
+		INNER JOIN evaluations e ON s.curr_eval_uuid = e.uuid
+		WHERE s.task_shortid = $1 AND e.task_revision < $2
+		ORDER BY s.created_at ASC
+	`, taskShortID, revision)
+	if err != nil {
+		return nil, fmt.Errorf("query outdated submissions: %w", err)
+	}
+	submUUIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("scan outdated submissions: %w", err)
+	}
+	return submUUIDs, nil
+}
+
 // CountSubms returns the total number of submissions in the database
 func (r *pgSubmRepo) CountSubms(ctx context.Context, authorUuid *uuid.UUID, taskShortID string, authorIds []string, taskIds []string, langIds []string, includeAdmin bool) (int, error) {
 
@@ -646,6 +733,41 @@ func (r *pgSubmRepo) CountSubms(ctx context.Context, authorUuid *uuid.UUID, task
 	return count, nil
 }
 
+// CountSolversPerTask returns, for each of the tasks, how many distinct users
+// other than admin have a submission whose current evaluation got full score.
+// nil taskShortIDs counts every task.
+func (r *pgSubmRepo) CountSolversPerTask(ctx context.Context, taskShortIDs []string) (map[string]int, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT s.task_shortid, COUNT(DISTINCT s.author_uuid)
+		FROM submissions s
+		INNER JOIN evaluations e ON s.curr_eval_uuid = e.uuid
+		INNER JOIN users u ON s.author_uuid = u.uuid
+		WHERE e.stage = 'finished'
+		  AND e.possible_score > 0 AND e.received_score >= e.possible_score
+		  AND u.username != 'admin'
+		  AND ($1::text[] IS NULL OR s.task_shortid = ANY($1))
+		GROUP BY s.task_shortid
+	`, taskShortIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query solvers per task: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var taskShortID string
+		var count int
+		if err := rows.Scan(&taskShortID, &count); err != nil {
+			return nil, fmt.Errorf("scan solvers per task: %w", err)
+		}
+		counts[taskShortID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate solvers per task: %w", err)
+	}
+	return counts, nil
+}
+
 func (r *pgSubmRepo) ListShallowSubmsJoinEval(ctx context.Context, authorUuid *uuid.UUID) ([]srvc.ShallowSubmJoinEvalDto, error) {
 
 	query := `
@@ -676,7 +798,7 @@ func (r *pgSubmRepo) ListShallowSubmsJoinEval(ctx context.Context, authorUuid *u
 		var interactor *string
 
 		// Score-related fields that may be NULL
-		var receivedScore *int
+		var receivedScore *float64
 		var possibleScore *int
 		var scorebarGreen *int
 		var scorebarRed *int
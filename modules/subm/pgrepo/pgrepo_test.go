@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/programme-lv/backend/common/testutil"
+	"github.com/programme-lv/backend/modules/exec"
 	"github.com/programme-lv/backend/modules/subm/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -200,6 +201,7 @@ func TestEvalRepo_Store_Success(t *testing.T) {
 	repo := NewPgEvalRepo(newSampleDB(t))
 
 	sample := sampleEval()
+	sample.Lane = exec.LaneBulk
 	require.NoError(t, repo.StoreEval(context.Background(), sample))
 
 	stored, err := repo.GetEval(context.Background(), sample.UUID)
@@ -220,6 +222,7 @@ func TestEvalRepo_Store_Success(t *testing.T) {
 	require.Equal(t, len(sample.Tests), len(stored.Tests))
 	require.Equal(t, *sample.Tests[0].CpuMs, *stored.Tests[0].CpuMs)
 	require.Nil(t, stored.Tests[2].CpuMs)
+	require.Equal(t, exec.LaneBulk, stored.Lane)
 }
 
 func TestSubmRepo_ListShallowSubmsJoinEval_WithCompleteScoreInfo(t *testing.T) {
@@ -252,7 +255,7 @@ func TestSubmRepo_ListShallowSubmsJoinEval_WithCompleteScoreInfo(t *testing.T) {
 	require.Equal(t, subm.UUID, result[0].Subm.UUID)
 	require.Equal(t, eval.UUID, result[0].Eval.UUID)
 	require.NotNil(t, result[0].Eval.ScoreInfo)
-	require.Equal(t, 50, result[0].Eval.ScoreInfo.ReceivedScore)
+	require.Equal(t, 50.0, result[0].Eval.ScoreInfo.ReceivedScore)
 	require.Equal(t, 100, result[0].Eval.ScoreInfo.PossibleScore)
 	require.Equal(t, 50, result[0].Eval.ScoreInfo.ScoreBar.Green)
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/programme-lv/backend/modules/subm/domain"
 	tasksrvc "github.com/programme-lv/backend/modules/task/srvc"
 	usersrvc "github.com/programme-lv/backend/modules/user"
+	"github.com/programme-lv/backend/modules/user/auth"
 )
 
 type SubmitSolParams struct {
@@ -36,22 +37,36 @@ func (s *submSrvc) SubmitSol(ctx context.Context, p SubmitSolParams) srvcerror.E
 			return user.UUID == uuid, nil
 		},
 		GetTask:          s.taskSrvc.GetTask,
+		IsAdmin:          auth.IsAdmin,
 		StoreSubm:        s.submRepo.StoreSubm,
 		StoreEval:        s.evalRepo.StoreEval,
 		BcastSubmCreated: s.broadcastSubmCreated,
 		EnqueueExec:      s.enqueueExecAndListen,
+		Lane:             exec.LaneHigh,
 	}
 
 	return submitSolCmd.Handle(ctx, p)
 }
 
+// taskAccessError hides drafts as missing
+// and reports other restricted tasks as closed.
+func taskAccessError(v tasksrvc.Visibility) srvcerror.E {
+	if !v.Viewable() {
+		return tasksrvc.ErrTaskNotFound
+	}
+	return ErrTaskClosedForSubms
+}
+
 type submitSolCmdHandler struct {
 	DoesUserExist    func(ctx context.Context, uuid uuid.UUID) (bool, srvcerror.E)
 	GetTask          func(ctx context.Context, shortId string) (tasksrvc.Task, srvcerror.E)
+	IsAdmin          func(ctx context.Context) bool
 	StoreSubm        func(ctx context.Context, subm *domain.Subm) error
 	StoreEval        func(ctx context.Context, eval domain.Eval) error
 	BcastSubmCreated func(subm domain.Subm)
 	EnqueueExec      func(ctx context.Context, eval domain.Eval, srcCode string, prLangId string) srvcerror.E
+	// job queue lane of the new evaluation
+	Lane exec.Lane
 }
 
 const MaxSubmLengthKB = 64
@@ -90,6 +105,10 @@ func (h submitSolCmdHandler) Handle(ctx context.Context, p SubmitSolParams) srvc
 		log.Error(action, "task_id", p.TaskShortID, "error", getTaskErr)
 		return getTaskErr
 	}
+	if v := t.VisibilityAt(time.Now()); !v.AcceptsSubms() && !h.IsAdmin(ctx) {
+		log.Warn("task closed for submissions", "task_id", p.TaskShortID, "visibility", v)
+		return taskAccessError(v)
+	}
 
 	evalUuid := uuid.New()
 	submEntity := domain.Subm{
@@ -102,6 +121,7 @@ func (h submitSolCmdHandler) Handle(ctx context.Context, p SubmitSolParams) srvc
 		CreatedAt:    time.Now(),
 	}
 	eval := domain.NewEval(evalUuid, submEntity.UUID, t)
+	eval.Lane = h.Lane
 
 	storeEvalErr := h.StoreEval(ctx, eval)
 	if storeEvalErr != nil {
@@ -131,15 +151,45 @@ func (h submitSolCmdHandler) Handle(ctx context.Context, p SubmitSolParams) srvc
 
 func (s *submSrvc) ReEvalSubm(ctx context.Context, submUuid uuid.UUID) srvcerror.E {
 	reevalSubmCmd := reEvalSubmHandler{
-		GetSubm:     s.submRepo.GetSubm,
-		GetTask:     s.taskSrvc.GetTask,
-		StoreEval:   s.evalRepo.StoreEval,
-		AssignEval:  s.submRepo.AssignEval,
-		EnqueueExec: s.enqueueExecAndListen,
+		GetSubm:       s.submRepo.GetSubm,
+		GetTask:       s.taskSrvc.GetTask,
+		StoreEval:     s.evalRepo.StoreEval,
+		AssignEval:    s.submRepo.AssignEval,
+		EnqueueExec:   s.enqueueExecAndListen,
+		IsExecRunning: s.execSrvc.HasPending,
+		CancelExec:    s.execSrvc.Cancel,
+		Lane:          exec.LaneBulk,
 	}
 	return reevalSubmCmd.Handle(ctx, submUuid)
 }
 
+// ReEvalOutdated re-evaluates the submissions to a task that were last
+// evaluated against an older revision of it and returns their count.
+// On error the count tells how many were re-evaluated before it.
+func (s *submSrvc) ReEvalOutdated(ctx context.Context, taskShortID string) (int, srvcerror.E) {
+	log := ctxlog.FromContext(ctx).With("handler", "re eval outdated")
+
+	task, err := s.taskSrvc.GetTask(ctx, taskShortID)
+	if err != nil {
+		return 0, err
+	}
+	submUuids, listErr := s.submRepo.ListOutdatedSubms(ctx, taskShortID, task.Revision)
+	if listErr != nil {
+		log.Error("list outdated subms", "task_short_id", taskShortID, "error", listErr)
+		return 0, srvcerror.InternalServerError()
+	}
+	for i, submUuid := range submUuids {
+		if err := s.ReEvalSubm(ctx, submUuid); err != nil {
+			log.Error("re-evaluate outdated subm", "task_short_id", taskShortID,
+				"subm_uuid", submUuid, "done", i, "total", len(submUuids), "error", err)
+			return i, err
+		}
+	}
+	log.Info("outdated submissions re-evaluated", "task_short_id", taskShortID,
+		"revision", task.Revision, "count", len(submUuids))
+	return len(submUuids), nil
+}
+
 type reEvalSubmHandler struct {
 	// get persisted submission entity by uuid
 	GetSubm func(ctx context.Context, submUuid uuid.UUID) (domain.Subm, error)
@@ -155,6 +205,15 @@ type reEvalSubmHandler struct {
 
 	// enqueue evaluation for corresponding submission execution by tester
 	EnqueueExec func(ctx context.Context, eval domain.Eval, srcCode string, prLangId string) srvcerror.E
+
+	// check whether the superseded evaluation is still executing
+	IsExecRunning func(ctx context.Context, execUuid uuid.UUID) bool
+
+	// stop the superseded evaluation's execution
+	CancelExec func(ctx context.Context, execUuid uuid.UUID) srvcerror.E
+
+	// job queue lane of the new evaluation
+	Lane exec.Lane
 }
 
 func (h reEvalSubmHandler) Handle(ctx context.Context, submUuid uuid.UUID) srvcerror.E {
@@ -177,6 +236,7 @@ func (h reEvalSubmHandler) Handle(ctx context.Context, submUuid uuid.UUID) srvce
 
 	evalUuid := uuid.New()
 	eval := domain.NewEval(evalUuid, subm.UUID, t)
+	eval.Lane = h.Lane
 
 	storeEvalErr := h.StoreEval(ctx, eval)
 	if storeEvalErr != nil {
@@ -192,6 +252,13 @@ func (h reEvalSubmHandler) Handle(ctx context.Context, submUuid uuid.UUID) srvce
 		return srvcerror.InternalServerError()
 	}
 
+	if h.IsExecRunning(ctx, subm.CurrEvalUUID) {
+		if err := h.CancelExec(ctx, subm.CurrEvalUUID); err != nil {
+			// it may have finished in the meantime
+			log.Warn("cancel superseded eval", "eval_uuid", subm.CurrEvalUUID, "error", err)
+		}
+	}
+
 	enqueueExecErr := h.EnqueueExec(ctx, eval, subm.Content, subm.LangShortID)
 	if enqueueExecErr != nil {
 		action := "enqueue execution"
@@ -202,11 +269,12 @@ func (h reEvalSubmHandler) Handle(ctx context.Context, submUuid uuid.UUID) srvce
 	return nil
 }
 
+// enqueueExecAndListen executes the evaluation in its lane.
 func (s *submSrvc) enqueueExecAndListen(ctx context.Context, eval domain.Eval, srcCode string, prLangId string) srvcerror.E {
 	log := ctxlog.FromContext(ctx)
 
 	// Add eval to in-progress map before enqueueing
-	s.inProgrEval[eval.UUID] = eval
+	s.inProgrEval.set(eval)
 
 	err := s.execSrvc.Enqueue(
 		ctx,
@@ -220,27 +288,36 @@ func (s *submSrvc) enqueueExecAndListen(ctx context.Context, eval domain.Eval, s
 			Checker:    eval.Checker,
 			Interactor: eval.Interactor,
 			Groups:     scoringGroups(eval),
+			Lane:       eval.Lane,
 		},
 	)
 	if err != nil {
-		delete(s.inProgrEval, eval.UUID) // remove from map if enqueue fails
+		s.inProgrEval.delete(eval.UUID) // remove from map if enqueue fails
 
 		action := "enqueue execution"
 		log.Error(action, "eval_uuid", eval.UUID, "error", err)
 		return srvcerror.InternalServerError()
 	}
 
-	ch, listenErr := s.execSrvc.Listen(ctx, eval.UUID)
+	return s.listenExec(ctx, eval)
+}
+
+// listenExec applies execution events to the in-progress
+// evaluation in a background goroutine until the execution ends.
+func (s *submSrvc) listenExec(ctx context.Context, eval domain.Eval) srvcerror.E {
+	log := ctxlog.FromContext(ctx)
+
+	// create a new background context for the event processing goroutine
+	processCtx := context.Background()
+	ch, listenErr := s.execSrvc.Listen(processCtx, eval.UUID)
 	if listenErr != nil {
-		delete(s.inProgrEval, eval.UUID) // remove from map if listen fails
+		s.inProgrEval.delete(eval.UUID) // remove from map if listen fails
 
 		action := "subscribe to execution"
 		log.Error(action, "eval_uuid", eval.UUID, "error", listenErr)
 		return srvcerror.InternalServerError()
 	}
 
-	// create a new background context for the event processing goroutine
-	processCtx := context.Background()
 	go func(execEvCh <-chan exec.Event) {
 		for ev := range execEvCh {
 			err := procExecEvCmdHandler{
@@ -263,6 +340,124 @@ func (s *submSrvc) enqueueExecAndListen(ctx context.Context, eval domain.Eval, s
 	return nil
 }
 
+// ResumeEvals continues unfinished evaluations whose executions this
+// replica has taken over, e.g. from a previous backend process or a
+// stopped replica. Such an evaluation is reset and reattached to its
+// execution. Evaluations whose executions other replicas hold are left
+// to them. An evaluation whose execution was lost is enqueued again in
+// the lane it was created for.
+//
+// Call it after the execution service has resumed its pending
+// executions, and again each time it takes executions over.
+func (s *submSrvc) ResumeEvals(ctx context.Context) srvcerror.E {
+	resumeEvalsCmd := resumeEvalsHandler{
+		ListUnfinishedEvals: s.evalRepo.ListUnfinishedEvals,
+		GetSubm:             s.submRepo.GetSubm,
+		StoreEval:           s.evalRepo.StoreEval,
+		IsListening: func(evalUuid uuid.UUID) bool {
+			_, ok := s.inProgrEval.get(evalUuid)
+			return ok
+		},
+		HasExec:          s.execSrvc.HasPending,
+		ListPendingExecs: s.execSrvc.ListPendingIDs,
+		ListenExec: func(ctx context.Context, eval domain.Eval) srvcerror.E {
+			s.inProgrEval.set(eval)
+			return s.listenExec(ctx, eval)
+		},
+		EnqueueExec: s.enqueueExecAndListen,
+	}
+	return resumeEvalsCmd.Handle(ctx)
+}
+
+type resumeEvalsHandler struct {
+	// list persisted evaluations that have not finished
+	ListUnfinishedEvals func(ctx context.Context) ([]domain.Eval, error)
+
+	// get persisted submission entity by uuid
+	GetSubm func(ctx context.Context, submUuid uuid.UUID) (domain.Subm, error)
+
+	// persist evaluation entity
+	StoreEval func(ctx context.Context, eval domain.Eval) error
+
+	// whether this replica already follows the evaluation's execution
+	IsListening func(evalUuid uuid.UUID) bool
+
+	// whether this replica's execution service runs the execution
+	HasExec func(ctx context.Context, execUuid uuid.UUID) bool
+
+	// executions that any replica has enqueued and not finished
+	ListPendingExecs func(ctx context.Context) ([]uuid.UUID, error)
+
+	// reattach to a running execution
+	ListenExec func(ctx context.Context, eval domain.Eval) srvcerror.E
+
+	// enqueue evaluation for corresponding submission execution by tester
+	EnqueueExec func(ctx context.Context, eval domain.Eval, srcCode string, prLangId string) srvcerror.E
+}
+
+func (h resumeEvalsHandler) Handle(ctx context.Context) srvcerror.E {
+	log := ctxlog.FromContext(ctx).With("handler", "resume evals")
+	ctx = ctxlog.WithLogger(ctx, log)
+
+	evals, err := h.ListUnfinishedEvals(ctx)
+	if err != nil {
+		log.Error("list unfinished evals", "error", err)
+		return srvcerror.InternalServerError()
+	}
+	pendingIDs, err := h.ListPendingExecs(ctx)
+	if err != nil {
+		log.Error("list pending executions", "error", err)
+		return srvcerror.InternalServerError()
+	}
+	pending := make(map[uuid.UUID]bool, len(pendingIDs))
+	for _, id := range pendingIDs {
+		pending[id] = true
+	}
+
+	for _, eval := range evals {
+		if h.IsListening(eval.UUID) {
+			continue
+		}
+
+		if h.HasExec(ctx, eval.UUID) {
+			eval.ResetProgress()
+			if err := h.StoreEval(ctx, eval); err != nil {
+				log.Error("store eval", "eval_uuid", eval.UUID, "error", err)
+				continue
+			}
+			if err := h.ListenExec(ctx, eval); err != nil {
+				log.Error("reattach to execution", "eval_uuid", eval.UUID, "error", err)
+			}
+			continue
+		}
+
+		// held by another replica, or being enqueued by the one that
+		// created the evaluation; either hands it over once it stops
+		if pending[eval.UUID] || time.Since(eval.CreatedAt) < exec.PendingLeaseTTL {
+			continue
+		}
+
+		eval.ResetProgress()
+		if err := h.StoreEval(ctx, eval); err != nil {
+			log.Error("store eval", "eval_uuid", eval.UUID, "error", err)
+			continue
+		}
+
+		subm, err := h.GetSubm(ctx, eval.SubmUUID)
+		if err != nil {
+			log.Error("get subm", "subm_uuid", eval.SubmUUID, "error", err)
+			continue
+		}
+		if err := h.EnqueueExec(ctx, eval, subm.Content, subm.LangShortID); err != nil {
+			log.Error("re-enqueue execution", "eval_uuid", eval.UUID, "error", err)
+			continue
+		}
+		log.Info("re-enqueued unfinished eval", "eval_uuid", eval.UUID)
+	}
+
+	return nil
+}
+
 func constructExecEnqueueTests(
 	ctx context.Context,
 	eval domain.Eval,
@@ -320,13 +515,13 @@ type procExecEvCmdHandler struct {
 	StoreEval     func(ctx context.Context, eval domain.Eval) error
 	BcastEvalUpd  func(eval domain.Eval)
 	GetEvalByUuid func(ctx context.Context, uuid uuid.UUID) (domain.Eval, error)
-	InProgrEval   map[uuid.UUID]domain.Eval
+	InProgrEval   *inProgrEvals
 }
 
 func (h procExecEvCmdHandler) Handle(ctx context.Context, p procExecEvParams) error {
 	log := ctxlog.FromContext(ctx)
 
-	latestEval, ok := h.InProgrEval[p.Eval.UUID]
+	latestEval, ok := h.InProgrEval.get(p.Eval.UUID)
 	if !ok {
 		action := "eval not found in in-memory cache"
 		log.Error(action, "eval_uuid", p.Eval.UUID)
@@ -340,6 +535,7 @@ func (h procExecEvCmdHandler) Handle(ctx context.Context, p procExecEvParams) er
 	final = final || p.Event.Type() == exec.InternalServerErrorType
 	final = final || p.Event.Type() == exec.CompilationErrorType
 	final = final || p.Event.Type() == exec.FinishedTestingType
+	final = final || p.Event.Type() == exec.CancelledType
 
 	if final {
 		err := h.StoreEval(ctx, eval)
@@ -347,7 +543,7 @@ func (h procExecEvCmdHandler) Handle(ctx context.Context, p procExecEvParams) er
 			log.Error("store evaluation", "error", err)
 			return srvcerror.InternalServerError()
 		}
-		delete(h.InProgrEval, p.Eval.UUID)
+		h.InProgrEval.delete(p.Eval.UUID)
 	} else {
 		finishedTests := 0
 		for _, test := range eval.Tests {
@@ -356,7 +552,7 @@ func (h procExecEvCmdHandler) Handle(ctx context.Context, p procExecEvParams) er
 			}
 		}
 		log.Debug("test progress", "finished", finishedTests, "total", len(eval.Tests))
-		h.InProgrEval[p.Eval.UUID] = eval
+		h.InProgrEval.set(eval)
 	}
 
 	h.BcastEvalUpd(eval)
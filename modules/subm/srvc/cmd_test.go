@@ -0,0 +1,112 @@
+package srvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/common/srvcerror"
+	"github.com/programme-lv/backend/modules/exec"
+	"github.com/programme-lv/backend/modules/subm/domain"
+	tasksrvc "github.com/programme-lv/backend/modules/task/srvc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitSolHonoursTaskVisibility(t *testing.T) {
+	submit := func(visibility tasksrvc.Visibility, admin bool) srvcerror.E {
+		h := submitSolCmdHandler{
+			DoesUserExist: func(ctx context.Context, uuid uuid.UUID) (bool, srvcerror.E) {
+				return true, nil
+			},
+			GetTask: func(ctx context.Context, shortId string) (tasksrvc.Task, srvcerror.E) {
+				return tasksrvc.Task{ShortId: shortId, Visibility: visibility}, nil
+			},
+			IsAdmin:          func(ctx context.Context) bool { return admin },
+			StoreSubm:        func(ctx context.Context, subm *domain.Subm) error { return nil },
+			StoreEval:        func(ctx context.Context, eval domain.Eval) error { return nil },
+			BcastSubmCreated: func(subm domain.Subm) {},
+			EnqueueExec: func(ctx context.Context, eval domain.Eval, srcCode string, prLangId string) srvcerror.E {
+				require.Equal(t, exec.LaneHigh, eval.Lane)
+				return nil
+			},
+			Lane: exec.LaneHigh,
+		}
+		return h.Handle(t.Context(), SubmitSolParams{
+			UUID: uuid.New(), Submission: "print(1)", ProgrLangID: "python3.13",
+			TaskShortID: "summa", AuthorUUID: uuid.New(),
+		})
+	}
+
+	require.Nil(t, submit(tasksrvc.VisibilityPublished, false))
+	require.Nil(t, submit(tasksrvc.VisibilityHidden, false))
+	require.ErrorIs(t, submit(tasksrvc.VisibilityDraft, false), tasksrvc.ErrTaskNotFound)
+	require.ErrorIs(t, submit(tasksrvc.VisibilityArchived, false), ErrTaskClosedForSubms)
+	require.Nil(t, submit(tasksrvc.VisibilityDraft, true))
+}
+
+func TestResumeEvalsKeepsLane(t *testing.T) {
+	live := domain.Eval{UUID: uuid.New(), Lane: exec.LaneHigh}
+	reeval := domain.Eval{UUID: uuid.New(), Lane: exec.LaneBulk}
+	enqueued := map[uuid.UUID]exec.Lane{}
+	h := resumeEvalsHandler{
+		ListUnfinishedEvals: func(ctx context.Context) ([]domain.Eval, error) {
+			return []domain.Eval{live, reeval}, nil
+		},
+		GetSubm: func(ctx context.Context, submUuid uuid.UUID) (domain.Subm, error) {
+			return domain.Subm{}, nil
+		},
+		StoreEval:   func(ctx context.Context, eval domain.Eval) error { return nil },
+		IsListening: func(evalUuid uuid.UUID) bool { return false },
+		HasExec:     func(ctx context.Context, execUuid uuid.UUID) bool { return false },
+		ListPendingExecs: func(ctx context.Context) ([]uuid.UUID, error) {
+			return nil, nil
+		},
+		EnqueueExec: func(ctx context.Context, eval domain.Eval, srcCode string, prLangId string) srvcerror.E {
+			enqueued[eval.UUID] = eval.Lane
+			return nil
+		},
+	}
+	require.Nil(t, h.Handle(t.Context()))
+	require.Equal(t, map[uuid.UUID]exec.Lane{live.UUID: exec.LaneHigh, reeval.UUID: exec.LaneBulk}, enqueued)
+}
+
+func TestResumeEvalsLeavesOtherReplicasEvals(t *testing.T) {
+	listening := domain.Eval{UUID: uuid.New()}
+	taken := domain.Eval{UUID: uuid.New()}
+	remote := domain.Eval{UUID: uuid.New()}
+	creating := domain.Eval{UUID: uuid.New(), CreatedAt: time.Now()}
+	lost := domain.Eval{UUID: uuid.New()}
+	var stored, listened, enqueued []uuid.UUID
+	h := resumeEvalsHandler{
+		ListUnfinishedEvals: func(ctx context.Context) ([]domain.Eval, error) {
+			return []domain.Eval{listening, taken, remote, creating, lost}, nil
+		},
+		GetSubm: func(ctx context.Context, submUuid uuid.UUID) (domain.Subm, error) {
+			return domain.Subm{}, nil
+		},
+		StoreEval: func(ctx context.Context, eval domain.Eval) error {
+			stored = append(stored, eval.UUID)
+			return nil
+		},
+		IsListening: func(evalUuid uuid.UUID) bool { return evalUuid == listening.UUID },
+		HasExec: func(ctx context.Context, execUuid uuid.UUID) bool {
+			return execUuid == listening.UUID || execUuid == taken.UUID
+		},
+		ListPendingExecs: func(ctx context.Context) ([]uuid.UUID, error) {
+			return []uuid.UUID{listening.UUID, taken.UUID, remote.UUID}, nil
+		},
+		ListenExec: func(ctx context.Context, eval domain.Eval) srvcerror.E {
+			listened = append(listened, eval.UUID)
+			return nil
+		},
+		EnqueueExec: func(ctx context.Context, eval domain.Eval, srcCode string, prLangId string) srvcerror.E {
+			enqueued = append(enqueued, eval.UUID)
+			return nil
+		},
+	}
+	require.Nil(t, h.Handle(t.Context()))
+	require.Equal(t, []uuid.UUID{taken.UUID, lost.UUID}, stored)
+	require.Equal(t, []uuid.UUID{taken.UUID}, listened)
+	require.Equal(t, []uuid.UUID{lost.UUID}, enqueued)
+}
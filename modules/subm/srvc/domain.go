@@ -11,6 +11,8 @@ import (
 func applyExecEventToEval(eval domain.Eval, event exec.Event) domain.Eval {
 	switch u := event.(type) {
 	case exec.ReceivedSubmission:
+		// a re-published job starts over
+		eval.ResetProgress()
 	case exec.StartedCompiling:
 		eval.Stage = domain.EvalStageCompiling
 	case exec.FinishedTesting:
@@ -21,6 +23,9 @@ func applyExecEventToEval(eval domain.Eval, event exec.Event) domain.Eval {
 			Type:    domain.ErrorTypeInternal,
 			Message: u.ErrorMsg,
 		}
+	case exec.Cancelled:
+		eval.Stage = domain.EvalStageFinished
+		eval.Error = &domain.EvalError{Type: domain.ErrorTypeCancelled}
 	case exec.CompilationError:
 		eval.Stage = domain.EvalStageFinished
 		eval.Error = &domain.EvalError{
@@ -42,16 +47,27 @@ func applyExecEventToEval(eval domain.Eval, event exec.Event) domain.Eval {
 		}
 		eval.Tests[u.TestID-1].Finished = true
 		if u.Subm != nil {
+			// the interactor reports in place of the checker; once it rejects
+			// the dialogue, the submission usually dies on a closed pipe
+			rejected := eval.Interactor != nil && u.Checker != nil &&
+				exec.CheckerScore(u.Checker.ExitCode, u.Checker.StdOut) == 0
 			if u.Subm.IsOomKilled || u.Subm.MemKiB >= int64(eval.MemLimKiB) {
 				eval.Tests[u.TestID-1].Mle = true
+			} else if rejected && u.Subm.CpuMs < int64(eval.CpuLimMs) {
+				eval.Tests[u.TestID-1].Wa = true
 			} else if u.Subm.ExitCode != 0 || u.Subm.StdErr != "" || u.Subm.Signal != nil {
 				eval.Tests[u.TestID-1].Re = true
 			} else if u.Subm.CpuMs >= int64(eval.CpuLimMs) {
 				eval.Tests[u.TestID-1].Tle = true
 			} else if u.Checker != nil {
-				if u.Checker.ExitCode == 0 {
+				score := exec.CheckerScore(u.Checker.ExitCode, u.Checker.StdOut)
+				eval.Tests[u.TestID-1].Score = &score
+				switch {
+				case score >= 1:
 					eval.Tests[u.TestID-1].Ac = true
-				} else {
+				case score > 0:
+					eval.Tests[u.TestID-1].Pc = true
+				default:
 					eval.Tests[u.TestID-1].Wa = true
 				}
 			}
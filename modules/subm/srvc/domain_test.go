@@ -0,0 +1,64 @@
+package srvc
+
+import (
+	"testing"
+
+	"github.com/programme-lv/backend/modules/exec"
+	"github.com/programme-lv/backend/modules/subm/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyInteractorRunData(t *testing.T) {
+	interactor := "interactor"
+	sigpipe := int64(13)
+	tests := []struct {
+		name       string
+		subm       exec.RunData
+		interactor exec.RunData
+		want       domain.Test
+	}{
+		{
+			name:       "accepted",
+			subm:       exec.RunData{CpuMs: 10},
+			interactor: exec.RunData{ExitCode: 0},
+			want:       domain.Test{Ac: true},
+		},
+		{
+			name:       "rejected dialogue outweighs broken pipe",
+			subm:       exec.RunData{CpuMs: 10, Signal: &sigpipe},
+			interactor: exec.RunData{ExitCode: 1},
+			want:       domain.Test{Wa: true},
+		},
+		{
+			name:       "time limit outweighs rejection",
+			subm:       exec.RunData{CpuMs: 1000},
+			interactor: exec.RunData{ExitCode: 1},
+			want:       domain.Test{Tle: true},
+		},
+		{
+			name:       "runtime error with accepted dialogue",
+			subm:       exec.RunData{CpuMs: 10, ExitCode: 1},
+			interactor: exec.RunData{ExitCode: 0},
+			want:       domain.Test{Re: true},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			eval := domain.Eval{
+				Interactor: &interactor,
+				CpuLimMs:   1000,
+				MemLimKiB:  1024,
+				Tests:      []domain.Test{{}},
+			}
+			eval = applyExecEventToEval(eval, exec.FinishedTest{
+				TestID: 1, Subm: &tc.subm, Checker: &tc.interactor,
+			})
+			got := eval.Tests[0]
+			require.True(t, got.Finished)
+			require.Equal(t, tc.want.Ac, got.Ac)
+			require.Equal(t, tc.want.Wa, got.Wa)
+			require.Equal(t, tc.want.Tle, got.Tle)
+			require.Equal(t, tc.want.Re, got.Re)
+		})
+	}
+}
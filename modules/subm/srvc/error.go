@@ -22,9 +22,29 @@ var ErrInvalidProgLang = srvcerror.New(
 	"Norādīta programmēšanas valoda nav iespējota.",
 ).SetHttpStatusCode(http.StatusBadRequest)
 
+var ErrTaskClosedForSubms = srvcerror.New(
+	"task_closed_for_submissions",
+	"Šis uzdevums vairs nepieņem iesūtījumus.",
+).SetHttpStatusCode(http.StatusForbidden)
+
 var ErrSubmissionNotFound = srvcerror.New(
 	"submission_not_found",
 	"Atbilstošais iesūtījums netika atrasts",
 ).SetHttpStatusCode(http.StatusNotFound)
 
+var ErrNoCustomInputs = srvcerror.New(
+	"no_custom_inputs",
+	"Norādiet vismaz vienu ievaddatu piemēru.",
+).SetHttpStatusCode(http.StatusBadRequest)
+
+var ErrTooManyCustomInputs = srvcerror.New(
+	"too_many_custom_inputs",
+	fmt.Sprintf("Vienlaikus var izpildīt ne vairāk kā %d ievaddatu piemērus.", MaxCustomRunInputs),
+).SetHttpStatusCode(http.StatusBadRequest)
+
+var ErrCustomInputTooLong = srvcerror.New(
+	"custom_input_too_long",
+	fmt.Sprintf("Maksimālais ievaddatu garums ir %d KB.", MaxCustomRunInputKB),
+).SetHttpStatusCode(http.StatusBadRequest)
+
 var ErrInternal = srvcerror.ErrInternal
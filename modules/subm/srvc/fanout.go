@@ -0,0 +1,208 @@
+package srvc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/programme-lv/backend/common/ctxlog"
+	"github.com/programme-lv/backend/modules/subm/domain"
+)
+
+// Every backend replica publishes its submission list updates to these
+// subjects and subscribes to them outside any queue group, so the SSE
+// clients and caches of all replicas see every update.
+const (
+	SubmCreatedSubject = "subm.created"
+	EvalUpdatedSubject = "subm.eval_updated"
+	// replicas announce that they are alive, see replicaHeartbeat
+	ReplicaAliveSubject = "subm.replica_alive"
+)
+
+// Every replica publishes to ReplicaAliveSubject each replicaHeartbeat.
+// The evaluations of a replica that stays silent for replicaSilence
+// are dropped from remoteEvals; a replica that takes them over
+// reports them again.
+const (
+	replicaHeartbeat = 10 * time.Second
+	replicaSilence   = 3 * replicaHeartbeat
+)
+
+// originHeader carries the publishing replica's ID so that
+// a replica skips updates it has already applied locally.
+const originHeader = "Proglv-Origin"
+
+type SubmSrvcOption func(*submSrvc)
+
+// WithNatsFanout shares submission list updates with other backend
+// replicas over NATS. Call StartFanout to receive theirs.
+func WithNatsFanout(conn *nats.Conn) SubmSrvcOption {
+	return func(s *submSrvc) {
+		s.natsConn = conn
+		s.publishUpdate = conn.PublishMsg
+	}
+}
+
+// StartFanout subscribes to the updates of other replicas until ctx ends.
+func (s *submSrvc) StartFanout(ctx context.Context) error {
+	if s.natsConn == nil {
+		return fmt.Errorf("nats fanout not configured")
+	}
+	log := ctxlog.FromContext(ctx).With("module", "subm", "cmd", "fanout")
+
+	subs := make([]*nats.Subscription, 0, 3)
+	for _, subject := range []string{SubmCreatedSubject, EvalUpdatedSubject, ReplicaAliveSubject} {
+		sub, err := s.natsConn.Subscribe(subject, func(msg *nats.Msg) {
+			s.handleFanoutMsg(log, msg)
+		})
+		if err != nil {
+			for _, sub := range subs {
+				_ = sub.Unsubscribe()
+			}
+			return fmt.Errorf("subscribe to %s: %w", subject, err)
+		}
+		subs = append(subs, sub)
+	}
+	go func() {
+		ticker := time.NewTicker(replicaHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				for _, sub := range subs {
+					_ = sub.Unsubscribe()
+				}
+				return
+			case now := <-ticker.C:
+				s.publishFanout(ReplicaAliveSubject, struct{}{})
+				if n := s.remoteEvals.dropSilent(now.Add(-replicaSilence)); n > 0 {
+					log.Warn("dropped evaluations of silent replicas", "evals", n)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *submSrvc) handleFanoutMsg(log *slog.Logger, msg *nats.Msg) {
+	origin := msg.Header.Get(originHeader)
+	if origin == s.replicaID {
+		return
+	}
+	now := time.Now()
+	s.remoteEvals.seen(origin, now)
+	switch msg.Subject {
+	case SubmCreatedSubject:
+		var subm domain.Subm
+		if err := json.Unmarshal(msg.Data, &subm); err != nil {
+			log.Warn("unmarshal created submission", "error", err)
+			return
+		}
+		s.feed.publish(SubmUpdate{SubmCreated: &subm})
+	case EvalUpdatedSubject:
+		var eval domain.Eval
+		if err := json.Unmarshal(msg.Data, &eval); err != nil {
+			log.Warn("unmarshal evaluation update", "error", err)
+			return
+		}
+		if eval.Stage == domain.EvalStageFinished {
+			s.remoteEvals.delete(eval.UUID)
+		} else {
+			s.remoteEvals.set(origin, eval)
+		}
+		s.feed.publish(SubmUpdate{EvalUpdate: &eval})
+	}
+}
+
+// publishFanout shares a local update with the other replicas.
+// Failures only cost remote clients a live update, so they are logged.
+func (s *submSrvc) publishFanout(subject string, v any) {
+	if s.publishUpdate == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.Default().Error("marshal fanout update", "subject", subject, "error", err)
+		return
+	}
+	msg := nats.NewMsg(subject)
+	msg.Header.Set(originHeader, s.replicaID)
+	msg.Data = data
+	if err := s.publishUpdate(msg); err != nil {
+		slog.Default().Warn("publish fanout update", "subject", subject, "error", err)
+	}
+}
+
+// remoteEvalSet holds the evaluations in progress on other replicas
+// and when each replica was last heard from.
+type remoteEvalSet struct {
+	mu       sync.Mutex
+	evals    map[uuid.UUID]remoteEval
+	lastSeen map[string]time.Time
+}
+
+type remoteEval struct {
+	origin string
+	eval   domain.Eval
+}
+
+func newRemoteEvalSet() *remoteEvalSet {
+	return &remoteEvalSet{
+		evals:    make(map[uuid.UUID]remoteEval),
+		lastSeen: make(map[string]time.Time),
+	}
+}
+
+func (r *remoteEvalSet) seen(origin string, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastSeen[origin] = now
+}
+
+func (r *remoteEvalSet) set(origin string, eval domain.Eval) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evals[eval.UUID] = remoteEval{origin: origin, eval: eval}
+}
+
+func (r *remoteEvalSet) delete(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.evals, id)
+}
+
+func (r *remoteEvalSet) list() []domain.Eval {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]domain.Eval, 0, len(r.evals))
+	for _, e := range r.evals {
+		res = append(res, e.eval)
+	}
+	return res
+}
+
+// dropSilent forgets the replicas last heard from before cutoff
+// together with their evaluations and returns how many were dropped.
+func (r *remoteEvalSet) dropSilent(cutoff time.Time) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dropped := 0
+	for origin, seen := range r.lastSeen {
+		if !seen.Before(cutoff) {
+			continue
+		}
+		delete(r.lastSeen, origin)
+		for id, e := range r.evals {
+			if e.origin == origin {
+				delete(r.evals, id)
+				dropped++
+			}
+		}
+	}
+	return dropped
+}
@@ -0,0 +1,77 @@
+package srvc
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/programme-lv/backend/modules/subm/domain"
+	"github.com/stretchr/testify/require"
+)
+
+// connectReplicas returns two services that exchange updates through
+// an in-memory stand-in for NATS.
+func connectReplicas(t *testing.T) (a, b *submSrvc) {
+	a = NewSubmSrvc(nil, nil, nil, nil, nil)
+	b = NewSubmSrvc(nil, nil, nil, nil, nil)
+	deliver := func(msg *nats.Msg) error {
+		// NATS delivers to every subscriber, including the publisher
+		a.handleFanoutMsg(slog.Default(), msg)
+		b.handleFanoutMsg(slog.Default(), msg)
+		return nil
+	}
+	a.publishUpdate, b.publishUpdate = deliver, deliver
+	return a, b
+}
+
+func TestFanoutDeliversUpdatesToOtherReplica(t *testing.T) {
+	a, b := connectReplicas(t)
+	updates, err := b.SubscribeUpdates(t.Context(), "")
+	require.Nil(t, err)
+
+	subm := domain.Subm{UUID: uuid.New(), Content: "print(1)"}
+	a.broadcastSubmCreated(subm)
+	u := <-updates
+	require.Equal(t, subm.UUID, u.SubmCreated.UUID)
+	require.Equal(t, 1, u.Seq)
+
+	checker := "checker.cpp"
+	eval := domain.Eval{UUID: uuid.New(), Stage: domain.EvalStageTesting, Checker: &checker}
+	a.broadcastEvalUpdate(eval)
+	u = <-updates
+	require.Equal(t, eval.UUID, u.EvalUpdate.UUID)
+	require.Nil(t, u.EvalUpdate.Checker)
+
+	// a late subscriber on b learns about the remote evaluation
+	snapshot, err := b.SubscribeUpdates(t.Context(), "")
+	require.Nil(t, err)
+	require.Equal(t, eval.UUID, (<-snapshot).EvalUpdate.UUID)
+
+	eval.Stage = domain.EvalStageFinished
+	a.broadcastEvalUpdate(eval)
+	require.Empty(t, b.remoteEvals.list())
+}
+
+func TestFanoutIgnoresOwnUpdates(t *testing.T) {
+	a, _ := connectReplicas(t)
+	a.broadcastSubmCreated(domain.Subm{UUID: uuid.New()})
+	a.broadcastEvalUpdate(domain.Eval{UUID: uuid.New(), Stage: domain.EvalStageTesting})
+
+	a.feed.mu.Lock()
+	defer a.feed.mu.Unlock()
+	require.Equal(t, 2, a.feed.lastSeq)
+	require.Empty(t, a.remoteEvals.list())
+}
+
+func TestFanoutDropsEvalsOfSilentReplica(t *testing.T) {
+	a, b := connectReplicas(t)
+	eval := domain.Eval{UUID: uuid.New(), Stage: domain.EvalStageTesting}
+	a.broadcastEvalUpdate(eval)
+	require.Len(t, b.remoteEvals.list(), 1)
+
+	require.Zero(t, b.remoteEvals.dropSilent(time.Now().Add(-replicaSilence)))
+	require.Equal(t, 1, b.remoteEvals.dropSilent(time.Now().Add(time.Second)))
+	require.Empty(t, b.remoteEvals.list())
+}
@@ -2,63 +2,182 @@ package srvc
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/programme-lv/backend/common/srvcerror"
 	"github.com/programme-lv/backend/modules/subm/domain"
 )
 
-func (s *submSrvc) SubscribeNewSubms(ctx context.Context) (<-chan domain.Subm, srvcerror.E) {
-	ch := make(chan domain.Subm, 10)
-	s.newSubmChListenerLock.Lock()
-	s.newSubmListeners[ch] = struct{}{}
-	s.newSubmChListenerLock.Unlock()
-	go func() {
-		<-ctx.Done()
-		s.newSubmChListenerLock.Lock()
-		delete(s.newSubmListeners, ch)
-		s.newSubmChListenerLock.Unlock()
-		close(ch)
-	}()
-	return ch, nil
+// feedCapacity is how many recent updates a reconnecting
+// subscriber can replay.
+const feedCapacity = 1000
+
+// SubmUpdate is one entry of the submission list feed: either a
+// created submission or the latest state of an evaluation.
+// Seq numbers are assigned per backend process.
+type SubmUpdate struct {
+	Seq int
+	// EventID combines the feed's replica ID with Seq, so that
+	// a client reconnecting to another replica is recognized
+	EventID     string
+	SubmCreated *domain.Subm
+	EvalUpdate  *domain.Eval
 }
 
-func (s *submSrvc) SubscribeEvalUpds(ctx context.Context) (<-chan domain.Eval, srvcerror.E) {
-	ch := make(chan domain.Eval, 10)
-	s.newEvalUpdListenerLock.Lock()
-	s.newEvalUpdListeners[ch] = struct{}{}
-	s.newEvalUpdListenerLock.Unlock()
-	go func() {
-		<-ctx.Done()
-		s.newEvalUpdListenerLock.Lock()
-		delete(s.newEvalUpdListeners, ch)
-		s.newEvalUpdListenerLock.Unlock()
-		close(ch)
-	}()
-	return ch, nil
+// updateFeed numbers submission list updates and keeps the most
+// recent ones for replay.
+type updateFeed struct {
+	id      string // replica ID, the prefix of event IDs
+	mu      sync.Mutex
+	recent  []SubmUpdate // ascending Seq, at most feedCapacity
+	lastSeq int
+	// changed is closed and replaced on every publish
+	changed chan struct{}
 }
 
-func (s *submSrvc) broadcastEvalUpdate(eval domain.Eval) {
-	s.newEvalUpdListenerLock.Lock()
-	defer s.newEvalUpdListenerLock.Unlock()
-	for ch := range s.newEvalUpdListeners {
-		select {
-		case ch <- eval:
-		default:
-			<-ch
-			ch <- eval
-		}
+func newUpdateFeed(id string) *updateFeed {
+	return &updateFeed{id: id, changed: make(chan struct{})}
+}
+
+func (f *updateFeed) eventID(seq int) string {
+	return fmt.Sprintf("%s.%d", f.id, seq)
+}
+
+// seqOf returns the sequence number of an event ID of this feed,
+// or 0 for an empty, malformed or another replica's event ID.
+func (f *updateFeed) seqOf(eventID string) int {
+	id, seq, ok := strings.Cut(eventID, ".")
+	if !ok || id != f.id {
+		return 0
+	}
+	n, err := strconv.Atoi(seq)
+	if err != nil || n < 0 {
+		return 0
 	}
+	return n
 }
 
-func (s *submSrvc) broadcastSubmCreated(subm domain.Subm) {
-	s.newSubmChListenerLock.Lock()
-	defer s.newSubmChListenerLock.Unlock()
-	for ch := range s.newSubmListeners {
+func (f *updateFeed) publish(u SubmUpdate) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastSeq++
+	u.Seq = f.lastSeq
+	f.recent = append(f.recent, u)
+	if len(f.recent) > feedCapacity {
+		f.recent = append([]SubmUpdate(nil), f.recent[len(f.recent)-feedCapacity:]...)
+	}
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// since returns the updates after seq. It reports false when some
+// of them are no longer kept or seq belongs to another process.
+// Callers hold f.mu.
+func (f *updateFeed) since(seq int) ([]SubmUpdate, bool) {
+	if seq > f.lastSeq {
+		return nil, false
+	}
+	if seq == f.lastSeq {
+		return nil, true
+	}
+	oldest := f.lastSeq - len(f.recent) + 1
+	if seq < oldest-1 {
+		return nil, false
+	}
+	return f.recent[seq-oldest+1:], true
+}
+
+// subscribe streams updates after afterSeq, or a snapshot followed by
+// the live tail when those updates cannot be replayed. A subscriber
+// that falls too far behind receives a fresh snapshot as well.
+func (f *updateFeed) subscribe(ctx context.Context, afterSeq int, snapshot func() []SubmUpdate) <-chan SubmUpdate {
+	out := make(chan SubmUpdate)
+	send := func(u SubmUpdate) bool {
+		u.EventID = f.eventID(u.Seq)
 		select {
-		case ch <- subm:
-		default:
-			<-ch
-			ch <- subm
+		case out <- u:
+			return true
+		case <-ctx.Done():
+			return false
 		}
 	}
+	next := afterSeq
+	// a new subscriber follows the updates published after it subscribed
+	fresh := afterSeq <= 0
+	if fresh {
+		f.mu.Lock()
+		next = f.lastSeq
+		f.mu.Unlock()
+	}
+	go func() {
+		defer close(out)
+		for {
+			f.mu.Lock()
+			pending, ok := f.since(next)
+			if !ok {
+				next = f.lastSeq
+			}
+			changed := f.changed
+			f.mu.Unlock()
+
+			if fresh || !ok {
+				fresh = false
+				for _, u := range snapshot() {
+					u.Seq = next
+					if !send(u) {
+						return
+					}
+				}
+			}
+			for _, u := range pending {
+				if !send(u) {
+					return
+				}
+				next = u.Seq
+			}
+			if len(pending) > 0 {
+				continue
+			}
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// SubscribeUpdates streams submission list updates after the one with
+// lastEventID until ctx ends. Any number of subscribers may follow the
+// feed. A new subscriber (empty lastEventID), one that last followed
+// another replica or one whose updates were dropped first receives
+// the state of every evaluation in progress.
+func (s *submSrvc) SubscribeUpdates(ctx context.Context, lastEventID string) (<-chan SubmUpdate, srvcerror.E) {
+	return s.feed.subscribe(ctx, s.feed.seqOf(lastEventID), s.inProgrEvalSnapshot), nil
+}
+
+func (s *submSrvc) inProgrEvalSnapshot() []SubmUpdate {
+	evals := append(s.inProgrEval.list(), s.remoteEvals.list()...)
+	res := make([]SubmUpdate, len(evals))
+	for i := range evals {
+		res[i] = SubmUpdate{EvalUpdate: &evals[i]}
+	}
+	return res
+}
+
+func (s *submSrvc) broadcastEvalUpdate(eval domain.Eval) {
+	s.feed.publish(SubmUpdate{EvalUpdate: &eval})
+	// other replicas only render the update; programs can be 1 MiB each
+	remote := eval
+	remote.Checker, remote.Interactor = nil, nil
+	s.publishFanout(EvalUpdatedSubject, remote)
+}
+
+func (s *submSrvc) broadcastSubmCreated(subm domain.Subm) {
+	s.feed.publish(SubmUpdate{SubmCreated: &subm})
+	s.publishFanout(SubmCreatedSubject, subm)
 }
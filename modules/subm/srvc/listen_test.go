@@ -0,0 +1,74 @@
+package srvc
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/modules/subm/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateFeedReplaysAfterLastEventID(t *testing.T) {
+	feed := newUpdateFeed("replica")
+	for range 3 {
+		feed.publish(SubmUpdate{SubmCreated: &domain.Subm{UUID: uuid.New()}})
+	}
+	noSnapshot := func() []SubmUpdate {
+		t.Fatal("unexpected snapshot")
+		return nil
+	}
+
+	first := feed.subscribe(t.Context(), 1, noSnapshot)
+	second := feed.subscribe(t.Context(), 1, noSnapshot)
+	require.Equal(t, 2, (<-first).Seq)
+	require.Equal(t, 2, (<-second).Seq)
+	require.Equal(t, 3, (<-first).Seq)
+	require.Equal(t, 3, (<-second).Seq)
+
+	feed.publish(SubmUpdate{EvalUpdate: &domain.Eval{}})
+	require.Equal(t, 4, (<-first).Seq)
+	require.Equal(t, 4, (<-second).Seq)
+}
+
+func TestUpdateFeedSendsSnapshotWhenReplayImpossible(t *testing.T) {
+	feed := newUpdateFeed("replica")
+	for range feedCapacity + 5 {
+		feed.publish(SubmUpdate{EvalUpdate: &domain.Eval{}})
+	}
+	evalUuid := uuid.New()
+	snapshot := func() []SubmUpdate {
+		return []SubmUpdate{{EvalUpdate: &domain.Eval{UUID: evalUuid}}}
+	}
+
+	for _, afterSeq := range []int{0, 2, feedCapacity + 100} {
+		ch := feed.subscribe(t.Context(), afterSeq, snapshot)
+		u := <-ch
+		require.Equal(t, feedCapacity+5, u.Seq)
+		require.Equal(t, evalUuid, u.EvalUpdate.UUID)
+	}
+
+	ch := feed.subscribe(t.Context(), 10, snapshot)
+	require.Equal(t, 11, (<-ch).Seq)
+}
+
+func TestSubscribeUpdatesSendsSnapshotAfterOtherReplicasEventID(t *testing.T) {
+	s := NewSubmSrvc(nil, nil, nil, nil, nil)
+	for range 3 {
+		s.feed.publish(SubmUpdate{SubmCreated: &domain.Subm{UUID: uuid.New()}})
+	}
+	eval := domain.Eval{UUID: uuid.New(), Stage: domain.EvalStageTesting}
+	s.inProgrEval.set(eval)
+
+	// the other replica's sequence is behind this one's
+	ch, err := s.SubscribeUpdates(t.Context(), "other-replica.1")
+	require.Nil(t, err)
+	u := <-ch
+	require.Equal(t, eval.UUID, u.EvalUpdate.UUID)
+	require.Equal(t, s.feed.eventID(3), u.EventID)
+
+	ch, err = s.SubscribeUpdates(t.Context(), s.feed.eventID(1))
+	require.Nil(t, err)
+	u = <-ch
+	require.NotNil(t, u.SubmCreated)
+	require.Equal(t, s.feed.eventID(2), u.EventID)
+}
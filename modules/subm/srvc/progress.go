@@ -0,0 +1,49 @@
+package srvc
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/modules/subm/domain"
+)
+
+// inProgrEvals holds the latest state of evaluations whose execution
+// has not finished yet. Execution event goroutines update it
+// concurrently with readers, so access goes through the mutex.
+type inProgrEvals struct {
+	mu    sync.Mutex
+	evals map[uuid.UUID]domain.Eval
+}
+
+func newInProgrEvals() *inProgrEvals {
+	return &inProgrEvals{evals: make(map[uuid.UUID]domain.Eval)}
+}
+
+func (p *inProgrEvals) get(id uuid.UUID) (domain.Eval, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	eval, ok := p.evals[id]
+	return eval, ok
+}
+
+func (p *inProgrEvals) list() []domain.Eval {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	res := make([]domain.Eval, 0, len(p.evals))
+	for _, eval := range p.evals {
+		res = append(res, eval)
+	}
+	return res
+}
+
+func (p *inProgrEvals) set(eval domain.Eval) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.evals[eval.UUID] = eval
+}
+
+func (p *inProgrEvals) delete(id uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.evals, id)
+}
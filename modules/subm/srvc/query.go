@@ -159,7 +159,7 @@ func (s *submSrvc) redactSubmContent(ctx context.Context, subm domain.Subm) (dom
 		}
 		userScore, ok := userMaxScores[subm.TaskShortID]
 		if ok {
-			userHasSolvedTheTask = userScore.Received >= userScore.Possible
+			userHasSolvedTheTask = userScore.Received >= float64(userScore.Possible)
 		}
 	}
 
@@ -238,7 +238,7 @@ func (s *submSrvc) ListSubms(ctx context.Context, filter ListSubmsParams) ([]dom
 }
 
 func (s *submSrvc) GetEval(ctx context.Context, uuid uuid.UUID) (domain.Eval, srvcerror.E) {
-	if eval, ok := s.inProgrEval[uuid]; ok {
+	if eval, ok := s.inProgrEval.get(uuid); ok {
 		return eval, nil
 	}
 	eval, err := s.evalRepo.GetEval(ctx, uuid)
@@ -0,0 +1,118 @@
+package srvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/common/ctxlog"
+	"github.com/programme-lv/backend/common/srvcerror"
+	"github.com/programme-lv/backend/modules/exec"
+	"github.com/programme-lv/backend/modules/plang"
+	tasksrvc "github.com/programme-lv/backend/modules/task/srvc"
+	"github.com/programme-lv/backend/modules/user/auth"
+)
+
+const (
+	MaxCustomRunInputs    = 10
+	MaxCustomRunInputKB   = 256
+	defaultCustomRunCpuMs = 1000
+	defaultCustomRunMemKB = 256 * 1024
+)
+
+type RunCustomParams struct {
+	SrcCode     string
+	ProgrLangID string
+	Stdins      []string
+	// optional; applies the task's CPU and memory limits
+	TaskShortID *string
+}
+
+// RunCustom executes code against user-provided inputs without
+// grading. Nothing is stored in the submission repositories.
+func (s *submSrvc) RunCustom(ctx context.Context, p RunCustomParams) (<-chan exec.Event, srvcerror.E) {
+	runCustomCmd := runCustomHandler{
+		GetTask: s.taskSrvc.GetTask,
+		IsAdmin: auth.IsAdmin,
+		Enqueue: s.execSrvc.Enqueue,
+		Listen:  s.execSrvc.Listen,
+	}
+	return runCustomCmd.Handle(ctx, p)
+}
+
+type runCustomHandler struct {
+	GetTask func(ctx context.Context, shortId string) (tasksrvc.Task, srvcerror.E)
+	IsAdmin func(ctx context.Context) bool
+	Enqueue func(ctx context.Context, execUuid uuid.UUID, srcCode string, prLangId string, tests []exec.TestFile, params exec.TestingParams) srvcerror.E
+	Listen  func(ctx context.Context, execUuid uuid.UUID) (<-chan exec.Event, srvcerror.E)
+}
+
+func (h runCustomHandler) Handle(ctx context.Context, p RunCustomParams) (<-chan exec.Event, srvcerror.E) {
+	log := ctxlog.FromContext(ctx).With("handler", "run custom")
+
+	if len(p.SrcCode) > MaxSubmLengthKB*1024 {
+		log.Warn("submission too long", "size", len(p.SrcCode))
+		return nil, ErrSubmTooLong
+	}
+	if len(p.Stdins) == 0 {
+		log.Warn("no custom inputs")
+		return nil, ErrNoCustomInputs
+	}
+	if len(p.Stdins) > MaxCustomRunInputs {
+		log.Warn("too many custom inputs", "count", len(p.Stdins))
+		return nil, ErrTooManyCustomInputs
+	}
+	for _, stdin := range p.Stdins {
+		if len(stdin) > MaxCustomRunInputKB*1024 {
+			log.Warn("custom input too long", "size", len(stdin))
+			return nil, ErrCustomInputTooLong
+		}
+	}
+
+	l, getProgrLangErr := plang.GetProgrLangById(p.ProgrLangID)
+	if getProgrLangErr != nil {
+		log.Warn("get progr lang", "prog_lang_id", p.ProgrLangID, "error", getProgrLangErr)
+		return nil, getProgrLangErr
+	}
+
+	params := exec.TestingParams{
+		CpuMs:  defaultCustomRunCpuMs,
+		MemKiB: defaultCustomRunMemKB,
+		Lane:   exec.LaneHigh,
+	}
+	if p.TaskShortID != nil {
+		t, getTaskErr := h.GetTask(ctx, *p.TaskShortID)
+		if getTaskErr != nil {
+			log.Warn("get task", "task_id", *p.TaskShortID, "error", getTaskErr)
+			return nil, getTaskErr
+		}
+		if v := t.VisibilityAt(time.Now()); !v.AcceptsSubms() && !h.IsAdmin(ctx) {
+			log.Warn("task closed for runs", "task_id", *p.TaskShortID, "visibility", v)
+			return nil, taskAccessError(v)
+		}
+		params.CpuMs = t.CpuMillis()
+		params.MemKiB = t.MemoryKiB()
+	}
+
+	noAnswer := ""
+	tests := make([]exec.TestFile, len(p.Stdins))
+	for i := range p.Stdins {
+		tests[i] = exec.TestFile{
+			InContent:  &p.Stdins[i],
+			AnsContent: &noAnswer,
+		}
+	}
+
+	execUuid := uuid.New()
+	if err := h.Enqueue(ctx, execUuid, p.SrcCode, l.ID, tests, params); err != nil {
+		log.Error("enqueue execution", "exec_uuid", execUuid, "error", err)
+		return nil, err
+	}
+
+	ch, err := h.Listen(ctx, execUuid)
+	if err != nil {
+		log.Error("listen to execution", "exec_uuid", execUuid, "error", err)
+		return nil, srvcerror.InternalServerError()
+	}
+	return ch, nil
+}
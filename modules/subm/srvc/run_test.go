@@ -0,0 +1,87 @@
+package srvc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/common/srvcerror"
+	"github.com/programme-lv/backend/modules/exec"
+	tasksrvc "github.com/programme-lv/backend/modules/task/srvc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCustomAppliesTaskLimitsWithoutChecker(t *testing.T) {
+	var gotTests []exec.TestFile
+	var gotParams exec.TestingParams
+	h := runCustomHandler{
+		GetTask: func(ctx context.Context, shortId string) (tasksrvc.Task, srvcerror.E) {
+			return tasksrvc.Task{ShortId: shortId, CpuTimeLimSecs: 0.5, MemLimMegabytes: 64, Checker: "checker"}, nil
+		},
+		Enqueue: func(ctx context.Context, execUuid uuid.UUID, srcCode string, prLangId string, tests []exec.TestFile, params exec.TestingParams) srvcerror.E {
+			gotTests = tests
+			gotParams = params
+			return nil
+		},
+		Listen: func(ctx context.Context, execUuid uuid.UUID) (<-chan exec.Event, srvcerror.E) {
+			return make(chan exec.Event), nil
+		},
+	}
+
+	taskID := "summa"
+	_, err := h.Handle(t.Context(), RunCustomParams{
+		SrcCode:     "print(input())",
+		ProgrLangID: "python3.13",
+		Stdins:      []string{"1\n", "2\n"},
+		TaskShortID: &taskID,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, gotTests, 2)
+	require.Equal(t, "2\n", *gotTests[1].InContent)
+	require.Nil(t, gotParams.Checker)
+	require.Equal(t, 500, gotParams.CpuMs)
+	require.Equal(t, 62500, gotParams.MemKiB)
+}
+
+func TestRunCustomRejectsInvalidInputs(t *testing.T) {
+	h := runCustomHandler{}
+	for _, stdins := range [][]string{
+		nil,
+		make([]string, MaxCustomRunInputs+1),
+		{strings.Repeat("a", MaxCustomRunInputKB*1024+1)},
+	} {
+		_, err := h.Handle(t.Context(), RunCustomParams{SrcCode: "", ProgrLangID: "python3.13", Stdins: stdins})
+		require.Error(t, err)
+	}
+}
+
+func TestRunCustomHonoursTaskVisibility(t *testing.T) {
+	run := func(visibility tasksrvc.Visibility, admin bool) error {
+		h := runCustomHandler{
+			GetTask: func(ctx context.Context, shortId string) (tasksrvc.Task, srvcerror.E) {
+				return tasksrvc.Task{ShortId: shortId, Visibility: visibility}, nil
+			},
+			IsAdmin: func(ctx context.Context) bool { return admin },
+			Enqueue: func(ctx context.Context, execUuid uuid.UUID, srcCode string, prLangId string, tests []exec.TestFile, params exec.TestingParams) srvcerror.E {
+				return nil
+			},
+			Listen: func(ctx context.Context, execUuid uuid.UUID) (<-chan exec.Event, srvcerror.E) {
+				return make(chan exec.Event), nil
+			},
+		}
+		taskID := "summa"
+		_, err := h.Handle(t.Context(), RunCustomParams{
+			SrcCode: "print(1)", ProgrLangID: "python3.13",
+			Stdins: []string{""}, TaskShortID: &taskID,
+		})
+		return err
+	}
+
+	require.NoError(t, run(tasksrvc.VisibilityPublished, false))
+	require.NoError(t, run(tasksrvc.VisibilityHidden, false))
+	require.ErrorIs(t, run(tasksrvc.VisibilityDraft, false), tasksrvc.ErrTaskNotFound)
+	require.ErrorIs(t, run(tasksrvc.VisibilityArchived, false), ErrTaskClosedForSubms)
+	require.NoError(t, run(tasksrvc.VisibilityArchived, true))
+}
@@ -2,10 +2,10 @@ package srvc
 
 import (
 	"context"
-	"sync"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
+	"github.com/nats-io/nats.go"
 	"github.com/programme-lv/backend/common/srvcerror"
 	"github.com/programme-lv/backend/modules/exec"
 	"github.com/programme-lv/backend/modules/subm/domain"
@@ -17,14 +17,16 @@ import (
 type SubmissionService interface {
 	SubmitSol(ctx context.Context, p SubmitSolParams) srvcerror.E
 	ReEvalSubm(ctx context.Context, submUuid uuid.UUID) srvcerror.E
+	ReEvalOutdated(ctx context.Context, taskShortID string) (int, srvcerror.E)
 	ViewSubm(ctx context.Context, uuid uuid.UUID) (domain.Subm, srvcerror.E)
 	ViewSubmByShortID(ctx context.Context, shortID string) (domain.Subm, srvcerror.E)
 	ListSubms(ctx context.Context, filter ListSubmsParams) ([]domain.Subm, srvcerror.E)
 	GetEval(ctx context.Context, uuid uuid.UUID) (domain.Eval, srvcerror.E)
-	SubscribeNewSubms(ctx context.Context) (<-chan domain.Subm, srvcerror.E)
-	SubscribeEvalUpds(ctx context.Context) (<-chan domain.Eval, srvcerror.E)
+	SubscribeUpdates(ctx context.Context, lastEventID string) (<-chan SubmUpdate, srvcerror.E)
 	GetMaxScorePerTask(ctx context.Context, userUUID uuid.UUID) (map[string]domain.MaxScore, srvcerror.E)
 	CountSubms(ctx context.Context, filter ListSubmsParams) (int, srvcerror.E)
+	ResumeEvals(ctx context.Context) srvcerror.E
+	RunCustom(ctx context.Context, p RunCustomParams) (<-chan exec.Event, srvcerror.E)
 }
 
 var _ SubmissionService = &submSrvc{}
@@ -37,13 +39,15 @@ type submSrvc struct {
 	taskSrvc tasksrvc.TaskService
 	execSrvc ExecSrvcFacade
 
-	newSubmChListenerLock sync.Mutex
-	newSubmListeners      map[chan domain.Subm]struct{}
+	feed *updateFeed
 
-	newEvalUpdListenerLock sync.Mutex
-	newEvalUpdListeners    map[chan domain.Eval]struct{}
+	// replica fanout, see WithNatsFanout
+	replicaID     string
+	natsConn      *nats.Conn
+	publishUpdate func(*nats.Msg) error
+	remoteEvals   *remoteEvalSet // in progress on other replicas
 
-	inProgrEval map[uuid.UUID]domain.Eval
+	inProgrEval *inProgrEvals
 }
 
 type SubmRepo interface {
@@ -55,6 +59,10 @@ type SubmRepo interface {
 	StoreSubm(ctx context.Context, subm *domain.Subm) error
 	CountSubms(ctx context.Context, authorUuid *uuid.UUID, taskShortID string, authorIds []string, taskIds []string, langIds []string, includeAdmin bool) (int, error)
 
+	// ListOutdatedSubms returns submissions whose current evaluation
+	// was created from a task revision older than revision
+	ListOutdatedSubms(ctx context.Context, taskShortID string, revision int) ([]uuid.UUID, error)
+
 	// ListShallowSubmsJoinEval does not return submissions without a corresponding evaluation
 	ListShallowSubmsJoinEval(ctx context.Context, authorUuid *uuid.UUID) ([]ShallowSubmJoinEvalDto, error)
 }
@@ -62,11 +70,18 @@ type SubmRepo interface {
 type EvalRepo interface {
 	GetEval(ctx context.Context, evalUUID uuid.UUID) (domain.Eval, error)
 	StoreEval(ctx context.Context, eval domain.Eval) error
+
+	// ListUnfinishedEvals returns current submission evaluations
+	// that have not reached the finished stage
+	ListUnfinishedEvals(ctx context.Context) ([]domain.Eval, error)
 }
 
 type ExecSrvcFacade interface {
 	Enqueue(ctx context.Context, execUuid uuid.UUID, srcCode string, prLangId string, tests []exec.TestFile, params exec.TestingParams) srvcerror.E
 	Listen(ctx context.Context, execUuid uuid.UUID) (<-chan exec.Event, srvcerror.E)
+	HasPending(ctx context.Context, execUuid uuid.UUID) bool
+	ListPendingIDs(ctx context.Context) ([]uuid.UUID, error)
+	Cancel(ctx context.Context, execUuid uuid.UUID) srvcerror.E
 }
 
 func NewSubmSrvc(
@@ -75,17 +90,24 @@ func NewSubmSrvc(
 	execSrvc ExecSrvcFacade,
 	submRepo SubmRepo,
 	evalRepo EvalRepo,
+	opts ...SubmSrvcOption,
 ) *submSrvc {
-	return &submSrvc{
+	replicaID := uuid.NewString()
+	s := &submSrvc{
 		userSrvc: userSrvc,
 		taskSrvc: taskSrvc,
 		execSrvc: execSrvc,
 		submRepo: submRepo,
 		evalRepo: evalRepo,
 
-		newSubmListeners:    make(map[chan domain.Subm]struct{}),
-		newEvalUpdListeners: make(map[chan domain.Eval]struct{}),
+		feed:        newUpdateFeed(replicaID),
+		replicaID:   replicaID,
+		remoteEvals: newRemoteEvalSet(),
 
-		inProgrEval: make(map[uuid.UUID]domain.Eval),
+		inProgrEval: newInProgrEvals(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
@@ -0,0 +1,332 @@
+// Package cms converts CMS (italy_yaml) task directories, as used by
+// the IOI and BOI archives, into the TaskZip model.
+//
+// Batch tasks with the default output comparison are supported. Tasks with
+// a checker, communication, output-only and grader-based tasks are rejected
+// with [ErrUnsupported].
+package cms
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/programme-lv/backend/modules/task/taskzip"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnsupported wraps errors about task parts the backend cannot represent.
+var ErrUnsupported = errors.New("unsupported CMS task")
+
+// taskYAML is the subset of task.yaml that the importer reads.
+type taskYAML struct {
+	Name                string    `yaml:"name"`
+	Title               string    `yaml:"title"`
+	TimeLimit           float64   `yaml:"time_limit"`
+	MemoryLimit         uint32    `yaml:"memory_limit"`
+	NInput              int       `yaml:"n_input"`
+	Infile              string    `yaml:"infile"`
+	Outfile             string    `yaml:"outfile"`
+	ScoreType           string    `yaml:"score_type"`
+	ScoreTypeParameters yaml.Node `yaml:"score_type_parameters"`
+	PrimaryLanguage     string    `yaml:"primary_language"`
+	OutputOnly          bool      `yaml:"output_only"`
+}
+
+// subtask is a run of tests worth points together.
+type subtask struct {
+	points float64
+	tests  []int
+}
+
+// Read converts a zipped CMS task directory. The returned task is not
+// validated, because the caller may replace its ID.
+func Read(data []byte) (taskzip.Task, error) {
+	files, err := taskzip.ReadFiles(data, "task.yaml")
+	if err != nil {
+		return taskzip.Task{}, err
+	}
+	var meta taskYAML
+	if err := yaml.Unmarshal(files["task.yaml"], &meta); err != nil {
+		return taskzip.Task{}, fmt.Errorf("task.yaml: %w", err)
+	}
+	if err := checkSupported(meta, files); err != nil {
+		return taskzip.Task{}, err
+	}
+
+	lang := meta.PrimaryLanguage
+	if lang == "" {
+		lang = "en"
+	}
+	title := meta.Title
+	if title == "" {
+		title = meta.Name
+	}
+	task := taskzip.Task{
+		Version:         1,
+		ID:              strings.ToLower(strings.ReplaceAll(meta.Name, "_", "-")),
+		Name:            map[string]string{lang: title},
+		Statements:      map[string][]byte{},
+		StatementImages: map[string][]byte{},
+		Testing: taskzip.Testing{
+			Type:   "simple",
+			CPUMs:  uint32(math.Round(meta.TimeLimit * 1000)),
+			MemMiB: meta.MemoryLimit,
+		},
+	}
+	readStatements(files, lang, &task)
+	if len(task.Statements) == 0 {
+		return taskzip.Task{}, fmt.Errorf("%w: no markdown statement in statement/, PDF statements cannot be imported", ErrUnsupported)
+	}
+
+	tests := make([]taskzip.Test, meta.NInput)
+	for i := range tests {
+		input, ok := files[fmt.Sprintf("input/input%d.txt", i)]
+		if !ok {
+			return taskzip.Task{}, fmt.Errorf("input/input%d.txt missing", i)
+		}
+		output, ok := files[fmt.Sprintf("output/output%d.txt", i)]
+		if !ok {
+			return taskzip.Task{}, fmt.Errorf("output/output%d.txt missing", i)
+		}
+		tests[i] = taskzip.Test{Input: taskzip.LF(input), Output: taskzip.LF(output)}
+	}
+	subtasks, err := readSubtasks(meta, files)
+	if err != nil {
+		return taskzip.Task{}, err
+	}
+	if err := mapSubtasks(subtasks, tests, &task); err != nil {
+		return taskzip.Task{}, err
+	}
+	readSolutions(files, meta.Name, &task)
+	readAttachments(files, &task)
+	return task, nil
+}
+
+func checkSupported(meta taskYAML, files map[string][]byte) error {
+	switch {
+	case meta.OutputOnly:
+		return fmt.Errorf("%w: output-only task", ErrUnsupported)
+	case hasManager(files, "manager"):
+		return fmt.Errorf("%w: communication task", ErrUnsupported)
+	case hasManager(files, "grader"), hasManager(files, "stub"):
+		return fmt.Errorf("%w: task with a grader", ErrUnsupported)
+	case hasManager(files, "checker"), hasManager(files, "correttore"):
+		// a CMS checker prints a score from 0.0 to 1.0 and exits 0,
+		// which a testlib checker's exit code would grade as accepted
+		return fmt.Errorf("%w: CMS checker, it reports a score instead of a testlib exit code", ErrUnsupported)
+	case meta.Infile != "" || meta.Outfile != "":
+		return fmt.Errorf("%w: file input and output (%q, %q)", ErrUnsupported, meta.Infile, meta.Outfile)
+	}
+	return nil
+}
+
+func hasManager(files map[string][]byte, name string) bool {
+	for file := range files {
+		dir, base := path.Split(file)
+		if (dir == "check/" || dir == "cor/" || dir == "sol/") &&
+			strings.TrimSuffix(base, path.Ext(base)) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// readStatements reads statement/<lang>.md files; statement.md is in the
+// primary language. Images next to them become statement images.
+func readStatements(files map[string][]byte, lang string, task *taskzip.Task) {
+	for name, data := range files {
+		for _, dir := range []string{"statement/", "testo/"} {
+			base, ok := strings.CutPrefix(name, dir)
+			if !ok || strings.Contains(base, "/") {
+				continue
+			}
+			ext := strings.ToLower(path.Ext(base))
+			switch ext {
+			case ".md":
+				stem := strings.TrimSuffix(base, path.Ext(base))
+				if stem == "statement" || stem == "testo" {
+					stem = lang
+				}
+				task.Statements[stem] = taskzip.LF(data)
+			case ".png", ".jpg", ".jpeg", ".webp", ".svg":
+				task.StatementImages[base] = data
+			}
+		}
+	}
+}
+
+var stLine = regexp.MustCompile(`^#\s*ST:\s*(\S+)`)
+
+// readSubtasks reads "# ST: points" subtasks from gen/GEN, where every
+// other non-comment line is a test, or falls back to GroupMin parameters
+// in task.yaml. A task without either is scored per test.
+func readSubtasks(meta taskYAML, files map[string][]byte) ([]subtask, error) {
+	if gen, ok := files["gen/GEN"]; ok {
+		return parseGEN(string(gen), meta.NInput)
+	}
+	switch meta.ScoreType {
+	case "", "Sum":
+		return nil, nil
+	case "GroupMin":
+		return parseGroupParameters(meta.ScoreTypeParameters, meta.NInput)
+	}
+	return nil, fmt.Errorf("%w: score type %s", ErrUnsupported, meta.ScoreType)
+}
+
+func parseGEN(gen string, nInput int) ([]subtask, error) {
+	var subtasks []subtask
+	test := 0
+	for _, line := range strings.Split(strings.ReplaceAll(gen, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if m := stLine.FindStringSubmatch(line); m != nil {
+			points, err := taskzip.ParsePoints(m[1], ErrUnsupported)
+			if err != nil {
+				return nil, fmt.Errorf("gen/GEN: %w", err)
+			}
+			subtasks = append(subtasks, subtask{points: points})
+			continue
+		}
+		if strings.HasPrefix(line, "#COPY:") {
+			line = "copy"
+		} else if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		if len(subtasks) == 0 {
+			return nil, errors.New("gen/GEN: test before the first # ST: line")
+		}
+		subtasks[len(subtasks)-1].tests = append(subtasks[len(subtasks)-1].tests, test)
+		test++
+	}
+	if len(subtasks) == 0 {
+		return nil, nil
+	}
+	if test != nInput {
+		return nil, fmt.Errorf("gen/GEN has %d tests, task.yaml n_input is %d", test, nInput)
+	}
+	return subtasks, nil
+}
+
+// parseGroupParameters reads [[points, count], ...] or
+// [[points, "regexp"], ...], where the regexp matches test codenames
+// 000, 001 and so on.
+func parseGroupParameters(node yaml.Node, nInput int) ([]subtask, error) {
+	var params [][2]yaml.Node
+	if err := node.Decode(&params); err != nil {
+		return nil, fmt.Errorf("task.yaml: score_type_parameters: %w", err)
+	}
+	var subtasks []subtask
+	next := 0
+	for _, param := range params {
+		points, err := taskzip.ParsePoints(param[0].Value, ErrUnsupported)
+		if err != nil {
+			return nil, fmt.Errorf("task.yaml: %w", err)
+		}
+		st := subtask{points: points}
+		// a quoted "004" is a codename regexp, a plain 4 a test count
+		if param[1].Tag == "!!int" {
+			var count int
+			if err := param[1].Decode(&count); err != nil || count < 0 {
+				return nil, fmt.Errorf("task.yaml: invalid test count %q in score_type_parameters", param[1].Value)
+			}
+			for i := 0; i < count; i++ {
+				st.tests = append(st.tests, next+i)
+			}
+			next += count
+		} else {
+			re, err := regexp.Compile("^(?:" + param[1].Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("task.yaml: subtask regexp %q: %w", param[1].Value, err)
+			}
+			for i := 0; i < nInput; i++ {
+				if re.MatchString(fmt.Sprintf("%03d", i)) {
+					st.tests = append(st.tests, i)
+				}
+			}
+		}
+		subtasks = append(subtasks, st)
+	}
+	return subtasks, nil
+}
+
+// mapSubtasks stores scored subtasks as TaskZip test ranges. Zero-point
+// subtasks, usually the samples, become examples instead.
+func mapSubtasks(subtasks []subtask, tests []taskzip.Test, task *taskzip.Task) error {
+	if len(subtasks) == 0 {
+		task.Tests = tests
+		return nil
+	}
+	used := make([]bool, len(tests))
+	for i, st := range subtasks {
+		for j, test := range st.tests {
+			if test >= len(tests) || used[test] || j > 0 && test != st.tests[j-1]+1 {
+				return fmt.Errorf("%w: subtask %d is not a separate contiguous range of tests", ErrUnsupported, i+1)
+			}
+			used[test] = true
+		}
+		if st.points == 0 {
+			for _, test := range st.tests {
+				task.Examples = append(task.Examples, taskzip.Example{Input: tests[test].Input, Output: tests[test].Output})
+			}
+			continue
+		}
+		if len(st.tests) == 0 {
+			return fmt.Errorf("subtask %d has no tests", i+1)
+		}
+		points := uint32(st.points)
+		first := len(task.Tests) + 1
+		for _, test := range st.tests {
+			task.Tests = append(task.Tests, tests[test])
+		}
+		task.Subtasks = append(task.Subtasks, taskzip.Subtask{
+			Tests:  fmt.Sprintf("%03d-%03d", first, len(task.Tests)),
+			Points: &points,
+		})
+	}
+	for test, ok := range used {
+		if !ok {
+			return fmt.Errorf("%w: test %d belongs to no subtask", ErrUnsupported, test)
+		}
+	}
+	return nil
+}
+
+// readSolutions keeps the reference solutions in sol/, named after the
+// task or soluzione/solution. They are expected to earn every point.
+func readSolutions(files map[string][]byte, name string, task *taskzip.Task) {
+	var names []string
+	for file := range files {
+		dir, base := path.Split(file)
+		stem := strings.TrimSuffix(base, path.Ext(base))
+		if dir == "sol/" && path.Ext(base) != "" && (stem == name || stem == "soluzione" || stem == "solution") {
+			names = append(names, file)
+		}
+	}
+	sort.Strings(names)
+	for _, file := range names {
+		task.Solutions = append(task.Solutions, taskzip.Solution{Filename: path.Base(file), Data: taskzip.LF(files[file])})
+	}
+}
+
+// readAttachments hands out the files in att/, such as sample archives.
+func readAttachments(files map[string][]byte, task *taskzip.Task) {
+	var names []string
+	for file := range files {
+		if strings.HasPrefix(file, "att/") {
+			names = append(names, file)
+		}
+	}
+	sort.Strings(names)
+	for _, file := range names {
+		task.Attachments = append(task.Attachments, taskzip.Attachment{
+			Path: strings.TrimPrefix(file, "att/"), Data: files[file],
+		})
+	}
+}
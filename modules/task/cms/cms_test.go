@@ -0,0 +1,181 @@
+package cms
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/programme-lv/backend/modules/task/taskzip"
+)
+
+const taskYAMLForTest = `name: Graph_Walk
+title: Graph Walk
+time_limit: 1.5
+memory_limit: 512
+n_input: 5
+infile: ""
+outfile: ""
+primary_language: en
+public_testcases: 0,1
+score_type: GroupMin
+`
+
+const genForTest = `# samples
+# ST: 0
+1 2
+#COPY: manual/sample2.txt
+# ST: 40
+3 4 # small
+5 6
+# ST: 60
+7 8
+`
+
+func cmsFilesForTest() map[string][]byte {
+	files := map[string][]byte{
+		"graph_walk/task.yaml":               []byte(taskYAMLForTest),
+		"graph_walk/gen/GEN":                 []byte(genForTest),
+		"graph_walk/statement/statement.md":  []byte("Walk the graph.\n\n![](walk.png)\n"),
+		"graph_walk/statement/lv.md":         []byte("Staigājiet pa grafu.\n"),
+		"graph_walk/statement/walk.png":      []byte("png"),
+		"graph_walk/statement/statement.pdf": []byte("%PDF"),
+		"graph_walk/sol/soluzione.cpp":       []byte("int main() {}\n"),
+		"graph_walk/att/graph_walk.zip":      []byte("PK"),
+		"graph_walk/sol/brute.cpp":           []byte("int main() {}\n"),
+	}
+	for i := 0; i < 5; i++ {
+		files[fmt.Sprintf("graph_walk/input/input%d.txt", i)] = []byte(fmt.Sprintf("%d\r\n", i))
+		files[fmt.Sprintf("graph_walk/output/output%d.txt", i)] = []byte(fmt.Sprintf("%d\n", i*i))
+	}
+	return files
+}
+
+func zipForTest(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	task, err := Read(zipForTest(t, cmsFilesForTest()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := taskzip.Validate(&task); err != nil {
+		t.Fatalf("converted task is not a valid TaskZip: %v", err)
+	}
+	if task.ID != "graph-walk" || task.Name["en"] != "Graph Walk" {
+		t.Fatalf("unexpected id or name: %q %v", task.ID, task.Name)
+	}
+	if task.Testing != (taskzip.Testing{Type: "simple", CPUMs: 1500, MemMiB: 512}) || task.Checker != nil {
+		t.Fatalf("unexpected testing: %+v", task.Testing)
+	}
+	if len(task.Statements) != 2 || string(task.Statements["lv"]) != "Staigājiet pa grafu.\n" {
+		t.Fatalf("unexpected statements: %v", task.Statements)
+	}
+	if string(task.StatementImages["walk.png"]) != "png" {
+		t.Fatalf("statement image missing: %v", task.StatementImages)
+	}
+
+	if len(task.Examples) != 2 || string(task.Examples[1].Input) != "1\n" {
+		t.Fatalf("zero-point subtask should become examples: %+v", task.Examples)
+	}
+	if len(task.Tests) != 3 || string(task.Tests[0].Input) != "2\n" || string(task.Tests[2].Output) != "16\n" {
+		t.Fatalf("unexpected tests: %+v", task.Tests)
+	}
+	p40, p60 := uint32(40), uint32(60)
+	wantSubtasks := []taskzip.Subtask{{Tests: "001-002", Points: &p40}, {Tests: "003-003", Points: &p60}}
+	if !reflect.DeepEqual(task.Subtasks, wantSubtasks) {
+		t.Fatalf("unexpected subtasks: %+v", task.Subtasks)
+	}
+	if len(task.Solutions) != 1 || task.Solutions[0].Filename != "soluzione.cpp" {
+		t.Fatalf("expected only the reference solution: %+v", task.Solutions)
+	}
+	if len(task.Attachments) != 1 || task.Attachments[0].Path != "graph_walk.zip" {
+		t.Fatalf("unexpected attachments: %+v", task.Attachments)
+	}
+}
+
+func TestReadGroupParameters(t *testing.T) {
+	files := cmsFilesForTest()
+	delete(files, "graph_walk/gen/GEN")
+	files["graph_walk/task.yaml"] = []byte(taskYAMLForTest + "score_type_parameters: [[0, 2], [40, \"00[23]\"], [60, \"004\"]]\n")
+	task, err := Read(zipForTest(t, files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(task.Examples) != 2 || len(task.Tests) != 3 || len(task.Subtasks) != 2 {
+		t.Fatalf("unexpected scoring: %d examples, %d tests, %+v", len(task.Examples), len(task.Tests), task.Subtasks)
+	}
+}
+
+func TestReadInvalidGroupParameters(t *testing.T) {
+	for _, count := range []string{"-1", "!!int many"} {
+		files := cmsFilesForTest()
+		delete(files, "graph_walk/gen/GEN")
+		files["graph_walk/task.yaml"] = []byte(taskYAMLForTest + "score_type_parameters: [[100, " + count + "]]\n")
+		_, err := Read(zipForTest(t, files))
+		if err == nil || errors.Is(err, ErrUnsupported) || !strings.Contains(err.Error(), "invalid test count") {
+			t.Fatalf("count %s: expected an invalid count error, got %v", count, err)
+		}
+	}
+}
+
+func TestReadUnsupported(t *testing.T) {
+	tests := map[string]func(files map[string][]byte){
+		"communication": func(files map[string][]byte) {
+			files["graph_walk/check/manager.cpp"] = []byte("int main() {}\n")
+		},
+		"grader": func(files map[string][]byte) {
+			files["graph_walk/sol/grader.cpp"] = []byte("int main() {}\n")
+		},
+		"output only": func(files map[string][]byte) {
+			files["graph_walk/task.yaml"] = []byte(taskYAMLForTest + "output_only: true\n")
+		},
+		"pdf statement": func(files map[string][]byte) {
+			delete(files, "graph_walk/statement/statement.md")
+			delete(files, "graph_walk/statement/lv.md")
+		},
+		"cms checker": func(files map[string][]byte) {
+			// scores every output 0 and exits 0, which testlib reads as accepted
+			files["graph_walk/check/checker.cpp"] = []byte("#include \"testlib.h\"\n#include <cstdio>\nint main() { printf(\"0\\n\"); return 0; }\n")
+		},
+		"compiled checker": func(files map[string][]byte) {
+			files["graph_walk/cor/correttore"] = []byte("\x7fELF")
+		},
+		"fractional points": func(files map[string][]byte) {
+			files["graph_walk/gen/GEN"] = []byte("# ST: 0.5\n1\n2\n3\n4\n5\n")
+		},
+		"overlapping subtasks": func(files map[string][]byte) {
+			delete(files, "graph_walk/gen/GEN")
+			files["graph_walk/task.yaml"] = []byte(taskYAMLForTest + "score_type_parameters: [[50, \"00[0-3]\"], [50, \"00[3-4]\"]]\n")
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			files := cmsFilesForTest()
+			mutate(files)
+			_, err := Read(zipForTest(t, files))
+			if !errors.Is(err, ErrUnsupported) {
+				t.Fatalf("expected ErrUnsupported, got %v", err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/programme-lv/backend/common/jsonresp"
@@ -50,8 +51,7 @@ func (h *taskHttpHandler) DeleteStatementImage(ctx context.Context) jsonresp.Htt
 		return err
 	}
 
-	h.getTaskViewCache.Delete(taskId)
-	h.getTaskListCache.Delete("")
+	h.invalidateTask(taskId)
 	return nil
 }
 
@@ -64,8 +64,7 @@ func (h *taskHttpHandler) DeleteIllustration(ctx context.Context) jsonresp.HttpS
 		return err
 	}
 
-	h.getTaskViewCache.Delete(taskId)
-	h.getTaskListCache.Delete("")
+	h.invalidateTask(taskId)
 
 	return nil
 }
@@ -84,10 +83,28 @@ func (h *taskHttpHandler) DeleteTask(ctx context.Context) jsonresp.HttpStatusCod
 		return err
 	}
 
-	h.getTaskViewCache.Delete(taskId)
-	h.getTaskListCache.Delete("")
+	h.invalidateTask(taskId)
 
 	logger.Info("task deleted successfully", "task_id", taskId)
 
 	return nil
 }
+
+// PutVisibilityReq is the JSON body for PUT /tasks/{taskId}/visibility.
+type PutVisibilityReq struct {
+	Visibility string     `json:"visibility"`
+	PublishAt  *time.Time `json:"publish_at"`
+}
+
+// PutVisibility sets who can see the task and invalidates cached views.
+func (h *taskHttpHandler) PutVisibility(ctx context.Context, req PutVisibilityReq) jsonresp.HttpStatusCoder {
+	taskId := chi.URLParamFromCtx(ctx, "taskId")
+
+	err := h.taskSrvc.SetTaskVisibility(ctx, taskId, srvc.Visibility(req.Visibility), req.PublishAt)
+	if err != nil {
+		return err
+	}
+
+	h.invalidateTask(taskId)
+	return nil
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/programme-lv/backend/common/jsonresp"
+	"github.com/programme-lv/backend/modules/task/srvc"
+)
+
+// EditTaskResponse is the JSON body returned after an edit
+// of limits, checker, tests or scoring.
+type EditTaskResponse struct {
+	Revision int `json:"revision"`
+}
+
+// PutLimitsReq is the JSON body for PUT /tasks/{taskId}/limits.
+type PutLimitsReq struct {
+	CPUTimeLimitSeconds  float64 `json:"cpu_time_limit_seconds"`
+	MemoryLimitMegabytes int     `json:"memory_limit_megabytes"`
+}
+
+// PutLimits changes the CPU time and memory limits.
+func (h *taskHttpHandler) PutLimits(ctx context.Context, req PutLimitsReq) (*EditTaskResponse, jsonresp.HttpStatusCoder) {
+	taskId := chi.URLParamFromCtx(ctx, "taskId")
+	revision, err := h.taskSrvc.SetTaskLimits(ctx, taskId, req.CPUTimeLimitSeconds, req.MemoryLimitMegabytes)
+	if err != nil {
+		return nil, err
+	}
+	h.invalidateTask(taskId)
+	return &EditTaskResponse{Revision: revision}, nil
+}
+
+// PutCheckerReq is the JSON body for PUT /tasks/{taskId}/checker.
+type PutCheckerReq struct {
+	Checker string `json:"checker"`
+}
+
+// PutChecker replaces the checker source; an empty one removes it.
+func (h *taskHttpHandler) PutChecker(ctx context.Context, req PutCheckerReq) (*EditTaskResponse, jsonresp.HttpStatusCoder) {
+	taskId := chi.URLParamFromCtx(ctx, "taskId")
+	revision, err := h.taskSrvc.SetTaskChecker(ctx, taskId, req.Checker)
+	if err != nil {
+		return nil, err
+	}
+	h.invalidateTask(taskId)
+	return &EditTaskResponse{Revision: revision}, nil
+}
+
+// PutScoringReq is the JSON body for PUT /tasks/{taskId}/scoring.
+// Test IDs and VisInpSubtasks are 1-based.
+type PutScoringReq struct {
+	Subtasks       []ScoringSubtask   `json:"subtasks"`
+	TestGroups     []ScoringTestGroup `json:"test_groups"`
+	VisInpSubtasks []int              `json:"vis_inp_subtasks"`
+}
+
+type ScoringSubtask struct {
+	Score        int               `json:"score"`
+	TestIDs      []int             `json:"test_ids"`
+	Descriptions map[string]string `json:"descriptions"`
+}
+
+type ScoringTestGroup struct {
+	Points  int   `json:"points"`
+	Public  bool  `json:"public"`
+	TestIDs []int `json:"test_ids"`
+}
+
+// PutScoring replaces the subtasks, test groups and visible input subtasks.
+func (h *taskHttpHandler) PutScoring(ctx context.Context, req PutScoringReq) (*EditTaskResponse, jsonresp.HttpStatusCoder) {
+	taskId := chi.URLParamFromCtx(ctx, "taskId")
+	scoring := srvc.TaskScoring{VisInpSubtaskIds: req.VisInpSubtasks}
+	for _, subtask := range req.Subtasks {
+		scoring.Subtasks = append(scoring.Subtasks, srvc.Subtask{
+			Score: subtask.Score, TestIDs: subtask.TestIDs, Descriptions: subtask.Descriptions,
+		})
+	}
+	for _, group := range req.TestGroups {
+		scoring.TestGroups = append(scoring.TestGroups, srvc.TestGroup{
+			Points: group.Points, Public: group.Public, TestIDs: group.TestIDs,
+		})
+	}
+	revision, err := h.taskSrvc.SetTaskScoring(ctx, taskId, scoring)
+	if err != nil {
+		return nil, err
+	}
+	h.invalidateTask(taskId)
+	return &EditTaskResponse{Revision: revision}, nil
+}
+
+// AddTest inserts a test from multipart fields input and answer
+// after the test given by query parameter after, or first without it.
+func (h *taskHttpHandler) AddTest(w http.ResponseWriter, r *http.Request) {
+	taskId := chi.URLParam(r, "taskId")
+	after := 0
+	if value := r.URL.Query().Get("after"); value != "" {
+		var err error
+		if after, err = strconv.Atoi(value); err != nil {
+			jsonresp.BadRequest(w, "after must be a test number")
+			return
+		}
+	}
+	input, answer, ok := readTestForm(w, r)
+	if !ok {
+		return
+	}
+	if input == nil || answer == nil {
+		jsonresp.BadRequest(w, "input and answer are required")
+		return
+	}
+	revision, err := h.taskSrvc.AddTaskTest(r.Context(), taskId, after, input, answer)
+	if err != nil {
+		jsonresp.WriteError(w, err)
+		return
+	}
+	h.invalidateTask(taskId)
+	_ = jsonresp.Success(w, EditTaskResponse{Revision: revision})
+}
+
+// ReplaceTest replaces test {testId} with multipart fields input,
+// answer or both.
+func (h *taskHttpHandler) ReplaceTest(w http.ResponseWriter, r *http.Request) {
+	taskId := chi.URLParam(r, "taskId")
+	testId, err := strconv.Atoi(chi.URLParam(r, "testId"))
+	if err != nil {
+		jsonresp.BadRequest(w, "testId must be a number")
+		return
+	}
+	input, answer, ok := readTestForm(w, r)
+	if !ok {
+		return
+	}
+	revision, replaceErr := h.taskSrvc.ReplaceTaskTest(r.Context(), taskId, testId, input, answer)
+	if replaceErr != nil {
+		jsonresp.WriteError(w, replaceErr)
+		return
+	}
+	h.invalidateTask(taskId)
+	_ = jsonresp.Success(w, EditTaskResponse{Revision: revision})
+}
+
+// DeleteTest removes test {testId}; later tests move up by one.
+func (h *taskHttpHandler) DeleteTest(ctx context.Context) (*EditTaskResponse, jsonresp.HttpStatusCoder) {
+	taskId := chi.URLParamFromCtx(ctx, "taskId")
+	testId, err := strconv.Atoi(chi.URLParamFromCtx(ctx, "testId"))
+	if err != nil {
+		return nil, jsonresp.ErrHttpBadRequest.WithMsg("testId must be a number")
+	}
+	revision, removeErr := h.taskSrvc.RemoveTaskTest(ctx, taskId, testId)
+	if removeErr != nil {
+		return nil, removeErr
+	}
+	h.invalidateTask(taskId)
+	return &EditTaskResponse{Revision: revision}, nil
+}
+
+// readTestForm reads the optional multipart files input and answer.
+// It writes the error response and returns false on failure.
+func readTestForm(w http.ResponseWriter, r *http.Request) ([]byte, []byte, bool) {
+	if err := r.ParseMultipartForm(256 << 20); err != nil { // 256MB cap
+		jsonresp.BadRequest(w, fmt.Sprintf("parse multipart form: %v", err))
+		return nil, nil, false
+	}
+	read := func(field string) ([]byte, bool) {
+		file, _, err := r.FormFile(field)
+		if err == http.ErrMissingFile {
+			return nil, true
+		}
+		if err != nil {
+			jsonresp.BadRequest(w, fmt.Sprintf("read %s: %v", field, err))
+			return nil, false
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			jsonresp.BadRequest(w, fmt.Sprintf("read %s: %v", field, err))
+			return nil, false
+		}
+		return data, true
+	}
+	input, ok := read("input")
+	if !ok {
+		return nil, nil, false
+	}
+	answer, ok := read("answer")
+	if !ok {
+		return nil, nil, false
+	}
+	return input, answer, true
+}
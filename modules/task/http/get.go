@@ -2,19 +2,28 @@ package http
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/programme-lv/backend/common/jsonresp"
+	"github.com/programme-lv/backend/modules/task/srvc"
+	"github.com/programme-lv/backend/modules/user/auth"
 )
 
 // GetTaskView returns the JSON task for {taskId}.
+// Drafts are reported as not found to everyone but admins.
 // The response is cached for 20 seconds.
 func (h *taskHttpHandler) GetTaskView(ctx context.Context) (Task, jsonresp.HttpStatusCoder) {
 	taskId := chi.URLParamFromCtx(ctx, "taskId")
 
 	if task, ok := h.getTaskViewCache.Get(taskId); ok {
-		return task, nil
+		return h.visibleTaskView(ctx, task)
 	}
 
 	t, err := h.taskSrvc.GetTask(ctx, taskId)
@@ -29,41 +38,144 @@ func (h *taskHttpHandler) GetTaskView(ctx context.Context) (Task, jsonresp.HttpS
 	}
 
 	h.getTaskViewCache.Set(taskId, response, 20*time.Second)
-	return response, nil
+	return h.visibleTaskView(ctx, response)
 }
 
-// GetTaskList returns JSON previews of all tasks.
-// The response is cached for 20 seconds.
-func (h *taskHttpHandler) GetTaskList(ctx context.Context) ([]TaskPreview, jsonresp.HttpStatusCoder) {
-	if previews, ok := h.getTaskListCache.Get(""); ok {
-		return previews, nil
+// visibleTaskView checks visibility on every request, so that
+// cached views of drafts reach only admins.
+func (h *taskHttpHandler) visibleTaskView(ctx context.Context, task Task) (Task, jsonresp.HttpStatusCoder) {
+	visibility := srvc.EffectiveVisibility(srvc.Visibility(task.Visibility), task.PublishAt, time.Now())
+	if !visibility.Viewable() && !auth.IsAdmin(ctx) {
+		return Task{}, srvc.ErrTaskNotFound
 	}
+	return task, nil
+}
+
+// taskListParams are the query parameters of GET /tasks.
+// A request with none of them gets the legacy JSON array.
+var taskListParams = []string{
+	"olympiad", "year", "stage", "division",
+	"min_difficulty", "max_difficulty", "tags",
+	"sort", "order", "offset", "limit",
+}
 
-	tasks, err := h.taskSrvc.ListTaskPreviews(ctx)
+// GetTaskList returns one page of JSON previews of listed tasks,
+// or of all tasks for admins. Query parameters olympiad, year, stage
+// and division take the ids of GET /task-filters; min_difficulty,
+// max_difficulty and comma-separated tags narrow the list further.
+// sort is name, difficulty, year or solves, and order=desc reverses it.
+// Without any of these parameters the first page is returned as a
+// bare array, as before pagination existed.
+// The response is cached for 20 seconds per query.
+func (h *taskHttpHandler) GetTaskList(w http.ResponseWriter, r *http.Request) {
+	paged := slices.ContainsFunc(taskListParams, r.URL.Query().Has)
+	q, err := parseTaskListQuery(r.URL.Query())
 	if err != nil {
-		return nil, err
+		jsonresp.BadRequest(w, err.Error())
+		return
 	}
-	previews := make([]TaskPreview, 0, len(tasks))
-	for _, t := range tasks {
+	q.IncludeUnlisted = auth.IsAdmin(r.Context())
+
+	cacheKey := fmt.Sprintf("%t:%s:%s:%s:%s:%d:%d:%s:%s:%t:%d:%d",
+		q.IncludeUnlisted, q.Olympiad, q.Year, q.Stage, q.Division,
+		q.MinDifficulty, q.MaxDifficulty, strings.Join(q.Tags, ","),
+		q.Sort, q.Desc, q.Offset, q.Limit)
+	if page, ok := h.getTaskListCache.Get(cacheKey); ok {
+		writeTaskList(w, page, paged)
+		return
+	}
+
+	page, listErr := h.taskSrvc.ListTaskPreviews(r.Context(), q)
+	if listErr != nil {
+		jsonresp.WriteError(w, listErr)
+		return
+	}
+	previews := make([]TaskPreview, 0, len(page.Tasks))
+	for _, t := range page.Tasks {
 		previews = append(previews, h.mapTaskPreview(t))
 	}
+	limit := q.Limit
+	if limit == 0 {
+		limit = srvc.DefaultTaskListLimit
+	}
+	response := TaskListPage{
+		Page: previews,
+		Pagination: Pagination{
+			Total:   page.Total,
+			Offset:  q.Offset,
+			Limit:   limit,
+			HasMore: q.Offset+len(previews) < page.Total,
+		},
+	}
+
+	h.getTaskListCache.Set(cacheKey, response, 20*time.Second)
+	writeTaskList(w, response, paged)
+}
+
+func writeTaskList(w http.ResponseWriter, page TaskListPage, paged bool) {
+	if !paged {
+		_ = jsonresp.Success(w, page.Page)
+		return
+	}
+	_ = jsonresp.Success(w, page)
+}
 
-	h.getTaskListCache.Set("", previews, 20*time.Second)
-	return previews, nil
+// parseTaskListQuery reads the GET /tasks query parameters.
+// Ranges and sort keys are validated by the service.
+func parseTaskListQuery(values url.Values) (srvc.TaskListQuery, error) {
+	q := srvc.TaskListQuery{
+		Olympiad: values.Get("olympiad"),
+		Year:     values.Get("year"),
+		Stage:    values.Get("stage"),
+		Division: values.Get("division"),
+		Sort:     srvc.TaskSort(values.Get("sort")),
+	}
+	for _, tag := range strings.Split(values.Get("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			q.Tags = append(q.Tags, tag)
+		}
+	}
+	switch values.Get("order") {
+	case "", "asc":
+	case "desc":
+		q.Desc = true
+	default:
+		return q, fmt.Errorf("order must be asc or desc")
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"min_difficulty", &q.MinDifficulty},
+		{"max_difficulty", &q.MaxDifficulty},
+		{"offset", &q.Offset},
+		{"limit", &q.Limit},
+	} {
+		if v := values.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return q, fmt.Errorf("%s must be a number", p.name)
+			}
+			*p.dst = n
+		}
+	}
+	return q, nil
 }
 
 // GetTaskFilters returns the origin catalog for the task-list sidebar.
+// Like GET /tasks, it counts unlisted tasks only for admins.
 // The response is cached for 20 seconds.
 func (h *taskHttpHandler) GetTaskFilters(ctx context.Context) (TaskFilterTree, jsonresp.HttpStatusCoder) {
-	if tree, ok := h.getTaskFiltersCache.Get(""); ok {
+	includeUnlisted := auth.IsAdmin(ctx)
+	if tree, ok := h.getTaskFiltersCache.Get(listCacheKey(includeUnlisted)); ok {
 		return tree, nil
 	}
 
-	tree, err := h.taskSrvc.ListTaskFilters(ctx)
+	tree, err := h.taskSrvc.ListTaskFilters(ctx, includeUnlisted)
 	if err != nil {
 		return TaskFilterTree{}, err
 	}
 	response := mapFilterTree(tree)
-	h.getTaskFiltersCache.Set("", response, 20*time.Second)
+	h.getTaskFiltersCache.Set(listCacheKey(includeUnlisted), response, 20*time.Second)
 	return response, nil
 }
@@ -25,7 +25,7 @@ type taskHttpHandler struct {
 	taskSrvc srvc.TaskService
 
 	getTaskViewCache    *cache.LruCache[string, Task]
-	getTaskListCache    *cache.LruCache[string, []TaskPreview]
+	getTaskListCache    *cache.LruCache[string, TaskListPage]
 	getTaskFiltersCache *cache.LruCache[string, TaskFilterTree]
 
 	publicAssetStore           *filestore.Store
@@ -51,7 +51,7 @@ func NewTaskHttpHandler(taskSrvc srvc.TaskService, opts ...HandlerOption) *taskH
 	h := &taskHttpHandler{
 		taskSrvc:            taskSrvc,
 		getTaskViewCache:    cache.NewLruCache[string, Task](1000),
-		getTaskListCache:    cache.NewLruCache[string, []TaskPreview](1000),
+		getTaskListCache:    cache.NewLruCache[string, TaskListPage](1000),
 		getTaskFiltersCache: cache.NewLruCache[string, TaskFilterTree](8),
 	}
 	for _, opt := range opts {
@@ -63,6 +63,8 @@ func NewTaskHttpHandler(taskSrvc srvc.TaskService, opts ...HandlerOption) *taskH
 // RegisterRoutes mounts task HTTP routes on r.
 // GET /task-filters, GET /tasks, and GET /tasks/{taskId} require a JWT and
 // are throttled to one in-flight request to avoid a cache stampede.
+// They and the uncached GET /tasks/search hide tasks by visibility
+// from everyone except admins.
 // Admin routes require an admin API key.
 // Upload and export are throttled separately because they are expensive.
 func (h *taskHttpHandler) RegisterRoutes(r *chi.Mux, jwtKey, adminAPIKey []byte, cookieSecure bool, pwdChangedAt auth.PasswordChangedAtLookup) {
@@ -84,9 +86,11 @@ func (h *taskHttpHandler) RegisterRoutes(r *chi.Mux, jwtKey, adminAPIKey []byte,
 			r.Use(middleware.ThrottleBacklog(1, 100, 30*time.Second))
 			r.Get("/task-filters", hf.NoReqJsonResp(h.GetTaskFilters))
 			r.Get("/tasks/{taskId}", hf.NoReqJsonResp(h.GetTaskView))
-			r.Get("/tasks", hf.NoReqJsonResp(h.GetTaskList))
+			r.Get("/tasks", h.GetTaskList)
 		})
 
+		r.Get("/tasks/search", h.SearchTasks)
+
 		r.Group(func(r chi.Router) {
 			r.Use(auth.HttpAllowOnlyAdmins(adminAPIKey))
 
@@ -97,6 +101,21 @@ func (h *taskHttpHandler) RegisterRoutes(r *chi.Mux, jwtKey, adminAPIKey []byte,
 			})
 
 			r.Delete("/tasks/{taskId}", hf.NoReqNoResp(h.DeleteTask))
+			r.Put("/tasks/{taskId}/visibility", hf.JsonReqNoResp(h.PutVisibility))
+
+			r.Get("/tasks/{taskId}/solution-report", hf.NoReqJsonResp(h.GetSolutionReport))
+			r.Post("/tasks/{taskId}/solution-report", hf.NoReqNoResp(h.CheckSolutions))
+
+			r.Get("/tasks/{taskId}/revisions", hf.NoReqJsonResp(h.ListTaskRevisions))
+			r.Get("/tasks/{taskId}/revisions/diff", h.DiffTaskRevisions)
+			r.Post("/tasks/{taskId}/revisions/{revision}/rollback", hf.NoReqJsonResp(h.RollbackTask))
+
+			r.Put("/tasks/{taskId}/limits", hf.JsonReqJsonResp(h.PutLimits))
+			r.Put("/tasks/{taskId}/checker", hf.JsonReqJsonResp(h.PutChecker))
+			r.Put("/tasks/{taskId}/scoring", hf.JsonReqJsonResp(h.PutScoring))
+			r.Post("/tasks/{taskId}/tests", h.AddTest)
+			r.Put("/tasks/{taskId}/tests/{testId}", h.ReplaceTest)
+			r.Delete("/tasks/{taskId}/tests/{testId}", hf.NoReqJsonResp(h.DeleteTest))
 
 			r.Patch("/tasks/{taskId}/statements/{langIso639}", hf.JsonReqNoResp(h.PutStatement))
 			r.Post("/tasks/{taskId}/images", h.UploadStatementImage)
@@ -108,6 +127,24 @@ func (h *taskHttpHandler) RegisterRoutes(r *chi.Mux, jwtKey, adminAPIKey []byte,
 	})
 }
 
+// listCacheKey separates the admin filters,
+// which include unlisted tasks, from the public ones.
+func listCacheKey(includeUnlisted bool) string {
+	if includeUnlisted {
+		return "unlisted"
+	}
+	return ""
+}
+
+// invalidateTask drops cached responses that show the task.
+func (h *taskHttpHandler) invalidateTask(taskId string) {
+	h.getTaskViewCache.Delete(taskId)
+	h.getTaskListCache.Clear()
+	for _, includeUnlisted := range []bool{false, true} {
+		h.getTaskFiltersCache.Delete(listCacheKey(includeUnlisted))
+	}
+}
+
 func (h *taskHttpHandler) logger(ctx context.Context) *slog.Logger {
 	return ctxlog.FromContext(ctx).With("module", "task", "layer", "http")
 }
@@ -22,8 +22,15 @@ type UploadTaskResponse struct {
 }
 
 // UploadTask imports a TaskZip v1 archive from multipart field task_zip
-// and writes the created task ID as JSON.
+// and writes the task ID as JSON. Uploading an existing task creates
+// its next revision.
+// With format=polygon or format=cms the field holds a zipped Polygon
+// full package or CMS task directory instead.
 // Query parameter override_id, if set, replaces the archive's short ID.
+// With check_solutions=1 the bundled solutions are run in the background;
+// see GET /tasks/{taskId}/solution-report.
+// Query parameter visibility sets the visibility of a new task; it
+// defaults to draft when solutions are checked.
 func (h *taskHttpHandler) UploadTask(w http.ResponseWriter, r *http.Request) {
 	logger := h.logger(r.Context()).With("handler", "UploadTask")
 
@@ -49,14 +56,36 @@ func (h *taskHttpHandler) UploadTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "taskzip" && format != "polygon" && format != "cms" {
+		jsonresp.BadRequest(w, fmt.Sprintf("unknown format %q", format))
+		return
+	}
+
 	overrideId := r.URL.Query().Get("override_id")
+	var opts []srvc.ImportOption
+	if check := r.URL.Query().Get("check_solutions"); check == "1" || check == "true" {
+		opts = append(opts, srvc.WithSolutionChecks())
+	}
+	if visibility := r.URL.Query().Get("visibility"); visibility != "" {
+		opts = append(opts, srvc.WithVisibility(srvc.Visibility(visibility)))
+	}
 
-	createdId, importTaskErr := h.taskSrvc.ImportTaskFromZip(r.Context(), zipBytes, overrideId)
+	importTask := h.taskSrvc.ImportTaskFromZip
+	switch format {
+	case "polygon":
+		importTask = h.taskSrvc.ImportTaskFromPolygon
+	case "cms":
+		importTask = h.taskSrvc.ImportTaskFromCMS
+	}
+	createdId, importTaskErr := importTask(r.Context(), zipBytes, overrideId, opts...)
 	if importTaskErr != nil {
 		jsonresp.WriteError(w, importTaskErr)
 		return
 	}
 
+	h.invalidateTask(createdId)
+
 	_ = jsonresp.Success(w, UploadTaskResponse{TaskId: createdId})
 }
 
@@ -117,8 +146,7 @@ func (h *taskHttpHandler) UploadStatementImage(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	h.getTaskViewCache.Delete(taskId)
-	h.getTaskListCache.Delete("")
+	h.invalidateTask(taskId)
 
 	err = jsonresp.Success(w, uri)
 	if err != nil {
@@ -206,8 +234,7 @@ func (h *taskHttpHandler) UploadIllustration(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	h.getTaskViewCache.Delete(taskId)
-	h.getTaskListCache.Delete("")
+	h.invalidateTask(taskId)
 
 	err = jsonresp.Success(w, map[string]string{"object_key": objectKey})
 	if err != nil {
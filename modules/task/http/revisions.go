@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/programme-lv/backend/common/jsonresp"
+)
+
+// TaskRevision is one entry of GET /tasks/{taskId}/revisions.
+type TaskRevision struct {
+	Revision  int        `json:"revision"`
+	CreatedAt *time.Time `json:"created_at"`
+	Current   bool       `json:"current"`
+}
+
+// RevisionDiff is the JSON body of GET /tasks/{taskId}/revisions/diff.
+type RevisionDiff struct {
+	From    int              `json:"from"`
+	To      int              `json:"to"`
+	Changes []RevisionChange `json:"changes"`
+}
+
+type RevisionChange struct {
+	Field  string `json:"field"`
+	Detail string `json:"detail"`
+}
+
+// RollbackTaskResponse is the JSON body returned after a rollback.
+type RollbackTaskResponse struct {
+	Revision int `json:"revision"`
+}
+
+// ListTaskRevisions lists the content revisions of a task, oldest first.
+func (h *taskHttpHandler) ListTaskRevisions(ctx context.Context) ([]TaskRevision, jsonresp.HttpStatusCoder) {
+	taskId := chi.URLParamFromCtx(ctx, "taskId")
+	revisions, err := h.taskSrvc.ListTaskRevisions(ctx, taskId)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]TaskRevision, len(revisions))
+	for i, rev := range revisions {
+		res[i] = TaskRevision{Revision: rev.Revision, Current: rev.Current}
+		if !rev.CreatedAt.IsZero() {
+			createdAt := rev.CreatedAt
+			res[i].CreatedAt = &createdAt
+		}
+	}
+	return res, nil
+}
+
+// DiffTaskRevisions lists what changed between revisions
+// given by query parameters from and to.
+func (h *taskHttpHandler) DiffTaskRevisions(w http.ResponseWriter, r *http.Request) {
+	taskId := chi.URLParam(r, "taskId")
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		jsonresp.BadRequest(w, "from must be a revision number")
+		return
+	}
+	to, err := strconv.Atoi(r.URL.Query().Get("to"))
+	if err != nil {
+		jsonresp.BadRequest(w, "to must be a revision number")
+		return
+	}
+
+	diff, diffErr := h.taskSrvc.DiffTaskRevisions(r.Context(), taskId, from, to)
+	if diffErr != nil {
+		jsonresp.WriteError(w, diffErr)
+		return
+	}
+	res := RevisionDiff{From: diff.From, To: diff.To, Changes: make([]RevisionChange, len(diff.Changes))}
+	for i, c := range diff.Changes {
+		res.Changes[i] = RevisionChange{Field: c.Field, Detail: c.Detail}
+	}
+	_ = jsonresp.Success(w, res)
+}
+
+// RollbackTask restores revision {revision} as a new revision
+// and invalidates cached views.
+func (h *taskHttpHandler) RollbackTask(ctx context.Context) (*RollbackTaskResponse, jsonresp.HttpStatusCoder) {
+	taskId := chi.URLParamFromCtx(ctx, "taskId")
+	revision, err := strconv.Atoi(chi.URLParamFromCtx(ctx, "revision"))
+	if err != nil {
+		return nil, jsonresp.ErrHttpBadRequest.WithMsg("revision must be a number")
+	}
+
+	next, rollbackErr := h.taskSrvc.RollbackTask(ctx, taskId, revision)
+	if rollbackErr != nil {
+		return nil, rollbackErr
+	}
+
+	h.invalidateTask(taskId)
+	return &RollbackTaskResponse{Revision: next}, nil
+}
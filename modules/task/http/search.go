@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/programme-lv/backend/common/jsonresp"
+	"github.com/programme-lv/backend/modules/user/auth"
+)
+
+// TaskSearchHit is one result of GET /tasks/search.
+type TaskSearchHit struct {
+	ShortId  string  `json:"short_id"`
+	FullName string  `json:"full_name"`
+	Lang     string  `json:"lang"`
+	Rank     float64 `json:"rank"`
+	// Snippet is HTML-escaped, with matches wrapped in <mark>
+	Snippet string `json:"snippet"`
+}
+
+// SearchTasks runs a full-text search for query parameter q
+// over listed tasks, or over all tasks for admins.
+func (h *taskHttpHandler) SearchTasks(w http.ResponseWriter, r *http.Request) {
+	hits, err := h.taskSrvc.SearchTasks(r.Context(), r.URL.Query().Get("q"), auth.IsAdmin(r.Context()))
+	if err != nil {
+		jsonresp.WriteError(w, err)
+		return
+	}
+	res := make([]TaskSearchHit, len(hits))
+	for i, hit := range hits {
+		res[i] = TaskSearchHit{
+			ShortId:  hit.ShortId,
+			FullName: hit.Name(),
+			Lang:     hit.Lang,
+			Rank:     hit.Rank,
+			Snippet:  hit.Snippet,
+		}
+	}
+	_ = jsonresp.Success(w, res)
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/common/jsonresp"
+	"github.com/programme-lv/backend/modules/task/srvc"
+)
+
+// SolutionReport is the JSON body of GET /tasks/{taskId}/solution-report.
+type SolutionReport struct {
+	TaskId      string          `json:"task_id"`
+	StartedAt   time.Time       `json:"started_at"`
+	FinishedAt  *time.Time      `json:"finished_at"`
+	Flagged     bool            `json:"flagged"`
+	Interrupted bool            `json:"interrupted"`
+	Solutions   []SolutionCheck `json:"solutions"`
+}
+
+// SolutionCheck is the graded run of one bundled solution.
+type SolutionCheck struct {
+	Fname         string     `json:"fname"`
+	LangId        string     `json:"lang_id"`
+	ExecUuid      *uuid.UUID `json:"exec_uuid"`
+	Status        string     `json:"status"`
+	SubtaskPoints []float64  `json:"subtask_points"`
+	ReceivedScore float64    `json:"received_score"`
+	PossibleScore int        `json:"possible_score"`
+	Problems      []string   `json:"problems"`
+}
+
+// GetSolutionReport returns the latest check of the task's bundled solutions.
+func (h *taskHttpHandler) GetSolutionReport(ctx context.Context) (*SolutionReport, jsonresp.HttpStatusCoder) {
+	taskId := chi.URLParamFromCtx(ctx, "taskId")
+	report, err := h.taskSrvc.GetSolutionReport(ctx, taskId)
+	if err != nil {
+		return nil, err
+	}
+	res := &SolutionReport{
+		TaskId: report.TaskId, StartedAt: report.StartedAt,
+		FinishedAt: report.FinishedAt, Flagged: report.Flagged,
+		Interrupted: report.Interrupted,
+		Solutions:   make([]SolutionCheck, len(report.Solutions)),
+	}
+	for i, check := range report.Solutions {
+		res.Solutions[i] = mapSolutionCheck(check)
+	}
+	return res, nil
+}
+
+// CheckSolutions starts a new check of the task's bundled solutions.
+func (h *taskHttpHandler) CheckSolutions(ctx context.Context) jsonresp.HttpStatusCoder {
+	taskId := chi.URLParamFromCtx(ctx, "taskId")
+	if err := h.taskSrvc.CheckSolutions(ctx, taskId); err != nil {
+		return err
+	}
+	return nil
+}
+
+func mapSolutionCheck(check srvc.SolutionCheck) SolutionCheck {
+	return SolutionCheck{
+		Fname: check.Fname, LangId: check.LangId, ExecUuid: check.ExecUuid,
+		Status: check.Status, SubtaskPoints: check.SubtaskPoints,
+		ReceivedScore: check.ReceivedScore, PossibleScore: check.PossibleScore,
+		Problems: check.Problems,
+	}
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"time"
 
 	"github.com/programme-lv/backend/modules/task/srvc"
 )
@@ -18,6 +19,24 @@ type TaskPreview struct {
 	OriginDivisions  []string           `json:"origin_divisions"`
 	OriginNote       string             `json:"origin_note"`
 	OriginNoteShort  string             `json:"origin_note_short"`
+	ProblemTags      []string           `json:"problem_tags"`
+	SolveCount       int                `json:"solve_count"`
+	Visibility       string             `json:"visibility"`
+	PublishAt        *time.Time         `json:"publish_at"`
+}
+
+// TaskListPage is the JSON body of GET /tasks.
+type TaskListPage struct {
+	Page       []TaskPreview `json:"page"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+// Pagination matches the pagination metadata of GET /subm.
+type Pagination struct {
+	Total   int  `json:"total"`
+	Offset  int  `json:"offset"`
+	Limit   int  `json:"limit"`
+	HasMore bool `json:"hasMore"`
 }
 
 // TaskFilterTree is the JSON body of GET /task-filters.
@@ -92,6 +111,14 @@ type StatementImage struct {
 	SzInBytes int    `json:"sz_in_bytes"`
 }
 
+// Attachment is a file handed to contestants, such as a grader header.
+// Path is relative to the task's attachment directory.
+type Attachment struct {
+	Path      string `json:"path"`
+	HttpUrl   string `json:"http_url"`
+	SzInBytes int    `json:"sz_in_bytes"`
+}
+
 // IllustrationImage is the image shown next to the task in lists and the task view.
 type IllustrationImage struct {
 	HttpUrl   string `json:"http_url"`
@@ -114,11 +141,14 @@ type Task struct {
 	DifficultyRating     *int               `json:"difficulty_rating"`
 	DefaultMDStatement   MdStatement        `json:"default_md_statement"`
 	StatementImages      []StatementImage   `json:"statement_images"`
+	Attachments          []Attachment       `json:"attachments"`
 	Examples             []Example          `json:"examples"`
 	OriginNotes          map[string]string  `json:"origin_notes"`
 	VisibleInputSubtasks []VisInputSubtask  `json:"visible_input_subtasks"`
 	StatementSubtasks    []SubtaskOverview  `json:"statement_subtasks"`
 	TestingType          string             `json:"testing_type"`
+	Visibility           string             `json:"visibility"`
+	PublishAt            *time.Time         `json:"publish_at"`
 }
 
 // SubtaskOverview is a scoring group shown in the statement.
@@ -244,11 +274,14 @@ func (h *taskHttpHandler) mapTaskResponse(task srvc.Task) Task {
 		DifficultyRating:     difficultyRating,
 		DefaultMDStatement:   defaultMdStatement,
 		StatementImages:      h.mapTaskStatementImages(task.MdImages),
+		Attachments:          h.mapTaskAttachments(task.Attachments),
 		Examples:             mapTaskExamples(task.Examples),
 		OriginNotes:          originNotesAsAMap,
 		VisibleInputSubtasks: visInputSubtasks,
 		StatementSubtasks:    subtasks,
 		TestingType:          testingType,
+		Visibility:           string(task.VisibilityAt(time.Now())),
+		PublishAt:            task.PublishAt,
 	}
 	return response
 }
@@ -276,6 +309,10 @@ func (h *taskHttpHandler) mapTaskPreview(preview srvc.TaskPreview) TaskPreview {
 	if divisions == nil {
 		divisions = []string{}
 	}
+	tags := preview.ProblemTags
+	if tags == nil {
+		tags = []string{}
+	}
 	return TaskPreview{
 		ShortId:          preview.ShortId,
 		FullName:         preview.DefaultFullName(),
@@ -287,6 +324,10 @@ func (h *taskHttpHandler) mapTaskPreview(preview srvc.TaskPreview) TaskPreview {
 		OriginDivisions:  divisions,
 		OriginNote:       preview.OriginNote,
 		OriginNoteShort:  preview.OriginNoteShort,
+		ProblemTags:      tags,
+		SolveCount:       preview.SolveCount,
+		Visibility:       string(preview.VisibilityAt(time.Now())),
+		PublishAt:        preview.PublishAt,
 	}
 }
 
@@ -325,6 +366,22 @@ func mapFilterTree(tree srvc.FilterTree) TaskFilterTree {
 	return TaskFilterTree{Olympiads: olympiads}
 }
 
+func (h *taskHttpHandler) mapTaskAttachments(attachments []srvc.Attachment) []Attachment {
+	response := make([]Attachment, len(attachments))
+	for i, attachment := range attachments {
+		httpUrl, err := h.taskSrvc.GetHttpUrlForAttachment(context.TODO(), attachment.ObjectKey)
+		if err != nil {
+			httpUrl = ""
+		}
+		response[i] = Attachment{
+			Path:      attachment.Path,
+			HttpUrl:   httpUrl,
+			SzInBytes: attachment.SzInBytes,
+		}
+	}
+	return response
+}
+
 func (h *taskHttpHandler) mapTaskStatementImages(images []srvc.StatementImage) []StatementImage {
 	response := make([]StatementImage, len(images))
 	for i, image := range images {
@@ -0,0 +1,431 @@
+// Package polygon converts Codeforces Polygon problem packages
+// into the TaskZip model, so that they can be imported like TaskZip archives.
+//
+// Only full packages can be read, because standard packages do not
+// contain generated tests. Parts the backend cannot represent, such as
+// interactors or test group dependencies, are rejected with [ErrUnsupported].
+package polygon
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/programme-lv/backend/modules/task/taskzip"
+)
+
+// ErrUnsupported wraps errors about package parts the backend cannot represent.
+var ErrUnsupported = errors.New("unsupported Polygon package")
+
+// Package is a Polygon package converted to the TaskZip model.
+type Package struct {
+	// Task is not validated, because the caller may replace its ID;
+	// run taskzip.Validate before using it.
+	Task taskzip.Task
+	// Tags are the problem tags as slugs.
+	Tags []string
+}
+
+// languages maps Polygon statement languages to ISO 639-1 codes.
+var languages = map[string]string{
+	"english": "en", "latvian": "lv", "russian": "ru", "ukrainian": "uk",
+	"lithuanian": "lt", "estonian": "et", "polish": "pl", "german": "de",
+	"french": "fr", "spanish": "es", "italian": "it", "portuguese": "pt",
+	"romanian": "ro", "hungarian": "hu", "czech": "cs", "finnish": "fi",
+	"swedish": "sv", "turkish": "tr", "chinese": "zh", "japanese": "ja",
+	"korean": "ko", "kazakh": "kk", "uzbek": "uz", "armenian": "hy",
+	"georgian": "ka", "azerbaijani": "az", "belarusian": "be",
+}
+
+// sections maps statement-section files to TaskZip statement headings.
+var sections = []struct{ file, heading string }{
+	{"legend.tex", "Story"},
+	{"input.tex", "Input"},
+	{"output.tex", "Output"},
+	{"interaction.tex", "Communication"},
+	{"scoring.tex", "Scoring"},
+	{"notes.tex", "Notes"},
+}
+
+// Read converts a zipped Polygon package.
+func Read(data []byte) (Package, error) {
+	files, err := taskzip.ReadFiles(data, "problem.xml")
+	if err != nil {
+		return Package{}, err
+	}
+	var problem problemXML
+	if err := xml.Unmarshal(files["problem.xml"], &problem); err != nil {
+		return Package{}, fmt.Errorf("problem.xml: %w", err)
+	}
+	if problem.Interactor != nil {
+		return Package{}, fmt.Errorf("%w: interactive problems", ErrUnsupported)
+	}
+
+	task := taskzip.Task{
+		Version:         1,
+		ID:              problem.ShortName,
+		Name:            map[string]string{},
+		Statements:      map[string][]byte{},
+		StatementImages: map[string][]byte{},
+	}
+	testset, err := mainTestset(problem)
+	if err != nil {
+		return Package{}, err
+	}
+	task.Testing = taskzip.Testing{
+		Type:   "checker",
+		CPUMs:  testset.TimeLimitMs,
+		MemMiB: uint32(testset.MemoryBytes >> 20),
+	}
+	if err := readChecker(problem, files, &task); err != nil {
+		return Package{}, err
+	}
+	if err := readStatements(problem, files, &task); err != nil {
+		return Package{}, err
+	}
+	groupSubtasks, err := readTests(testset, files, &task)
+	if err != nil {
+		return Package{}, err
+	}
+	if err := readSolutions(problem, files, groupSubtasks, &task); err != nil {
+		return Package{}, err
+	}
+
+	var tags []string
+	for _, tag := range problem.Tags {
+		if slug := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(tag.Value)), " ", "-"); slug != "" {
+			tags = append(tags, slug)
+		}
+	}
+	return Package{Task: task, Tags: tags}, nil
+}
+
+func mainTestset(problem problemXML) (testsetXML, error) {
+	for _, testset := range problem.Testsets {
+		if testset.Name == "tests" {
+			return testset, nil
+		}
+	}
+	if len(problem.Testsets) == 1 {
+		return problem.Testsets[0], nil
+	}
+	return testsetXML{}, errors.New(`testset "tests" missing`)
+}
+
+func readChecker(problem problemXML, files map[string][]byte, task *taskzip.Task) error {
+	if problem.Checker == nil {
+		return errors.New("checker missing")
+	}
+	if problem.Checker.Type != "" && problem.Checker.Type != "testlib" {
+		return fmt.Errorf("%w: %s checker", ErrUnsupported, problem.Checker.Type)
+	}
+	checker, ok := files[problem.Checker.Source.Path]
+	if !ok {
+		return fmt.Errorf("checker source %s missing", problem.Checker.Source.Path)
+	}
+	task.Checker = taskzip.LF(checker)
+	return nil
+}
+
+// readStatements renders the statement sections of every language
+// with a LaTeX statement as a TaskZip markdown statement.
+func readStatements(problem problemXML, files map[string][]byte, task *taskzip.Task) error {
+	for _, name := range problem.Names {
+		if lang, ok := languages[name.Language]; ok && name.Value != "" {
+			task.Name[lang] = name.Value
+		}
+	}
+	for _, statement := range problem.Statements {
+		if statement.Type != "application/x-tex" {
+			continue
+		}
+		lang, ok := languages[statement.Language]
+		if !ok {
+			return fmt.Errorf("%w: statement language %q", ErrUnsupported, statement.Language)
+		}
+		dir := "statement-sections/" + statement.Language + "/"
+		if _, ok := files[dir+"legend.tex"]; !ok {
+			return fmt.Errorf("%w: %s statement without statement-sections", ErrUnsupported, statement.Language)
+		}
+		if _, ok := task.Name[lang]; !ok {
+			if name, ok := files[dir+"name.tex"]; ok {
+				task.Name[lang] = strings.TrimSpace(texToMarkdown(string(name)))
+			}
+		}
+		var parts []string
+		for _, section := range sections {
+			data, ok := files[dir+section.file]
+			if !ok {
+				continue
+			}
+			if text := texToMarkdown(string(data)); text != "" {
+				underline := strings.Repeat("-", len([]rune(section.heading)))
+				parts = append(parts, section.heading+"\n"+underline+"\n"+text)
+			}
+		}
+		task.Statements[lang] = []byte(strings.Join(parts, "\n\n") + "\n")
+		for name, data := range files {
+			base, ok := strings.CutPrefix(name, dir)
+			if !ok || strings.Contains(base, "/") || !isImage(base) {
+				continue
+			}
+			if prev, ok := task.StatementImages[base]; ok && string(prev) != string(data) {
+				return fmt.Errorf("%w: image %s differs between statements", ErrUnsupported, base)
+			}
+			task.StatementImages[base] = data
+		}
+	}
+	if len(task.Statements) == 0 {
+		return errors.New("no LaTeX statement")
+	}
+	return nil
+}
+
+// test is one test of the main testset with its answer.
+type test struct {
+	taskzip.Test
+	sample bool
+	points float64
+	group  string
+}
+
+// unit is a Polygon test group, or all tests when there are none.
+type unit struct {
+	name      string
+	eachTest  bool
+	points    float64
+	hasPoints bool
+	deps      []string
+	tests     []int
+}
+
+// readTests reads tests and examples and maps points and groups onto
+// TaskZip test groups, one subtask per Polygon group. It returns the
+// 1-based subtask of each scored group.
+func readTests(testset testsetXML, files map[string][]byte, task *taskzip.Task) (map[string]uint32, error) {
+	tests := make([]test, len(testset.Tests))
+	for i, t := range testset.Tests {
+		input, ok := files[fmt.Sprintf(testset.InputPattern, i+1)]
+		if !ok {
+			return nil, fmt.Errorf("%w: test %d input missing, build a full package", ErrUnsupported, i+1)
+		}
+		answer, ok := files[fmt.Sprintf(testset.AnswerPattern, i+1)]
+		if !ok {
+			return nil, fmt.Errorf("%w: test %d answer missing, build a full package", ErrUnsupported, i+1)
+		}
+		var points float64
+		if t.Points != "" {
+			var err error
+			if points, err = taskzip.ParsePoints(t.Points, ErrUnsupported); err != nil {
+				return nil, fmt.Errorf("test %d: %w", i+1, err)
+			}
+		}
+		tests[i] = test{
+			Test:   taskzip.Test{Input: taskzip.LF(input), Output: taskzip.LF(answer)},
+			sample: t.Sample, points: points, group: t.Group,
+		}
+		if t.Sample {
+			task.Examples = append(task.Examples, taskzip.Example{Input: tests[i].Input, Output: tests[i].Output})
+		}
+	}
+
+	units, err := testUnits(testset, tests)
+	if err != nil {
+		return nil, err
+	}
+	scored := false
+	for _, u := range units {
+		scored = scored || u.points > 0
+		for _, i := range u.tests {
+			scored = scored || tests[i].points > 0
+		}
+	}
+	if !scored {
+		for _, t := range tests {
+			task.Tests = append(task.Tests, t.Test)
+		}
+		return nil, nil
+	}
+	return mapScoring(units, tests, task)
+}
+
+// testUnits groups the tests by Polygon group in order of appearance.
+// Every group must be a contiguous run of tests.
+func testUnits(testset testsetXML, tests []test) ([]unit, error) {
+	grouped := false
+	for _, t := range tests {
+		grouped = grouped || t.group != ""
+	}
+	if !grouped {
+		all := unit{eachTest: true}
+		for i := range tests {
+			all.tests = append(all.tests, i)
+		}
+		return []unit{all}, nil
+	}
+	defs := make(map[string]groupXML, len(testset.Groups))
+	for _, g := range testset.Groups {
+		defs[g.Name] = g
+	}
+	var units []unit
+	index := map[string]int{}
+	for i, t := range tests {
+		if t.group == "" {
+			return nil, fmt.Errorf("%w: test %d has no group", ErrUnsupported, i+1)
+		}
+		k, seen := index[t.group]
+		if !seen {
+			def := defs[t.group]
+			u := unit{name: t.group, eachTest: def.PointsPolicy == "each-test"}
+			if def.Points != "" {
+				points, err := taskzip.ParsePoints(def.Points, ErrUnsupported)
+				if err != nil {
+					return nil, fmt.Errorf("group %s: %w", t.group, err)
+				}
+				u.points, u.hasPoints = points, true
+			}
+			for _, dep := range def.Dependencies {
+				u.deps = append(u.deps, dep.Group)
+			}
+			units = append(units, u)
+			k = len(units) - 1
+			index[t.group] = k
+		} else if last := units[k].tests[len(units[k].tests)-1]; last != i-1 {
+			return nil, fmt.Errorf("%w: group %s is not a contiguous range of tests", ErrUnsupported, t.group)
+		}
+		units[k].tests = append(units[k].tests, i)
+	}
+	return units, nil
+}
+
+func mapScoring(units []unit, tests []test, task *taskzip.Task) (map[string]uint32, error) {
+	dropped := map[string]bool{}
+	groupSubtasks := map[string]uint32{}
+	for _, u := range units {
+		for _, dep := range u.deps {
+			if !dropped[dep] {
+				return nil, fmt.Errorf("%w: group %s depends on group %s", ErrUnsupported, u.name, dep)
+			}
+		}
+		firstGroup := len(task.TestGroups) + 1
+		addGroup := func(testIDs []int, points float64) {
+			first := len(task.Tests) + 1
+			for _, i := range testIDs {
+				task.Tests = append(task.Tests, tests[i].Test)
+			}
+			task.TestGroups = append(task.TestGroups, taskzip.TestGroup{
+				ID: uint32(len(task.TestGroups) + 1), First: uint32(first),
+				Last: uint32(len(task.Tests)), Points: uint32(points),
+			})
+		}
+		if u.eachTest {
+			for _, i := range u.tests {
+				if tests[i].points > 0 {
+					addGroup([]int{i}, tests[i].points)
+				} else if !tests[i].sample {
+					return nil, fmt.Errorf("%w: test %d has no points", ErrUnsupported, i+1)
+				}
+			}
+		} else {
+			points := u.points
+			if !u.hasPoints {
+				for _, i := range u.tests {
+					points += tests[i].points
+				}
+			}
+			if points > 0 {
+				addGroup(u.tests, points)
+			} else {
+				for _, i := range u.tests {
+					if !tests[i].sample {
+						return nil, fmt.Errorf("%w: group %s has no points", ErrUnsupported, u.name)
+					}
+				}
+			}
+		}
+		if len(task.TestGroups) < firstGroup {
+			dropped[u.name] = true
+			continue
+		}
+		task.Subtasks = append(task.Subtasks, taskzip.Subtask{
+			Groups: fmt.Sprintf("%02d-%02d", firstGroup, len(task.TestGroups)),
+		})
+		groupSubtasks[u.name] = uint32(len(task.Subtasks))
+	}
+	if len(task.TestGroups) > 99 {
+		return nil, fmt.Errorf("%w: more than 99 scored test groups", ErrUnsupported)
+	}
+	return groupSubtasks, nil
+}
+
+// passVerdicts are extra-tag verdicts that decide whether a group passes.
+var passVerdicts = map[string]bool{
+	"OK": true, "WA": false, "TL": false, "ML": false, "RE": false, "PE": false, "RJ": false,
+}
+
+// readSolutions keeps solutions whose expected score is known:
+// main and accepted solutions earn every point, and rejected ones
+// pass the groups their extra tags mark OK. Other solutions are skipped.
+func readSolutions(problem problemXML, files map[string][]byte, groupSubtasks map[string]uint32, task *taskzip.Task) error {
+	for _, solution := range problem.Solutions {
+		data, ok := files[solution.Source.Path]
+		if !ok {
+			return fmt.Errorf("solution %s missing", solution.Source.Path)
+		}
+		res := taskzip.Solution{Filename: path.Base(solution.Source.Path), Data: taskzip.LF(data)}
+		switch solution.Tag {
+		case "main", "accepted":
+			for i := range task.Subtasks {
+				res.Subtasks = append(res.Subtasks, uint32(i+1))
+			}
+		default:
+			subtasks, ok := expectedSubtasks(solution.ExtraTags, groupSubtasks)
+			if !ok {
+				continue
+			}
+			res.Subtasks = subtasks
+			if len(subtasks) == 0 {
+				zero := uint32(0)
+				res.Score = &zero
+			}
+		}
+		task.Solutions = append(task.Solutions, res)
+	}
+	return nil
+}
+
+// expectedSubtasks is ok only when every scored group has a pass or fail verdict.
+func expectedSubtasks(tags []extraTagXML, groupSubtasks map[string]uint32) ([]uint32, bool) {
+	if len(groupSubtasks) == 0 {
+		return nil, false
+	}
+	verdicts := map[string]bool{}
+	for _, tag := range tags {
+		if pass, ok := passVerdicts[tag.Tag]; ok {
+			verdicts[tag.Group] = pass
+		}
+	}
+	passed := make([]uint32, 0, len(groupSubtasks))
+	for group, subtask := range groupSubtasks {
+		pass, ok := verdicts[group]
+		if !ok {
+			return nil, false
+		}
+		if pass {
+			passed = append(passed, subtask)
+		}
+	}
+	slices.Sort(passed)
+	return passed, true
+}
+
+func isImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".webp", ".svg":
+		return true
+	}
+	return false
+}
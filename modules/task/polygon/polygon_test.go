@@ -0,0 +1,231 @@
+package polygon
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/programme-lv/backend/modules/task/taskzip"
+)
+
+const problemXMLForTest = `<?xml version="1.0" encoding="utf-8"?>
+<problem revision="7" short-name="sum-pairs">
+  <names>
+    <name language="english" value="Sum Pairs"/>
+    <name language="latvian" value="Pāru summas"/>
+  </names>
+  <statements>
+    <statement charset="UTF-8" language="english" mathjax="true" path="statements/english/problem.tex" type="application/x-tex"/>
+    <statement charset="UTF-8" language="english" path="statements/.pdf/english/problem.pdf" type="application/pdf"/>
+  </statements>
+  <judging cpu-name="Intel" cpu-speed="3600" input-file="" output-file="">
+    <testset name="tests">
+      <time-limit>2000</time-limit>
+      <memory-limit>268435456</memory-limit>
+      <test-count>5</test-count>
+      <input-path-pattern>tests/%02d</input-path-pattern>
+      <answer-path-pattern>tests/%02d.a</answer-path-pattern>
+      <tests>
+        <test method="manual" sample="true" group="0" points="0"/>
+        <test method="generated" group="1" points="0"/>
+        <test method="generated" group="1" points="0"/>
+        <test method="generated" group="2" points="35"/>
+        <test method="generated" group="2" points="35"/>
+      </tests>
+      <groups>
+        <group feedback-policy="complete" name="0" points="0" points-policy="complete-group"/>
+        <group feedback-policy="icpc" name="1" points="30" points-policy="complete-group">
+          <dependencies><dependency group="0"/></dependencies>
+        </group>
+        <group feedback-policy="points" name="2" points-policy="each-test"/>
+      </groups>
+    </testset>
+  </judging>
+  <assets>
+    <checker name="std::ncmp.cpp" type="testlib">
+      <source path="files/check.cpp" type="cpp.g++17"/>
+    </checker>
+    <solutions>
+      <solution tag="main"><source path="solutions/main.cpp" type="cpp.g++17"/></solution>
+      <solution tag="wrong-answer">
+        <source path="solutions/small.py" type="python.3"/>
+        <extra-tags>
+          <extra-tag group="0" tag="OK"/>
+          <extra-tag group="1" tag="OK"/>
+          <extra-tag group="2" tag="WA"/>
+        </extra-tags>
+      </solution>
+      <solution tag="time-limit-exceeded"><source path="solutions/slow.cpp" type="cpp.g++17"/></solution>
+    </solutions>
+  </assets>
+  <tags>
+    <tag value="greedy"/>
+    <tag value="Two Pointers"/>
+  </tags>
+</problem>
+`
+
+func polygonFilesForTest() map[string][]byte {
+	files := map[string][]byte{
+		"sum-pairs-7$linux/problem.xml":                           []byte(problemXMLForTest),
+		"sum-pairs-7$linux/files/check.cpp":                       []byte("#include \"testlib.h\"\r\n"),
+		"sum-pairs-7$linux/solutions/main.cpp":                    []byte("int main() {}\n"),
+		"sum-pairs-7$linux/solutions/small.py":                    []byte("print(0)\n"),
+		"sum-pairs-7$linux/solutions/slow.cpp":                    []byte("int main() { for (;;); }\n"),
+		"sum-pairs-7$linux/statement-sections/english/name.tex":   []byte("Sum Pairs"),
+		"sum-pairs-7$linux/statement-sections/english/legend.tex": []byte("Find \\textbf{two} numbers $a~b$ --- quickly. % todo\n\n\\includegraphics[width=5cm]{pairs.png}\n"),
+		"sum-pairs-7$linux/statement-sections/english/input.tex":  []byte("The first line contains:\n\\begin{itemize}\n\\item $n$ ($1 \\le n \\le 10^5$);\n\\item {\\bf k}.\n\\end{itemize}\n"),
+		"sum-pairs-7$linux/statement-sections/english/output.tex": []byte("Print the answer.\n"),
+		"sum-pairs-7$linux/statement-sections/english/notes.tex":  []byte(""),
+		"sum-pairs-7$linux/statement-sections/english/pairs.png":  []byte("png"),
+		"sum-pairs-7$linux/statements/english/problem.tex":        []byte("\\begin{problem}\n"),
+		"sum-pairs-7$linux/statements/.pdf/english/problem.pdf":   []byte("%PDF"),
+	}
+	for i := 1; i <= 5; i++ {
+		name := "sum-pairs-7$linux/tests/0" + string(rune('0'+i))
+		files[name] = []byte(strings.Repeat("1 ", i) + "\r\n")
+		files[name+".a"] = []byte("1\n")
+	}
+	return files
+}
+
+func zipForTest(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	pkg, err := Read(zipForTest(t, polygonFilesForTest()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := pkg.Task
+	if err := taskzip.Validate(&task); err != nil {
+		t.Fatalf("converted task is not a valid TaskZip: %v", err)
+	}
+	if task.ID != "sum-pairs" || task.Name["en"] != "Sum Pairs" || task.Name["lv"] != "Pāru summas" {
+		t.Fatalf("unexpected id or names: %q %v", task.ID, task.Name)
+	}
+	if task.Testing != (taskzip.Testing{Type: "checker", CPUMs: 2000, MemMiB: 256}) {
+		t.Fatalf("unexpected testing: %+v", task.Testing)
+	}
+	if string(task.Checker) != "#include \"testlib.h\"\n" {
+		t.Fatalf("unexpected checker: %q", task.Checker)
+	}
+
+	wantStatement := "Story\n-----\nFind **two** numbers $a~b$ — quickly.\n\n![](pairs.png)\n\n" +
+		"Input\n-----\nThe first line contains:\n\n- $n$ ($1 \\le n \\le 10^5$);\n- **k**.\n\n" +
+		"Output\n------\nPrint the answer.\n"
+	if got := string(task.Statements["en"]); got != wantStatement {
+		t.Fatalf("statement mismatch:\n%s\nwant:\n%s", got, wantStatement)
+	}
+	if string(task.StatementImages["pairs.png"]) != "png" {
+		t.Fatalf("statement image missing: %v", task.StatementImages)
+	}
+
+	if len(task.Examples) != 1 || string(task.Examples[0].Input) != "1 \n" {
+		t.Fatalf("unexpected examples: %+v", task.Examples)
+	}
+	if len(task.Tests) != 4 || string(task.Tests[0].Input) != "1 1 \n" {
+		t.Fatalf("sample group should be dropped from scored tests: %d tests", len(task.Tests))
+	}
+	wantGroups := []taskzip.TestGroup{
+		{ID: 1, First: 1, Last: 2, Points: 30},
+		{ID: 2, First: 3, Last: 3, Points: 35},
+		{ID: 3, First: 4, Last: 4, Points: 35},
+	}
+	if !reflect.DeepEqual(task.TestGroups, wantGroups) {
+		t.Fatalf("unexpected groups: %+v", task.TestGroups)
+	}
+	wantSubtasks := []taskzip.Subtask{{Groups: "01-01"}, {Groups: "02-03"}}
+	if !reflect.DeepEqual(task.Subtasks, wantSubtasks) {
+		t.Fatalf("unexpected subtasks: %+v", task.Subtasks)
+	}
+
+	if len(task.Solutions) != 2 {
+		t.Fatalf("expected main and wrong-answer solutions, got %d", len(task.Solutions))
+	}
+	if sol := task.Solutions[0]; sol.Filename != "main.cpp" || !reflect.DeepEqual(sol.Subtasks, []uint32{1, 2}) {
+		t.Fatalf("unexpected main solution: %+v", sol)
+	}
+	if sol := task.Solutions[1]; sol.Filename != "small.py" || !reflect.DeepEqual(sol.Subtasks, []uint32{1}) {
+		t.Fatalf("unexpected partial solution: %+v", sol)
+	}
+	if !reflect.DeepEqual(pkg.Tags, []string{"greedy", "two-pointers"}) {
+		t.Fatalf("unexpected tags: %v", pkg.Tags)
+	}
+}
+
+func TestReadUnsupported(t *testing.T) {
+	tests := map[string]func(files map[string][]byte){
+		"interactor": func(files map[string][]byte) {
+			xml := strings.Replace(problemXMLForTest, "<solutions>",
+				`<interactor><source path="files/interactor.cpp" type="cpp.g++17"/></interactor><solutions>`, 1)
+			files["sum-pairs-7$linux/problem.xml"] = []byte(xml)
+		},
+		"standard package": func(files map[string][]byte) {
+			delete(files, "sum-pairs-7$linux/tests/04")
+		},
+		"fractional points": func(files map[string][]byte) {
+			xml := strings.Replace(problemXMLForTest, `group="2" points="35"/>`, `group="2" points="35.5"/>`, 1)
+			files["sum-pairs-7$linux/problem.xml"] = []byte(xml)
+		},
+		"dependency": func(files map[string][]byte) {
+			xml := strings.Replace(problemXMLForTest, `points-policy="each-test"/>`,
+				`points-policy="each-test"><dependencies><dependency group="1"/></dependencies></group>`, 1)
+			files["sum-pairs-7$linux/problem.xml"] = []byte(xml)
+		},
+		"scattered group": func(files map[string][]byte) {
+			xml := strings.Replace(problemXMLForTest, `<test method="generated" group="1" points="0"/>
+        <test method="generated" group="1" points="0"/>`, `<test method="generated" group="1" points="0"/>
+        <test method="generated" group="2" points="0"/>`, 1)
+			xml = strings.Replace(xml, `<test method="generated" group="2" points="35"/>`, `<test method="generated" group="1" points="35"/>`, 1)
+			files["sum-pairs-7$linux/problem.xml"] = []byte(xml)
+		},
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			files := polygonFilesForTest()
+			mutate(files)
+			_, err := Read(zipForTest(t, files))
+			if !errors.Is(err, ErrUnsupported) {
+				t.Fatalf("expected ErrUnsupported, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTexToMarkdown(t *testing.T) {
+	tests := []struct{ tex, want string }{
+		{"``quoted'' <<guillemets>>", "\"quoted\" «guillemets»"},
+		{"\\emph{a} and {\\it b} and \\texttt{c}", "*a* and *b* and `c`"},
+		{"100\\% sure % comment", "100% sure"},
+		{"$x--y$ -- $$a~b$$", "$x--y$ – $$a~b$$"},
+		{"\\begin{verbatim}\n1 2\n\\end{verbatim}", "```\n1 2\n```"},
+		{"\\begin{enumerate}\n\\item one\n\\begin{itemize}\n\\item two\n\\end{itemize}\n\\end{enumerate}", "1. one\n\n  - two"},
+		{"see \\href{https://x.lv}{here}", "see [here](https://x.lv)"},
+		{"\\unknown{cmd}", "\\unknown{cmd}"},
+	}
+	for _, tt := range tests {
+		if got := texToMarkdown(tt.tex); got != tt.want {
+			t.Errorf("texToMarkdown(%q) = %q, want %q", tt.tex, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package polygon
+
+import (
+	"regexp"
+	"strings"
+)
+
+// texToMarkdown converts the LaTeX subset used in Polygon statement
+// sections to markdown. Math between $ signs is kept for KaTeX,
+// verbatim blocks become code blocks, and commands it does not know
+// are left as they are.
+func texToMarkdown(tex string) string {
+	tex = strings.ReplaceAll(tex, "\r\n", "\n")
+	var out strings.Builder
+	for {
+		loc := verbatimRE.FindStringSubmatchIndex(tex)
+		if loc == nil {
+			convertText(&out, convertLists(stripComments(tex)))
+			break
+		}
+		convertText(&out, convertLists(stripComments(tex[:loc[0]])))
+		code := strings.TrimSuffix(tex[loc[2]:loc[3]], "\n")
+		out.WriteString("\n\n```\n" + code + "\n```\n\n")
+		tex = tex[loc[1]:]
+	}
+	return tidyMarkdown(out.String())
+}
+
+var blankLinesRE = regexp.MustCompile(`\n{3,}`)
+
+func tidyMarkdown(md string) string {
+	lines := strings.Split(md, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	md = strings.Join(lines, "\n")
+	return strings.Trim(blankLinesRE.ReplaceAllString(md, "\n\n"), "\n")
+}
+
+// stripComments drops % comments. An escaped \% is kept.
+func stripComments(tex string) string {
+	lines := strings.Split(tex, "\n")
+	for i, line := range lines {
+		for j := 0; j < len(line); j++ {
+			if line[j] == '\\' {
+				j++
+				continue
+			}
+			if line[j] == '%' {
+				line = line[:j]
+				break
+			}
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+var verbatimRE = regexp.MustCompile(`(?s)\\begin\{verbatim\}\n?(.*?)\\end\{verbatim\}`)
+
+var (
+	listEnvRE = regexp.MustCompile(`\\(begin|end)\{(itemize|enumerate)\}`)
+	itemRE    = regexp.MustCompile(`^\s*\\item\s*`)
+)
+
+// convertLists turns \item lines of itemize and enumerate into
+// markdown list items, indented by nesting depth.
+func convertLists(tex string) string {
+	var kinds []string
+	var out []string
+	for _, line := range strings.Split(tex, "\n") {
+		envs := listEnvRE.FindAllStringSubmatch(line, -1)
+		rest := strings.TrimSpace(listEnvRE.ReplaceAllString(line, ""))
+		for _, env := range envs {
+			if env[1] == "begin" {
+				kinds = append(kinds, env[2])
+			} else if len(kinds) > 0 {
+				kinds = kinds[:len(kinds)-1]
+			}
+		}
+		if len(envs) > 0 && rest == "" {
+			out = append(out, "")
+			continue
+		}
+		if len(kinds) > 0 && itemRE.MatchString(rest) {
+			bullet := "- "
+			if kinds[len(kinds)-1] == "enumerate" {
+				bullet = "1. "
+			}
+			rest = strings.Repeat("  ", len(kinds)-1) + bullet + itemRE.ReplaceAllString(rest, "")
+			out = append(out, rest)
+			continue
+		}
+		if len(kinds) > 0 {
+			out = append(out, strings.Repeat("  ", len(kinds))+rest)
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// wrapCommands are converted to markdown around their single argument.
+var wrapCommands = map[string][2]string{
+	"textbf":     {"**", "**"},
+	"textit":     {"*", "*"},
+	"emph":       {"*", "*"},
+	"texttt":     {"`", "`"},
+	"underline":  {"", ""},
+	"textsc":     {"", ""},
+	"url":        {"<", ">"},
+	"section":    {"\n\n### ", "\n\n"},
+	"subsection": {"\n\n#### ", "\n\n"},
+}
+
+// declarations switch the style of the rest of their group, as in {\bf text}.
+var declarations = map[string]string{"bf": "textbf", "it": "textit", "em": "emph", "tt": "texttt"}
+
+var wordCommands = map[string]string{
+	"ldots":     "…",
+	"dots":      "…",
+	"par":       "\n\n",
+	"noindent":  "",
+	"bigskip":   "\n\n",
+	"medskip":   "\n\n",
+	"smallskip": "\n\n",
+	"newline":   "\n",
+	"centering": "",
+}
+
+var ignoredEnvs = map[string]bool{"center": true, "flushleft": true, "flushright": true}
+
+// convertText converts text outside math mode. Math segments
+// are copied unchanged, so that $a~b$ and $x--y$ stay valid.
+func convertText(out *strings.Builder, tex string) {
+	for i := 0; i < len(tex); {
+		c := tex[i]
+		switch {
+		case c == '$':
+			end := mathEnd(tex, i)
+			out.WriteString(tex[i:end])
+			i = end
+		case c == '\\':
+			i = convertCommand(out, tex, i)
+		case c == '~':
+			out.WriteString(" ")
+			i++
+		case strings.HasPrefix(tex[i:], "---"):
+			out.WriteString("—")
+			i += 3
+		case strings.HasPrefix(tex[i:], "--"):
+			out.WriteString("–")
+			i += 2
+		case strings.HasPrefix(tex[i:], "<<"):
+			out.WriteString("«")
+			i += 2
+		case strings.HasPrefix(tex[i:], ">>"):
+			out.WriteString("»")
+			i += 2
+		case strings.HasPrefix(tex[i:], "``"), strings.HasPrefix(tex[i:], "''"):
+			out.WriteString("\"")
+			i += 2
+		case c == '{':
+			i = convertGroup(out, tex, i)
+		case c == '}':
+			i++
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+}
+
+// convertGroup converts a {...} group, applying a leading
+// declaration such as \bf to the whole group.
+func convertGroup(out *strings.Builder, tex string, i int) int {
+	group, end := braceArg(tex, i)
+	j := 0
+	for j < len(group) && group[j] == ' ' {
+		j++
+	}
+	if strings.HasPrefix(group[j:], "\\") {
+		k := j + 1
+		for k < len(group) && isLetter(group[k]) {
+			k++
+		}
+		if cmd, ok := declarations[group[j+1:k]]; ok {
+			wrap := wrapCommands[cmd]
+			out.WriteString(wrap[0])
+			convertText(out, strings.TrimLeft(group[k:], " "))
+			out.WriteString(wrap[1])
+			return end
+		}
+	}
+	convertText(out, group)
+	return end
+}
+
+// mathEnd returns the index after the math segment starting at i.
+func mathEnd(tex string, i int) int {
+	delim := "$"
+	if strings.HasPrefix(tex[i:], "$$") {
+		delim = "$$"
+	}
+	for j := i + len(delim); j < len(tex); j++ {
+		if tex[j] == '\\' {
+			j++
+			continue
+		}
+		if strings.HasPrefix(tex[j:], delim) {
+			return j + len(delim)
+		}
+	}
+	return len(tex)
+}
+
+func convertCommand(out *strings.Builder, tex string, i int) int {
+	j := i + 1
+	for j < len(tex) && isLetter(tex[j]) {
+		j++
+	}
+	name := tex[i+1 : j]
+	if name == "" {
+		if j >= len(tex) {
+			return j
+		}
+		switch tex[j] {
+		case '\\':
+			out.WriteString("\n")
+		case ' ':
+			out.WriteByte(' ')
+		default:
+			// \%, \$, \{, \_ and similar escapes
+			out.WriteByte(tex[j])
+		}
+		return j + 1
+	}
+	switch {
+	case name == "begin" || name == "end":
+		env, end := braceArg(tex, j)
+		if !ignoredEnvs[env] {
+			out.WriteString(tex[i:end])
+		}
+		return end
+	case name == "includegraphics":
+		end := skipOptionalArg(tex, j)
+		file, end := braceArg(tex, end)
+		out.WriteString("![](" + file + ")")
+		return end
+	case name == "href":
+		url, end := braceArg(tex, j)
+		text, end := braceArg(tex, end)
+		out.WriteString("[")
+		convertText(out, text)
+		out.WriteString("](" + url + ")")
+		return end
+	}
+	if wrap, ok := wrapCommands[name]; ok {
+		arg, end := braceArg(tex, j)
+		out.WriteString(wrap[0])
+		convertText(out, arg)
+		out.WriteString(wrap[1])
+		return end
+	}
+	if word, ok := wordCommands[name]; ok {
+		for j < len(tex) && tex[j] == ' ' {
+			j++
+		}
+		out.WriteString(word)
+		return j
+	}
+	// keep unknown commands with their arguments, e.g. \unknown{a}{b}
+	for j < len(tex) && tex[j] == '{' {
+		_, j = braceArg(tex, j)
+	}
+	out.WriteString(tex[i:j])
+	return j
+}
+
+// braceArg returns the contents of the {...} group at or after i,
+// skipping spaces, and the index after it.
+func braceArg(tex string, i int) (string, int) {
+	for i < len(tex) && tex[i] == ' ' {
+		i++
+	}
+	if i >= len(tex) || tex[i] != '{' {
+		return "", i
+	}
+	depth := 0
+	for j := i; j < len(tex); j++ {
+		switch tex[j] {
+		case '\\':
+			j++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return tex[i+1 : j], j + 1
+			}
+		}
+	}
+	return tex[i+1:], len(tex)
+}
+
+func skipOptionalArg(tex string, i int) int {
+	if i < len(tex) && tex[i] == '[' {
+		if end := strings.IndexByte(tex[i:], ']'); end >= 0 {
+			return i + end + 1
+		}
+	}
+	return i
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,84 @@
+package polygon
+
+import "encoding/xml"
+
+// problemXML is the subset of Polygon's problem.xml that the importer reads.
+type problemXML struct {
+	XMLName    xml.Name       `xml:"problem"`
+	ShortName  string         `xml:"short-name,attr"`
+	Names      []nameXML      `xml:"names>name"`
+	Statements []statementXML `xml:"statements>statement"`
+	Testsets   []testsetXML   `xml:"judging>testset"`
+	Checker    *checkerXML    `xml:"assets>checker"`
+	Interactor *interactorXML `xml:"assets>interactor"`
+	Solutions  []solutionXML  `xml:"assets>solutions>solution"`
+	Tags       []tagXML       `xml:"tags>tag"`
+}
+
+type nameXML struct {
+	Language string `xml:"language,attr"`
+	Value    string `xml:"value,attr"`
+}
+
+type statementXML struct {
+	Language string `xml:"language,attr"`
+	Path     string `xml:"path,attr"`
+	Type     string `xml:"type,attr"`
+}
+
+type testsetXML struct {
+	Name          string     `xml:"name,attr"`
+	TimeLimitMs   uint32     `xml:"time-limit"`
+	MemoryBytes   uint64     `xml:"memory-limit"`
+	InputPattern  string     `xml:"input-path-pattern"`
+	AnswerPattern string     `xml:"answer-path-pattern"`
+	Tests         []testXML  `xml:"tests>test"`
+	Groups        []groupXML `xml:"groups>group"`
+}
+
+type testXML struct {
+	Sample bool   `xml:"sample,attr"`
+	Points string `xml:"points,attr"`
+	Group  string `xml:"group,attr"`
+}
+
+type groupXML struct {
+	Name         string   `xml:"name,attr"`
+	Points       string   `xml:"points,attr"`
+	PointsPolicy string   `xml:"points-policy,attr"`
+	Dependencies []depXML `xml:"dependencies>dependency"`
+}
+
+type depXML struct {
+	Group string `xml:"group,attr"`
+}
+
+type sourceXML struct {
+	Path string `xml:"path,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type checkerXML struct {
+	Name   string    `xml:"name,attr"`
+	Type   string    `xml:"type,attr"`
+	Source sourceXML `xml:"source"`
+}
+
+type interactorXML struct {
+	Source sourceXML `xml:"source"`
+}
+
+type solutionXML struct {
+	Tag       string        `xml:"tag,attr"`
+	Source    sourceXML     `xml:"source"`
+	ExtraTags []extraTagXML `xml:"extra-tags>extra-tag"`
+}
+
+type extraTagXML struct {
+	Group string `xml:"group,attr"`
+	Tag   string `xml:"tag,attr"`
+}
+
+type tagXML struct {
+	Value string `xml:"value,attr"`
+}
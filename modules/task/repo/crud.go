@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/programme-lv/backend/modules/task/srvc"
 )
 
@@ -25,7 +26,41 @@ func (r *taskPgRepo) CreateTask(ctx context.Context, t srvc.Task) error {
 	}
 	defer tx.Rollback(ctx)
 
-	// Insert main task.
+	args, err := taskRowArgs(t)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO tasks (short_id, `+taskRowColumns+`, visibility, publish_at)
+		VALUES ($1, `+taskRowValues+`, COALESCE(NULLIF($27, ''), 'published'), $28)
+	`, append(args, string(t.Visibility), t.PublishAt)...)
+	if err != nil {
+		return fmt.Errorf("insert main task: %w", err)
+	}
+	if err := insertTaskContent(ctx, tx, t); err != nil {
+		return err
+	}
+	if err := saveTaskRevision(ctx, tx, t); err != nil {
+		return err
+	}
+	if err := updateTaskSearch(ctx, tx, t.ShortId); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// taskRowColumns are the revised columns of the tasks row besides
+// short_id, bound to taskRowValues by taskRowArgs. Visibility is kept
+// across revisions and is only written on creation.
+const taskRowColumns = `full_name_dict, orig_lang, readme, illustr_img_object_key, width_px, height_px, filesize_bytes, mem_lim_megabytes, cpu_time_lim_secs, origin_olympiad, origin_org, origin_year, olymp_stage, origin_divisions, authors, problem_tags, archive_object_key, difficulty_rating, checker, interactor, revision, techniques, data_structures, origin_contestants, origin_solvers`
+
+const taskRowValues = `$2::jsonb, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15::jsonb, $16::jsonb, $17::jsonb, $18, $19, $20, $21, $22, $23::jsonb, $24::jsonb, $25, $26`
+
+func taskRowArgs(t srvc.Task) ([]any, error) {
 	var illustrObjectKey string
 	var illustrWidthPx, illustrHeightPx, illustrSzInBytes int
 	if t.IllustrImg != nil {
@@ -37,29 +72,34 @@ func (r *taskPgRepo) CreateTask(ctx context.Context, t srvc.Task) error {
 
 	fullNameJSON, err := marshalStringMapJSON(t.FullName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	divisionsJSON, err := marshalStringSliceJSON(t.OriginDivisions)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	authorsJSON, err := marshalStringSliceJSON(t.Authors)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	tagsJSON, err := marshalStringSliceJSON(t.ProblemTags)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	_, err = tx.Exec(ctx, `
-		INSERT INTO tasks (short_id, full_name_dict, orig_lang, readme, illustr_img_object_key, width_px, height_px, filesize_bytes, mem_lim_megabytes, cpu_time_lim_secs, origin_olympiad, origin_org, origin_year, olymp_stage, origin_divisions, authors, problem_tags, archive_object_key, difficulty_rating, checker, interactor)
-		VALUES ($1, $2::jsonb, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15::jsonb, $16::jsonb, $17::jsonb, $18, $19, $20, $21)
-	`, t.ShortId, fullNameJSON, t.OrigLang, t.Readme, illustrObjectKey, illustrWidthPx, illustrHeightPx, illustrSzInBytes, t.MemLimMegabytes, t.CpuTimeLimSecs, t.OriginOlympiad, t.OriginOrg, t.OriginYear, t.OlympStage, divisionsJSON, authorsJSON, tagsJSON, t.OgFilesZipObjectKey, t.DifficultyRating, t.Checker, t.Interactor)
+	techniquesJSON, err := marshalStringSliceJSON(t.Techniques)
 	if err != nil {
-		return fmt.Errorf("insert main task: %w", err)
+		return nil, err
 	}
+	dataStructuresJSON, err := marshalStringSliceJSON(t.DataStructures)
+	if err != nil {
+		return nil, err
+	}
+	return []any{t.ShortId, fullNameJSON, t.OrigLang, t.Readme, illustrObjectKey, illustrWidthPx, illustrHeightPx, illustrSzInBytes, t.MemLimMegabytes, t.CpuTimeLimSecs, t.OriginOlympiad, t.OriginOrg, t.OriginYear, t.OlympStage, divisionsJSON, authorsJSON, tagsJSON, t.OgFilesZipObjectKey, t.DifficultyRating, t.Checker, t.Interactor, t.Revision, techniquesJSON, dataStructuresJSON, t.OriginContestants, t.OriginSolvers}, nil
+}
 
+// insertTaskContent inserts the nested entities of a task.
+func insertTaskContent(ctx context.Context, tx pgx.Tx, t srvc.Task) error {
+	var err error
 	// Insert origin notes.
 	for _, note := range t.OriginNotes {
 		_, err = tx.Exec(ctx, `
@@ -196,17 +236,36 @@ func (r *taskPgRepo) CreateTask(ctx context.Context, t srvc.Task) error {
 			return fmt.Errorf("marshal solution subtasks: %w", err)
 		}
 		_, err = tx.Exec(ctx, `
-			INSERT INTO task_solutions (task_id, fname, content, subtasks)
-			VALUES ($1, $2, $3, $4)
-		`, t.ShortId, sol.Fname, sol.Content, subtasksBytes)
+			INSERT INTO task_solutions (task_id, fname, content, subtasks, score)
+			VALUES ($1, $2, $3, $4, $5)
+		`, t.ShortId, sol.Fname, sol.Content, subtasksBytes, sol.Score)
 		if err != nil {
 			return fmt.Errorf("insert solution: %w", err)
 		}
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("commit: %w", err)
+	// Insert attachments.
+	for _, a := range t.Attachments {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO task_attachments (task_short_id, path, object_key, filesize_bytes)
+			VALUES ($1, $2, $3, $4)
+		`, t.ShortId, a.Path, a.ObjectKey, a.SzInBytes)
+		if err != nil {
+			return fmt.Errorf("insert attachment: %w", err)
+		}
+	}
+
+	// Insert authoring files.
+	for _, f := range t.AuthoringFiles {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO task_authoring_files (task_short_id, path, sha2, filesize_bytes)
+			VALUES ($1, $2, $3, $4)
+		`, t.ShortId, f.Path, f.Sha2, f.SzInBytes)
+		if err != nil {
+			return fmt.Errorf("insert authoring file: %w", err)
+		}
 	}
+
 	return nil
 }
 
@@ -229,8 +288,41 @@ func (r *taskPgRepo) DeleteTask(ctx context.Context, shortId string) error {
 	}
 	defer tx.Rollback(ctx)
 
-	// Delete from all related tables first (in case of any issues with CASCADE)
+	if err := deleteTaskContent(ctx, tx, shortId); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `DELETE FROM task_solution_reports WHERE task_id = $1`, shortId)
+	if err != nil {
+		return fmt.Errorf("delete task solution reports: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM task_revisions WHERE task_id = $1`, shortId)
+	if err != nil {
+		return fmt.Errorf("delete task revisions: %w", err)
+	}
+
+	// Finally, delete the main task record
+	result, err := tx.Exec(ctx, `DELETE FROM tasks WHERE short_id = $1`, shortId)
+	if err != nil {
+		return fmt.Errorf("delete task: %w", err)
+	}
+
+	// Verify that the task was actually deleted
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("task %s was not deleted", shortId)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// deleteTaskContent deletes the nested entities of a task.
+func deleteTaskContent(ctx context.Context, tx pgx.Tx, shortId string) error {
 	// The foreign key constraints should handle the cascading, but we'll be explicit
+	var err error
 
 	// Delete task_examples
 	_, err = tx.Exec(ctx, `DELETE FROM task_examples WHERE task_short_id = $1`, shortId)
@@ -289,20 +381,16 @@ func (r *taskPgRepo) DeleteTask(ctx context.Context, shortId string) error {
 		return fmt.Errorf("delete task solutions: %w", err)
 	}
 
-	// Finally, delete the main task record
-	result, err := tx.Exec(ctx, `DELETE FROM tasks WHERE short_id = $1`, shortId)
+	// Delete task_attachments
+	_, err = tx.Exec(ctx, `DELETE FROM task_attachments WHERE task_short_id = $1`, shortId)
 	if err != nil {
-		return fmt.Errorf("delete task: %w", err)
-	}
-
-	// Verify that the task was actually deleted
-	rowsAffected := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("task %s was not deleted", shortId)
+		return fmt.Errorf("delete task attachments: %w", err)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("commit: %w", err)
+	// Delete task_authoring_files
+	_, err = tx.Exec(ctx, `DELETE FROM task_authoring_files WHERE task_short_id = $1`, shortId)
+	if err != nil {
+		return fmt.Errorf("delete task authoring files: %w", err)
 	}
 	return nil
 }
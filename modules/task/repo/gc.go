@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/programme-lv/backend/modules/task/srvc"
+)
+
+// ListReferencedObjects returns the stored object keys that current
+// tasks and every saved revision refer to. Revisions are read from
+// their snapshots, so rolled back content keeps its files.
+func (r *taskPgRepo) ListReferencedObjects(ctx context.Context) (srvc.ReferencedObjects, error) {
+	var refs srvc.ReferencedObjects
+	var err error
+	if refs.TestSha2s, err = r.listKeys(ctx, "test files", `
+		SELECT inp_sha2 FROM task_tests
+		UNION SELECT ans_sha2 FROM task_tests
+		UNION SELECT sha2 FROM task_authoring_files
+		UNION SELECT jsonb_path_query(snapshot, '$.Tests[*].InpSha2') #>> '{}' FROM task_revisions
+		UNION SELECT jsonb_path_query(snapshot, '$.Tests[*].AnsSha2') #>> '{}' FROM task_revisions
+		UNION SELECT jsonb_path_query(snapshot, '$.AuthoringFiles[*].Sha2') #>> '{}' FROM task_revisions
+	`); err != nil {
+		return refs, err
+	}
+	if refs.IllustrKeys, err = r.listKeys(ctx, "illustrations", `
+		SELECT illustr_img_object_key FROM tasks
+		UNION SELECT jsonb_path_query(snapshot, '$.IllustrImg.ObjectKey') #>> '{}' FROM task_revisions
+	`); err != nil {
+		return refs, err
+	}
+	if refs.MdImageKeys, err = r.listKeys(ctx, "statement images", `
+		SELECT object_key FROM task_images
+		UNION SELECT jsonb_path_query(snapshot, '$.MdImages[*].ObjectKey') #>> '{}' FROM task_revisions
+	`); err != nil {
+		return refs, err
+	}
+	if refs.AttachmentKeys, err = r.listKeys(ctx, "attachments", `
+		SELECT object_key FROM task_attachments
+		UNION SELECT jsonb_path_query(snapshot, '$.Attachments[*].ObjectKey') #>> '{}' FROM task_revisions
+	`); err != nil {
+		return refs, err
+	}
+	if refs.ArchiveKeys, err = r.listKeys(ctx, "archives", `
+		SELECT archive_object_key FROM tasks
+		UNION SELECT snapshot->>'OgFilesZipObjectKey' FROM task_revisions
+	`); err != nil {
+		return refs, err
+	}
+	return refs, nil
+}
+
+// listKeys runs a union of single text column selects
+// and returns its distinct non-empty values.
+func (r *taskPgRepo) listKeys(ctx context.Context, what string, union string) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT key FROM (`+union+`) AS keys (key)
+		WHERE key IS NOT NULL AND key <> ''
+		ORDER BY key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query referenced %s: %w", what, err)
+	}
+	keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("scan referenced %s: %w", what, err)
+	}
+	return keys, nil
+}
@@ -22,7 +22,8 @@ func (r *taskPgRepo) GetTaskPreview(ctx context.Context, shortId string) (srvc.T
 	err := r.pool.QueryRow(ctx, `
 		SELECT short_id, full_name_dict, orig_lang, illustr_img_object_key, width_px, height_px, filesize_bytes,
 		       origin_olympiad, COALESCE(origin_org,''), COALESCE(origin_year,''),
-		       COALESCE(olymp_stage,''), COALESCE(origin_divisions,'[]'::jsonb), difficulty_rating
+		       COALESCE(olymp_stage,''), COALESCE(origin_divisions,'[]'::jsonb), difficulty_rating,
+		       visibility, publish_at
 		FROM tasks
 		WHERE short_id = $1
 	`, shortId).Scan(
@@ -39,6 +40,8 @@ func (r *taskPgRepo) GetTaskPreview(ctx context.Context, shortId string) (srvc.T
 		&t.OlympStage,
 		&divisionsBytes,
 		&t.DifficultyRating,
+		&t.Visibility,
+		&t.PublishAt,
 	)
 	if err == nil && len(fullNameBytes) > 0 {
 		var nameMap map[string]string
@@ -87,8 +90,9 @@ func (r *taskPgRepo) GetTask(ctx context.Context, shortId string) (srvc.Task, er
 	var authorsBytes []byte
 	var divisionsBytes []byte
 	var problemTagsBytes []byte
+	var techniquesBytes, dataStructuresBytes []byte
 	err := r.pool.QueryRow(ctx, `
-		SELECT short_id, full_name_dict, orig_lang, readme, illustr_img_object_key, width_px, height_px, filesize_bytes, mem_lim_megabytes, cpu_time_lim_secs, origin_olympiad, COALESCE(origin_org,''), COALESCE(origin_year,''), COALESCE(olymp_stage,''), COALESCE(origin_divisions,'[]'::jsonb), COALESCE(authors,'[]'::jsonb), COALESCE(problem_tags,'[]'::jsonb), COALESCE(archive_object_key,''), difficulty_rating, checker, interactor
+		SELECT short_id, full_name_dict, orig_lang, readme, illustr_img_object_key, width_px, height_px, filesize_bytes, mem_lim_megabytes, cpu_time_lim_secs, origin_olympiad, COALESCE(origin_org,''), COALESCE(origin_year,''), COALESCE(olymp_stage,''), COALESCE(origin_divisions,'[]'::jsonb), COALESCE(authors,'[]'::jsonb), COALESCE(problem_tags,'[]'::jsonb), COALESCE(archive_object_key,''), difficulty_rating, checker, interactor, revision, visibility, publish_at, COALESCE(techniques,'[]'::jsonb), COALESCE(data_structures,'[]'::jsonb), origin_contestants, origin_solvers
 		FROM tasks
 		WHERE short_id = $1
 	`, shortId).Scan(
@@ -113,6 +117,13 @@ func (r *taskPgRepo) GetTask(ctx context.Context, shortId string) (srvc.Task, er
 		&t.DifficultyRating,
 		&t.Checker,
 		&t.Interactor,
+		&t.Revision,
+		&t.Visibility,
+		&t.PublishAt,
+		&techniquesBytes,
+		&dataStructuresBytes,
+		&t.OriginContestants,
+		&t.OriginSolvers,
 	)
 	if err == nil && len(fullNameBytes) > 0 {
 		var nameMap map[string]string
@@ -120,6 +131,10 @@ func (r *taskPgRepo) GetTask(ctx context.Context, shortId string) (srvc.Task, er
 			t.FullName = nameMap
 		}
 	}
+	if err == nil {
+		_ = json.Unmarshal(techniquesBytes, &t.Techniques)
+		_ = json.Unmarshal(dataStructuresBytes, &t.DataStructures)
+	}
 	if err == nil && len(authorsBytes) > 0 {
 		var authors []string
 		if uerr := json.Unmarshal(authorsBytes, &authors); uerr == nil {
@@ -400,7 +415,7 @@ func (r *taskPgRepo) GetTask(ctx context.Context, shortId string) (srvc.Task, er
 
 	// Load Solutions.
 	solutionRows, err := r.pool.Query(ctx, `
-		SELECT fname, content, subtasks 
+		SELECT fname, content, subtasks, score
 		FROM task_solutions 
 		WHERE task_id = $1
 	`, shortId)
@@ -411,7 +426,7 @@ func (r *taskPgRepo) GetTask(ctx context.Context, shortId string) (srvc.Task, er
 	for solutionRows.Next() {
 		var sol srvc.Solution
 		var subtasksBytes []byte
-		if err := solutionRows.Scan(&sol.Fname, &sol.Content, &subtasksBytes); err != nil {
+		if err := solutionRows.Scan(&sol.Fname, &sol.Content, &subtasksBytes, &sol.Score); err != nil {
 			solutionRows.Close()
 			return t, fmt.Errorf("load solution: %w", err)
 		}
@@ -426,5 +441,47 @@ func (r *taskPgRepo) GetTask(ctx context.Context, shortId string) (srvc.Task, er
 	solutionRows.Close()
 	t.Solutions = solutions
 
+	attachmentRows, err := r.pool.Query(ctx, `
+		SELECT path, object_key, filesize_bytes
+		FROM task_attachments
+		WHERE task_short_id = $1
+		ORDER BY path
+	`, shortId)
+	if err != nil {
+		return t, fmt.Errorf("load attachments: %w", err)
+	}
+	var attachments []srvc.Attachment
+	for attachmentRows.Next() {
+		var a srvc.Attachment
+		if err := attachmentRows.Scan(&a.Path, &a.ObjectKey, &a.SzInBytes); err != nil {
+			attachmentRows.Close()
+			return t, fmt.Errorf("load attachment: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+	attachmentRows.Close()
+	t.Attachments = attachments
+
+	authoringRows, err := r.pool.Query(ctx, `
+		SELECT path, sha2, filesize_bytes
+		FROM task_authoring_files
+		WHERE task_short_id = $1
+		ORDER BY path
+	`, shortId)
+	if err != nil {
+		return t, fmt.Errorf("load authoring files: %w", err)
+	}
+	var authoringFiles []srvc.AuthoringFile
+	for authoringRows.Next() {
+		var f srvc.AuthoringFile
+		if err := authoringRows.Scan(&f.Path, &f.Sha2, &f.SzInBytes); err != nil {
+			authoringRows.Close()
+			return t, fmt.Errorf("load authoring file: %w", err)
+		}
+		authoringFiles = append(authoringFiles, f)
+	}
+	authoringRows.Close()
+	t.AuthoringFiles = authoringFiles
+
 	return t, nil
 }
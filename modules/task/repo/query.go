@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/programme-lv/backend/modules/task/srvc"
 )
@@ -41,9 +43,117 @@ func (r *taskPgRepo) SearchTasksByName(ctx context.Context, name string) ([]stri
 	return taskIds, nil
 }
 
-// ListTaskPreviews returns a list of task previews with pagination.
-func (r *taskPgRepo) ListTaskPreviews(ctx context.Context, limit int, offset int) ([]srvc.TaskPreview, error) {
-	// Query tasks table for preview data
+// listedTaskCond matches tasks that are published now,
+// mirroring srvc.EffectiveVisibility.
+const listedTaskCond = `(t.visibility = 'published'
+	OR (t.visibility IN ('draft', 'hidden') AND t.publish_at <= NOW()))`
+
+// taskTagsExpr lists the topics, techniques and data structures of a task.
+const taskTagsExpr = `COALESCE(t.problem_tags,'[]'::jsonb) || COALESCE(t.techniques,'[]'::jsonb) || COALESCE(t.data_structures,'[]'::jsonb)`
+
+// taskNameExpr mirrors srvc.TaskPreview.DefaultFullName.
+const taskNameExpr = `COALESCE(
+	t.full_name_dict->>NULLIF(t.orig_lang, ''),
+	t.full_name_dict->>'lv',
+	(SELECT n.value FROM jsonb_each_text(t.full_name_dict) AS n LIMIT 1),
+	'')`
+
+// ListTaskPreviews returns the page of task previews selected by q
+// and how many tasks match it. Ties are ordered by short ID, so that
+// pages do not overlap.
+func (r *taskPgRepo) ListTaskPreviews(ctx context.Context, q srvc.TaskPreviewQuery) ([]srvc.TaskPreview, int, error) {
+	var conds []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if !q.IncludeUnlisted {
+		conds = append(conds, listedTaskCond)
+	}
+	if q.Origins != nil {
+		var olympiads, years, stages, divisions []string
+		for _, o := range q.Origins {
+			divisionsJSON, err := json.Marshal(o.Divisions)
+			if err != nil {
+				return nil, 0, fmt.Errorf("marshal origin divisions: %w", err)
+			}
+			if o.Divisions == nil {
+				divisionsJSON = []byte("[]")
+			}
+			olympiads = append(olympiads, o.Olympiad)
+			years = append(years, o.Year)
+			stages = append(stages, o.Stage)
+			divisions = append(divisions, string(divisionsJSON))
+		}
+		conds = append(conds, fmt.Sprintf(`(COALESCE(t.origin_olympiad, ''), COALESCE(t.origin_year, ''),
+			COALESCE(t.olymp_stage, ''), COALESCE(t.origin_divisions, '[]'::jsonb)) IN (
+			SELECT o.olympiad, o.year, o.stage, o.divisions::jsonb
+			FROM unnest(%s::text[], %s::text[], %s::text[], %s::text[]) AS o (olympiad, year, stage, divisions))`,
+			arg(olympiads), arg(years), arg(stages), arg(divisions)))
+	}
+	if q.MinDifficulty > 0 {
+		conds = append(conds, "t.difficulty_rating >= "+arg(q.MinDifficulty))
+	}
+	if q.MaxDifficulty > 0 {
+		conds = append(conds, "t.difficulty_rating <= "+arg(q.MaxDifficulty))
+	}
+	if len(q.Tags) > 0 {
+		tagsJSON, err := json.Marshal(q.Tags)
+		if err != nil {
+			return nil, 0, fmt.Errorf("marshal tags: %w", err)
+		}
+		conds = append(conds, "("+taskTagsExpr+") @> "+arg(string(tagsJSON))+"::jsonb")
+	}
+	where := "TRUE"
+	if len(conds) > 0 {
+		where = strings.Join(conds, " AND ")
+	}
+
+	var total int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM tasks t WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count task previews: %w", err)
+	}
+
+	join, sortKey := "", ""
+	switch q.Sort {
+	case srvc.TaskSortName:
+		sortKey = "(" + taskNameExpr + ") COLLATE lv_ci"
+	case srvc.TaskSortDifficulty:
+		sortKey = "t.difficulty_rating"
+	case srvc.TaskSortYear:
+		var years []string
+		var editions []int
+		for year, edition := range q.YearKeys {
+			years = append(years, year)
+			editions = append(editions, edition)
+		}
+		join = fmt.Sprintf(`LEFT JOIN unnest(%s::text[], %s::int[]) AS y (origin_year, edition)
+			ON y.origin_year = COALESCE(t.origin_year, '')`, arg(years), arg(editions))
+		sortKey = "y.edition"
+	case srvc.TaskSortSolves:
+		var taskIds []string
+		var solves []int
+		for taskId, count := range q.Solves {
+			taskIds = append(taskIds, taskId)
+			solves = append(solves, count)
+		}
+		join = fmt.Sprintf(`LEFT JOIN unnest(%s::text[], %s::int[]) AS sc (short_id, solves)
+			ON sc.short_id = t.short_id`, arg(taskIds), arg(solves))
+		sortKey = "COALESCE(sc.solves, 0)"
+	}
+	orderBy := "t.short_id"
+	if sortKey != "" {
+		dir := "ASC"
+		if q.Desc {
+			dir = "DESC"
+		}
+		// tasks without a year sort last in either direction
+		orderBy = sortKey + " " + dir + " NULLS LAST, t.short_id"
+	}
+	limit, offset := arg(q.Limit), arg(q.Offset)
+
 	rows, err := r.pool.Query(ctx, `
 		SELECT t.short_id,
 		       t.full_name_dict,
@@ -71,13 +181,16 @@ func (r *taskPgRepo) ListTaskPreviews(ctx context.Context, limit int, offset int
 				FROM task_origin_notes ton
 				WHERE ton.task_short_id = t.short_id
 				LIMIT 1)
-		       ) as origin_note_short
+		       ) as origin_note_short,
+		       `+taskTagsExpr+`,
+		       t.visibility, t.publish_at
 		FROM tasks t
-		ORDER BY t.short_id
-		LIMIT $1 OFFSET $2
-	`, limit, offset)
+		`+join+`
+		WHERE `+where+`
+		ORDER BY `+orderBy+`
+		LIMIT `+limit+` OFFSET `+offset, args...)
 	if err != nil {
-		return nil, fmt.Errorf("query task previews: %w", err)
+		return nil, 0, fmt.Errorf("query task previews: %w", err)
 	}
 	defer rows.Close()
 
@@ -91,6 +204,7 @@ func (r *taskPgRepo) ListTaskPreviews(ctx context.Context, limit int, offset int
 		var originNote, originNoteShort *string
 		var fullNameBytes []byte
 		var divisionsBytes []byte
+		var tagsBytes []byte
 		err := rows.Scan(
 			&p.ShortId,
 			&fullNameBytes,
@@ -107,9 +221,12 @@ func (r *taskPgRepo) ListTaskPreviews(ctx context.Context, limit int, offset int
 			&p.DifficultyRating,
 			&originNote,
 			&originNoteShort,
+			&tagsBytes,
+			&p.Visibility,
+			&p.PublishAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("scan task preview: %w", err)
+			return nil, 0, fmt.Errorf("scan task preview: %w", err)
 		}
 
 		if len(fullNameBytes) > 0 {
@@ -121,6 +238,16 @@ func (r *taskPgRepo) ListTaskPreviews(ctx context.Context, limit int, offset int
 		if len(divisionsBytes) > 0 {
 			_ = json.Unmarshal(divisionsBytes, &p.OriginDivisions)
 		}
+		if len(tagsBytes) > 0 {
+			var tags []*string
+			_ = json.Unmarshal(tagsBytes, &tags)
+			// topics, techniques and data structures share some slugs
+			for _, tag := range tags {
+				if tag != nil && !slices.Contains(p.ProblemTags, *tag) {
+					p.ProblemTags = append(p.ProblemTags, *tag)
+				}
+			}
+		}
 
 		// Handle NULL values
 		if originNote != nil {
@@ -143,23 +270,25 @@ func (r *taskPgRepo) ListTaskPreviews(ctx context.Context, limit int, offset int
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating task previews: %w", err)
+		return nil, 0, fmt.Errorf("error iterating task previews: %w", err)
 	}
 
-	return previews, nil
+	return previews, total, nil
 }
 
 // ListOriginCounts returns distinct stored origin tuples and how many tasks share each.
-func (r *taskPgRepo) ListOriginCounts(ctx context.Context) ([]srvc.OriginCount, error) {
+// Unless includeUnlisted is set, only listed tasks are counted.
+func (r *taskPgRepo) ListOriginCounts(ctx context.Context, includeUnlisted bool) ([]srvc.OriginCount, error) {
 	rows, err := r.pool.Query(ctx, `
-		SELECT COALESCE(origin_olympiad, ''),
-		       COALESCE(origin_year, ''),
-		       COALESCE(olymp_stage, ''),
-		       COALESCE(origin_divisions, '[]'::jsonb),
+		SELECT COALESCE(t.origin_olympiad, ''),
+		       COALESCE(t.origin_year, ''),
+		       COALESCE(t.olymp_stage, ''),
+		       COALESCE(t.origin_divisions, '[]'::jsonb),
 		       COUNT(*)
-		FROM tasks
+		FROM tasks t
+		WHERE $1 OR `+listedTaskCond+`
 		GROUP BY 1, 2, 3, 4
-	`)
+	`, includeUnlisted)
 	if err != nil {
 		return nil, fmt.Errorf("list origin counts: %w", err)
 	}
@@ -16,7 +16,12 @@ func marshalStringMapJSON(m map[string]string) ([]byte, error) {
 	return b, nil
 }
 
+// marshalStringSliceJSON encodes a nil slice as [] rather than null,
+// so that the column can be concatenated and expanded as an array.
 func marshalStringSliceJSON(s []string) ([]byte, error) {
+	if s == nil {
+		s = []string{}
+	}
 	b, err := json.Marshal(s)
 	if err != nil {
 		return nil, fmt.Errorf("marshal string slice: %w", err)
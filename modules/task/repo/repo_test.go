@@ -31,6 +31,16 @@ func TestTaskPgRepo(t *testing.T) {
 		t.Fatalf("Failed to unmarshal task: %v", err)
 	}
 
+	task.Attachments = []srvc.Attachment{
+		{Path: "grader.h", ObjectKey: "aplusbirc/0123456789ab/grader.h", SzInBytes: 18},
+	}
+	task.AuthoringFiles = []srvc.AuthoringFile{
+		{Path: "testspec/gen.py", Sha2: "5f0b7c2f9d1e", SzInBytes: 42},
+	}
+	task.Techniques = []string{"brute-force"}
+	task.DataStructures = []string{"array"}
+	contestants, solvers := 120, 7
+	task.OriginContestants, task.OriginSolvers = &contestants, &solvers
 	ctx := context.Background()
 
 	// Test task creation
@@ -114,6 +124,13 @@ func TestTaskPgRepo(t *testing.T) {
 	assert.Equal(t, "partial.py", pySol.Fname, "Python solution filename")
 	assert.Equal(t, []int{1, 2}, pySol.Subtasks, "Python solution subtasks")
 
+	assert.Equal(t, task.Attachments, retrievedTask.Attachments, "Attachments mismatch")
+	assert.Equal(t, task.AuthoringFiles, retrievedTask.AuthoringFiles, "AuthoringFiles mismatch")
+	assert.Equal(t, []string{"brute-force"}, retrievedTask.Techniques, "Techniques mismatch")
+	assert.Equal(t, []string{"array"}, retrievedTask.DataStructures, "DataStructures mismatch")
+	assert.Equal(t, &contestants, retrievedTask.OriginContestants, "OriginContestants mismatch")
+	assert.Equal(t, &solvers, retrievedTask.OriginSolvers, "OriginSolvers mismatch")
+
 	// Test ResolveNames
 	names, err := repo.ResolveNames(ctx, []string{task.ShortId})
 	require.NoError(t, err, "Failed to resolve names")
@@ -162,11 +179,75 @@ func TestTaskPgRepo(t *testing.T) {
 	})
 
 	t.Run("ListTaskPreviews", func(t *testing.T) {
-		taskPreviews, err := repo.ListTaskPreviews(ctx, 10, 0)
+		taskPreviews, total, err := repo.ListTaskPreviews(ctx, srvc.TaskPreviewQuery{IncludeUnlisted: true, Limit: 10})
 		require.NoError(t, err, "Failed to list task previews")
 		require.Len(t, taskPreviews, 1, "Should have exactly one task preview")
+		assert.Equal(t, 1, total, "Listed preview total mismatch")
 		assert.Equal(t, "LIO 38. atlases kārta", taskPreviews[0].OriginNoteShort, "Listed preview OriginNoteShort mismatch")
 		assert.Equal(t, []string{"junior"}, taskPreviews[0].OriginDivisions, "Listed preview divisions mismatch")
+		assert.Contains(t, taskPreviews[0].ProblemTags, "two-sum", "Listed preview ProblemTags missing two-sum")
+		assert.Contains(t, taskPreviews[0].ProblemTags, "brute-force", "Listed preview ProblemTags missing techniques")
+
+		origins, err := repo.ListOriginCounts(ctx, true)
+		require.NoError(t, err, "Failed to list origin counts")
+		for _, q := range []srvc.TaskPreviewQuery{
+			{Origins: origins, Tags: []string{"two-sum", "brute-force"}, MinDifficulty: 3, MaxDifficulty: 3},
+			{Sort: srvc.TaskSortName, Desc: true},
+			{Sort: srvc.TaskSortYear, YearKeys: map[string]int{"2024/2025": 2025}},
+			{Sort: srvc.TaskSortSolves, Solves: map[string]int{"aplusbirc": 2}},
+		} {
+			q.IncludeUnlisted, q.Limit = true, 10
+			taskPreviews, total, err = repo.ListTaskPreviews(ctx, q)
+			require.NoError(t, err, "Failed to list task previews with %+v", q)
+			require.Len(t, taskPreviews, 1, "Query %+v should match the task", q)
+			assert.Equal(t, 1, total)
+		}
+		for _, q := range []srvc.TaskPreviewQuery{
+			{Origins: []srvc.OriginCount{}},
+			{Tags: []string{"two-sum", "dp"}},
+			{MinDifficulty: 4},
+		} {
+			q.IncludeUnlisted, q.Limit = true, 10
+			taskPreviews, total, err = repo.ListTaskPreviews(ctx, q)
+			require.NoError(t, err, "Failed to list task previews with %+v", q)
+			assert.Empty(t, taskPreviews, "Query %+v should not match the task", q)
+			assert.Zero(t, total)
+		}
+		taskPreviews, total, err = repo.ListTaskPreviews(ctx, srvc.TaskPreviewQuery{IncludeUnlisted: true, Offset: 1, Limit: 10})
+		require.NoError(t, err, "Failed to list task previews past the end")
+		assert.Empty(t, taskPreviews)
+		assert.Equal(t, 1, total, "Total counts tasks outside the page")
+	})
+
+	t.Run("SearchTasks", func(t *testing.T) {
+		hits, err := repo.SearchTasks(ctx, "kartītes", 10, true)
+		require.NoError(t, err, "Failed to search task statements")
+		require.Len(t, hits, 1, "Statement search should find the task")
+		assert.Equal(t, "aplusbirc", hits[0].ShortId, "Search hit ShortId mismatch")
+		assert.Equal(t, "lv", hits[0].Lang, "Search hit language mismatch")
+		assert.Contains(t, hits[0].Snippet, "<mark>kartītes</mark>", "Search snippet should highlight the match")
+
+		hits, err = repo.SearchTasks(ctx, "two-sum", 10, true)
+		require.NoError(t, err, "Failed to search task tags")
+		assert.Len(t, hits, 1, "Tag search should find the task")
+
+		require.NoError(t, repo.UpdateStatement(ctx, task.ShortId, srvc.MarkdownStatement{
+			LangIso639: "en", Story: "Given $N$ cards",
+		}), "Failed to add English statement")
+		hits, err = repo.SearchTasks(ctx, "card", 10, true)
+		require.NoError(t, err, "Failed to search English statement")
+		require.Len(t, hits, 1, "Stemmed English search should find the new statement")
+		assert.Equal(t, "en", hits[0].Lang, "Search hit language mismatch")
+	})
+
+	t.Run("ListReferencedObjects", func(t *testing.T) {
+		refs, err := repo.ListReferencedObjects(ctx)
+		require.NoError(t, err, "Failed to list referenced objects")
+		assert.Contains(t, refs.TestSha2s, task.Tests[0].InpSha2, "Test input should be referenced")
+		assert.Contains(t, refs.TestSha2s, "5f0b7c2f9d1e", "Authoring file should be referenced")
+		assert.Equal(t, []string{"task-md-images/nekoks.png"}, refs.IllustrKeys, "Illustration key mismatch") // gitleaks:allow -- test storage path
+		assert.Equal(t, []string{"aplusbirc/0123456789ab/grader.h"}, refs.AttachmentKeys, "Attachment key mismatch")
+		assert.Equal(t, []string{"task-archives/aplusbirc.zip"}, refs.ArchiveKeys, "Archive key mismatch")
 	})
 
 	// Test DeleteTask
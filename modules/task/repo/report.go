@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/programme-lv/backend/modules/task/srvc"
+)
+
+// solutionCheckJSON is the stored form of srvc.SolutionCheck.
+type solutionCheckJSON struct {
+	Fname         string     `json:"fname"`
+	LangId        string     `json:"lang_id"`
+	ExecUuid      *uuid.UUID `json:"exec_uuid"`
+	Status        string     `json:"status"`
+	SubtaskPoints []float64  `json:"subtask_points"`
+	ReceivedScore float64    `json:"received_score"`
+	PossibleScore int        `json:"possible_score"`
+	Problems      []string   `json:"problems"`
+}
+
+// SaveSolutionReport inserts or replaces the solution report of a task.
+func (r *taskPgRepo) SaveSolutionReport(ctx context.Context, report srvc.SolutionReport) error {
+	checks := make([]solutionCheckJSON, len(report.Solutions))
+	for i, c := range report.Solutions {
+		checks[i] = solutionCheckJSON(c)
+	}
+	checksBytes, err := json.Marshal(checks)
+	if err != nil {
+		return fmt.Errorf("marshal solution checks: %w", err)
+	}
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO task_solution_reports (task_id, started_at, finished_at, flagged, interrupted, solutions)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (task_id) DO UPDATE SET
+			started_at = EXCLUDED.started_at,
+			finished_at = EXCLUDED.finished_at,
+			flagged = EXCLUDED.flagged,
+			interrupted = EXCLUDED.interrupted,
+			solutions = EXCLUDED.solutions
+	`, report.TaskId, report.StartedAt, report.FinishedAt, report.Flagged, report.Interrupted, checksBytes)
+	if err != nil {
+		return fmt.Errorf("save solution report: %w", err)
+	}
+	return nil
+}
+
+// GetSolutionReport returns nil when the task has no solution report.
+func (r *taskPgRepo) GetSolutionReport(ctx context.Context, taskId string) (*srvc.SolutionReport, error) {
+	report := srvc.SolutionReport{TaskId: taskId}
+	var finishedAt *time.Time
+	var checksBytes []byte
+	err := r.pool.QueryRow(ctx, `
+		SELECT started_at, finished_at, flagged, interrupted, solutions
+		FROM task_solution_reports
+		WHERE task_id = $1
+	`, taskId).Scan(&report.StartedAt, &finishedAt, &report.Flagged, &report.Interrupted, &checksBytes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get solution report: %w", err)
+	}
+	report.FinishedAt = finishedAt
+
+	var checks []solutionCheckJSON
+	if err := json.Unmarshal(checksBytes, &checks); err != nil {
+		return nil, fmt.Errorf("unmarshal solution checks: %w", err)
+	}
+	for _, c := range checks {
+		report.Solutions = append(report.Solutions, srvc.SolutionCheck(c))
+	}
+	return &report, nil
+}
+
+// InterruptSolutionReports finishes, flags and marks as interrupted
+// the unfinished reports started before startedBefore.
+func (r *taskPgRepo) InterruptSolutionReports(ctx context.Context, startedBefore time.Time) (int, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE task_solution_reports
+		SET finished_at = now(), flagged = true, interrupted = true
+		WHERE finished_at IS NULL AND started_at < $1
+	`, startedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("interrupt solution reports: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,122 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/programme-lv/backend/modules/task/srvc"
+)
+
+// ReviseTask replaces the content of task prev with next, which must
+// carry the following revision number. The snapshot of prev is
+// refreshed first, so that in-place edits of the revision are kept.
+func (r *taskPgRepo) ReviseTask(ctx context.Context, prev, next srvc.Task) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := saveTaskRevision(ctx, tx, prev); err != nil {
+		return err
+	}
+	args, err := taskRowArgs(next)
+	if err != nil {
+		return err
+	}
+	result, err := tx.Exec(ctx, `
+		UPDATE tasks SET (`+taskRowColumns+`) = (`+taskRowValues+`)
+		WHERE short_id = $1 AND revision = $27
+	`, append(args, prev.Revision)...)
+	if err != nil {
+		return fmt.Errorf("update main task: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("task %s is no longer at revision %d", prev.ShortId, prev.Revision)
+	}
+	if err := deleteTaskContent(ctx, tx, prev.ShortId); err != nil {
+		return err
+	}
+	if err := insertTaskContent(ctx, tx, next); err != nil {
+		return err
+	}
+	if err := saveTaskRevision(ctx, tx, next); err != nil {
+		return err
+	}
+	if err := updateTaskSearch(ctx, tx, next.ShortId); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// saveTaskRevision stores the task as the snapshot of its revision.
+func saveTaskRevision(ctx context.Context, tx pgx.Tx, t srvc.Task) error {
+	snapshot, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("marshal task revision: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO task_revisions (task_id, revision, created_at, snapshot)
+		VALUES ($1, $2, NOW(), $3)
+		ON CONFLICT (task_id, revision) DO UPDATE SET snapshot = EXCLUDED.snapshot
+	`, t.ShortId, t.Revision, snapshot)
+	if err != nil {
+		return fmt.Errorf("save task revision: %w", err)
+	}
+	return nil
+}
+
+// ListTaskRevisions returns the stored revisions of a task, oldest first.
+func (r *taskPgRepo) ListTaskRevisions(ctx context.Context, taskId string) ([]srvc.TaskRevision, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT revision, created_at
+		FROM task_revisions
+		WHERE task_id = $1
+		ORDER BY revision
+	`, taskId)
+	if err != nil {
+		return nil, fmt.Errorf("list task revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []srvc.TaskRevision
+	for rows.Next() {
+		var rev srvc.TaskRevision
+		if err := rows.Scan(&rev.Revision, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan task revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate task revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// GetTaskRevision returns nil when the revision has no snapshot.
+func (r *taskPgRepo) GetTaskRevision(ctx context.Context, taskId string, revision int) (*srvc.Task, error) {
+	var snapshot []byte
+	err := r.pool.QueryRow(ctx, `
+		SELECT snapshot
+		FROM task_revisions
+		WHERE task_id = $1 AND revision = $2
+	`, taskId, revision).Scan(&snapshot)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get task revision: %w", err)
+	}
+	var t srvc.Task
+	if err := json.Unmarshal(snapshot, &t); err != nil {
+		return nil, fmt.Errorf("unmarshal task revision: %w", err)
+	}
+	return &t, nil
+}
@@ -0,0 +1,107 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/programme-lv/backend/modules/task/srvc"
+)
+
+// refreshTaskSearch rebuilds the search documents of task $1 from its stored
+// row and statements. Migrations 075 and 084 run the same query for every task.
+const refreshTaskSearch = `
+	WITH langs AS (
+		SELECT t.short_id, k.lang
+		FROM tasks t, jsonb_object_keys(COALESCE(t.full_name_dict, '{}'::jsonb)) AS k(lang)
+		WHERE t.short_id = $1
+		UNION
+		SELECT s.task_short_id, s.lang_iso639 FROM task_md_statements s
+		WHERE s.task_short_id = $1 AND s.lang_iso639 IS NOT NULL
+	), docs AS (
+		SELECT l.short_id, l.lang,
+		       CASE l.lang WHEN 'lv' THEN 'latvian' WHEN 'en' THEN 'english' ELSE 'simple' END AS config,
+		       COALESCE(t.full_name_dict->>l.lang, '') AS name,
+		       concat_ws(' ',
+		           (SELECT string_agg(x, ' ') FROM jsonb_array_elements_text(COALESCE(t.problem_tags, '[]'::jsonb) || COALESCE(t.techniques, '[]'::jsonb) || COALESCE(t.data_structures, '[]'::jsonb)) AS x),
+		           (SELECT string_agg(x, ' ') FROM jsonb_array_elements_text(COALESCE(t.authors, '[]'::jsonb)) AS x)
+		       ) AS meta,
+		       COALESCE(s.story, '') AS story,
+		       concat_ws(E'\n\n', s.input, s.output) AS io
+		FROM langs l
+		JOIN tasks t ON t.short_id = l.short_id
+		LEFT JOIN LATERAL (
+			SELECT story, input, output FROM task_md_statements
+			WHERE task_short_id = l.short_id AND lang_iso639 = l.lang
+			ORDER BY id DESC LIMIT 1
+		) s ON TRUE
+	)
+	INSERT INTO task_search (task_short_id, lang, config, body, document)
+	SELECT short_id, lang, config, concat_ws(E'\n\n', story, io),
+	       setweight(to_tsvector(config::regconfig, name), 'A') ||
+	       setweight(to_tsvector(config::regconfig, meta), 'B') ||
+	       setweight(to_tsvector(config::regconfig, story), 'C') ||
+	       setweight(to_tsvector(config::regconfig, io), 'D')
+	FROM docs
+`
+
+// updateTaskSearch replaces the search documents of the task
+// within the transaction that changed it.
+func updateTaskSearch(ctx context.Context, tx pgx.Tx, taskId string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM task_search WHERE task_short_id = $1`, taskId); err != nil {
+		return fmt.Errorf("delete task search documents: %w", err)
+	}
+	if _, err := tx.Exec(ctx, refreshTaskSearch, taskId); err != nil {
+		return fmt.Errorf("insert task search documents: %w", err)
+	}
+	return nil
+}
+
+// SearchTasks ranks tasks by how well one of their language documents
+// matches the web-search style query. Snippets are HTML-escaped
+// statement text with matches wrapped in <mark>.
+// Unless includeUnlisted is set, only listed tasks are searched.
+func (r *taskPgRepo) SearchTasks(ctx context.Context, query string, limit int, includeUnlisted bool) ([]srvc.TaskSearchHit, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH hits AS (
+			SELECT DISTINCT ON (s.task_short_id)
+			       s.task_short_id, s.lang, s.config, s.body, t.full_name_dict,
+			       ts_rank(s.document, websearch_to_tsquery(s.config::regconfig, $1))::float8 AS rank
+			FROM task_search s
+			JOIN tasks t ON t.short_id = s.task_short_id
+			WHERE s.document @@ websearch_to_tsquery(s.config::regconfig, $1)
+			  AND ($3 OR `+listedTaskCond+`)
+			ORDER BY s.task_short_id, rank DESC, s.lang
+		)
+		SELECT task_short_id, lang, full_name_dict, rank,
+		       ts_headline(config::regconfig,
+		           replace(replace(replace(body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+		           websearch_to_tsquery(config::regconfig, $1),
+		           'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2')
+		FROM hits
+		ORDER BY rank DESC, task_short_id
+		LIMIT $2
+	`, query, limit, includeUnlisted)
+	if err != nil {
+		return nil, fmt.Errorf("search tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []srvc.TaskSearchHit
+	for rows.Next() {
+		var hit srvc.TaskSearchHit
+		var fullNameBytes []byte
+		if err := rows.Scan(&hit.ShortId, &hit.Lang, &fullNameBytes, &hit.Rank, &hit.Snippet); err != nil {
+			return nil, fmt.Errorf("scan task search hit: %w", err)
+		}
+		if len(fullNameBytes) > 0 {
+			_ = json.Unmarshal(fullNameBytes, &hit.FullName)
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate task search hits: %w", err)
+	}
+	return hits, nil
+}
@@ -3,6 +3,7 @@ package repo
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/programme-lv/backend/modules/task/srvc"
 )
@@ -120,6 +121,9 @@ func (r *taskPgRepo) UpdateStatement(ctx context.Context, taskId string, stateme
 			return fmt.Errorf("insert new markdown statement: %w", err)
 		}
 	}
+	if err := updateTaskSearch(ctx, tx, taskId); err != nil {
+		return err
+	}
 
 	// Commit the transaction
 	if err = tx.Commit(ctx); err != nil {
@@ -211,3 +215,19 @@ func (r *taskPgRepo) UpdateIllustrationImg(ctx context.Context, taskId string, i
 
 	return nil
 }
+
+// UpdateVisibility sets the visibility and scheduled publication of a task.
+func (r *taskPgRepo) UpdateVisibility(ctx context.Context, taskId string, visibility srvc.Visibility, publishAt *time.Time) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE tasks
+		SET visibility = $2, publish_at = $3
+		WHERE short_id = $1
+	`, taskId, string(visibility), publishAt)
+	if err != nil {
+		return fmt.Errorf("update task visibility: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("task with ID %s does not exist", taskId)
+	}
+	return nil
+}
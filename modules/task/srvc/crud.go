@@ -11,6 +11,10 @@ func (ts *taskSrvc) CreateTask(ctx context.Context, task Task) srvcerror.E {
 	for i := range task.MdImages {
 		task.MdImages[i].ObjectKey = taskStatementImageStoredKey(task.MdImages[i].ObjectKey)
 	}
+	task.Revision = 1
+	if task.Visibility == "" {
+		task.Visibility = VisibilityPublished
+	}
 	err := ts.repo.CreateTask(ctx, task)
 	if err != nil {
 		l := ts.logger(ctx)
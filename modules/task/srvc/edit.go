@@ -0,0 +1,270 @@
+package srvc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/programme-lv/backend/common/srvcerror"
+	taskzipv1 "github.com/programme-lv/backend/modules/task/taskzip"
+)
+
+// TaskScoring is how the tests of a task are grouped and scored.
+type TaskScoring struct {
+	Subtasks   []Subtask
+	TestGroups []TestGroup
+	// 1-based subtasks whose test inputs are shown with the statement
+	VisInpSubtaskIds []int
+}
+
+// SetTaskLimits changes the CPU time and memory limits
+// and returns the new revision.
+func (ts *taskSrvc) SetTaskLimits(
+	ctx context.Context, taskId string, cpuTimeLimSecs float64, memLimMegabytes int,
+) (int, srvcerror.E) {
+	return ts.editTask(ctx, taskId, "limits", func(task *Task) srvcerror.E {
+		task.CpuTimeLimSecs = cpuTimeLimSecs
+		task.MemLimMegabytes = memLimMegabytes
+		return nil
+	})
+}
+
+// SetTaskChecker replaces the checker source and returns the new
+// revision. An empty checker makes the task compare output exactly.
+func (ts *taskSrvc) SetTaskChecker(ctx context.Context, taskId string, checker string) (int, srvcerror.E) {
+	return ts.editTask(ctx, taskId, "checker", func(task *Task) srvcerror.E {
+		task.Checker = checker
+		return nil
+	})
+}
+
+// AddTaskTest inserts a test after test number after, or first when
+// after is 0, and returns the new revision. The test joins the test
+// group and subtasks of the test before it, or of the first test.
+func (ts *taskSrvc) AddTaskTest(
+	ctx context.Context, taskId string, after int, input, answer []byte,
+) (int, srvcerror.E) {
+	current, err := ts.getRawTask(ctx, taskId)
+	if err != nil {
+		return 0, err
+	}
+	if after < 0 || after > len(current.Tests) {
+		return 0, errTestNotFound(taskId, after)
+	}
+	if err := ts.uploadEditedTest(ctx, after+1, input, answer); err != nil {
+		return 0, err
+	}
+	test := Test{InpSha2: sha2Hex(input), AnsSha2: sha2Hex(answer)}
+	return ts.reviseEditedTask(ctx, current, "tests", func(task *Task) srvcerror.E {
+		id := after + 1
+		renumberTests(task, func(testID int) []int {
+			if testID >= id {
+				return []int{testID + 1}
+			}
+			return []int{testID}
+		})
+		neighbour := after
+		if neighbour == 0 {
+			neighbour = 2
+		}
+		joinTest := func(testIDs []int) []int {
+			if !slices.Contains(testIDs, neighbour) {
+				return testIDs
+			}
+			testIDs = append(testIDs, id)
+			slices.Sort(testIDs)
+			return testIDs
+		}
+		for i := range task.TestGroups {
+			task.TestGroups[i].TestIDs = joinTest(task.TestGroups[i].TestIDs)
+		}
+		for i := range task.Subtasks {
+			task.Subtasks[i].TestIDs = joinTest(task.Subtasks[i].TestIDs)
+		}
+		task.Tests = slices.Insert(slices.Clone(task.Tests), after, test)
+		return nil
+	})
+}
+
+// ReplaceTaskTest replaces the input, the answer or both of a test
+// and returns the new revision. A nil input or answer is kept.
+func (ts *taskSrvc) ReplaceTaskTest(
+	ctx context.Context, taskId string, testId int, input, answer []byte,
+) (int, srvcerror.E) {
+	if input == nil && answer == nil {
+		return 0, errInvalidTaskEdit("nav norādīta ne ievade, ne atbilde")
+	}
+	current, err := ts.getRawTask(ctx, taskId)
+	if err != nil {
+		return 0, err
+	}
+	if testId < 1 || testId > len(current.Tests) {
+		return 0, errTestNotFound(taskId, testId)
+	}
+	test := current.Tests[testId-1]
+	if input == nil {
+		if input, err = ts.DownloadTestFile(ctx, test.InpSha2); err != nil {
+			return 0, err
+		}
+	}
+	if answer == nil {
+		if answer, err = ts.DownloadTestFile(ctx, test.AnsSha2); err != nil {
+			return 0, err
+		}
+	}
+	if err := ts.uploadEditedTest(ctx, testId, input, answer); err != nil {
+		return 0, err
+	}
+	test = Test{InpSha2: sha2Hex(input), AnsSha2: sha2Hex(answer)}
+	return ts.reviseEditedTask(ctx, current, "tests", func(task *Task) srvcerror.E {
+		task.Tests = slices.Clone(task.Tests)
+		task.Tests[testId-1] = test
+		return nil
+	})
+}
+
+// RemoveTaskTest removes a test and returns the new revision.
+// Later tests move up by one.
+func (ts *taskSrvc) RemoveTaskTest(ctx context.Context, taskId string, testId int) (int, srvcerror.E) {
+	return ts.editTask(ctx, taskId, "tests", func(task *Task) srvcerror.E {
+		if testId < 1 || testId > len(task.Tests) {
+			return errTestNotFound(taskId, testId)
+		}
+		renumberTests(task, func(testID int) []int {
+			switch {
+			case testID == testId:
+				return nil
+			case testID > testId:
+				return []int{testID - 1}
+			}
+			return []int{testID}
+		})
+		task.Tests = slices.Delete(slices.Clone(task.Tests), testId-1, testId)
+		return nil
+	})
+}
+
+// SetTaskScoring replaces the subtasks, test groups and visible input
+// subtasks and returns the new revision. Subtask scores are taken from
+// their test groups when they have any.
+func (ts *taskSrvc) SetTaskScoring(ctx context.Context, taskId string, scoring TaskScoring) (int, srvcerror.E) {
+	return ts.editTask(ctx, taskId, "scoring", func(task *Task) srvcerror.E {
+		task.Subtasks = scoring.Subtasks
+		task.TestGroups = scoring.TestGroups
+		task.VisInpSubtasks = nil
+		for _, id := range scoring.VisInpSubtaskIds {
+			if id < 1 || id > len(scoring.Subtasks) {
+				return errInvalidTaskEdit(fmt.Sprintf("nav apakšuzdevuma %d", id))
+			}
+			if slices.ContainsFunc(task.VisInpSubtasks, func(v VisibleInputSubtask) bool {
+				return v.SubtaskId == id
+			}) {
+				return errInvalidTaskEdit(fmt.Sprintf("apakšuzdevums %d norādīts atkārtoti", id))
+			}
+			task.VisInpSubtasks = append(task.VisInpSubtasks, VisibleInputSubtask{SubtaskId: id})
+		}
+		return nil
+	})
+}
+
+func (ts *taskSrvc) editTask(
+	ctx context.Context, taskId, change string, edit func(task *Task) srvcerror.E,
+) (int, srvcerror.E) {
+	current, err := ts.getRawTask(ctx, taskId)
+	if err != nil {
+		return 0, err
+	}
+	return ts.reviseEditedTask(ctx, current, change, edit)
+}
+
+// reviseEditedTask applies edit to a copy of current, checks the result
+// with the TaskZip rules and stores it as the next revision. edit must
+// not modify the slices of current in place, as they are saved as the
+// previous revision.
+func (ts *taskSrvc) reviseEditedTask(
+	ctx context.Context, current Task, change string, edit func(task *Task) srvcerror.E,
+) (int, srvcerror.E) {
+	next := current
+	if err := edit(&next); err != nil {
+		return 0, err
+	}
+	if err := ts.normalizeScoring(ctx, &next); err != nil {
+		return 0, err
+	}
+	revision, err := ts.reviseTask(ctx, current, next)
+	if err != nil {
+		return 0, err
+	}
+	ts.logger(ctx).Info("task edited", "task_id", current.ShortId,
+		"change", change, "revision", revision)
+	return revision, nil
+}
+
+// normalizeScoring validates the testing and scoring of an edited task
+// like a TaskZip upload and then rebuilds them the way an upload does,
+// so that subtask scores and visible inputs follow the tests.
+func (ts *taskSrvc) normalizeScoring(ctx context.Context, task *Task) srvcerror.E {
+	archive, err := mapToTaskZip(*task)
+	if err != nil {
+		return errInvalidTaskEdit(err.Error())
+	}
+	archive.Tests = make([]taskzipv1.Test, len(task.Tests))
+	if err := taskzipv1.ValidateTesting(&archive); err != nil {
+		return errInvalidTaskEdit(err.Error())
+	}
+	if err := taskzipv1.ValidateScoring(&archive); err != nil {
+		return errInvalidTaskEdit(err.Error())
+	}
+	var scoring Task
+	if err := mapTaskZipScoring(archive, &scoring); err != nil {
+		return errInvalidTaskEdit(err.Error())
+	}
+	for i := range scoring.VisInpSubtasks {
+		for j := range scoring.VisInpSubtasks[i].Tests {
+			test := &scoring.VisInpSubtasks[i].Tests[j]
+			input, err := ts.DownloadTestFile(ctx, task.Tests[test.TestId-1].InpSha2)
+			if err != nil {
+				return err
+			}
+			test.Input = string(input)
+		}
+	}
+	task.Subtasks = scoring.Subtasks
+	task.TestGroups = scoring.TestGroups
+	task.VisInpSubtasks = scoring.VisInpSubtasks
+	return nil
+}
+
+// uploadEditedTest checks a test that will get number id
+// and uploads its input and answer.
+func (ts *taskSrvc) uploadEditedTest(ctx context.Context, id int, input, answer []byte) srvcerror.E {
+	if err := taskzipv1.ValidateTest(id, taskzipv1.Test{Input: input, Output: answer}); err != nil {
+		return errInvalidTaskEdit(err.Error())
+	}
+	if err := ts.UploadTestFile(ctx, input); err != nil {
+		return err
+	}
+	return ts.UploadTestFile(ctx, answer)
+}
+
+// renumberTests maps the test IDs of test groups and subtasks
+// to new slices, dropping the IDs that mapTestID maps to none.
+func renumberTests(task *Task, mapTestID func(testID int) []int) {
+	renumber := func(testIDs []int) []int {
+		res := make([]int, 0, len(testIDs))
+		for _, testID := range testIDs {
+			res = append(res, mapTestID(testID)...)
+		}
+		return res
+	}
+	groups := slices.Clone(task.TestGroups)
+	for i := range groups {
+		groups[i].TestIDs = renumber(groups[i].TestIDs)
+	}
+	task.TestGroups = groups
+	subtasks := slices.Clone(task.Subtasks)
+	for i := range subtasks {
+		subtasks[i].TestIDs = renumber(subtasks[i].TestIDs)
+	}
+	task.Subtasks = subtasks
+}
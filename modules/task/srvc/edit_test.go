@@ -0,0 +1,111 @@
+package srvc_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/programme-lv/backend/common/filestore"
+	"github.com/programme-lv/backend/gen/mocks/mocktasksrvc"
+	"github.com/programme-lv/backend/modules/task/srvc"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditTaskTestsAndScoring(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	service := srvc.NewTaskSrvc(repo, store, store)
+
+	var tests []srvc.Test
+	for _, data := range []string{"1\n", "2\n", "3\n"} {
+		require.Nil(t, service.UploadTestFile(ctx, []byte(data)))
+		tests = append(tests, srvc.Test{InpSha2: sha2ForTest(data), AnsSha2: sha2ForTest(data)})
+	}
+	current := srvc.Task{
+		ShortId: "summa", Revision: 2, CpuTimeLimSecs: 1, MemLimMegabytes: 256,
+		Tests: tests,
+		TestGroups: []srvc.TestGroup{
+			{Points: 4, TestIDs: []int{1}},
+			{Points: 6, TestIDs: []int{2, 3}},
+		},
+		Subtasks: []srvc.Subtask{
+			{Score: 4, TestIDs: []int{1}},
+			{Score: 6, TestIDs: []int{2, 3}},
+		},
+		VisInpSubtasks: []srvc.VisibleInputSubtask{
+			{SubtaskId: 1, Tests: []srvc.VisInpSubtaskTest{{TestId: 1, Input: "1\n"}}},
+		},
+	}
+	var next srvc.Task
+	repo.EXPECT().Exists(ctx, "summa").Return(true, nil)
+	repo.EXPECT().GetTask(ctx, "summa").Return(current, nil)
+	repo.EXPECT().ReviseTask(ctx, current, mock.Anything).RunAndReturn(
+		func(_ context.Context, _ srvc.Task, task srvc.Task) error {
+			next = task
+			return nil
+		},
+	)
+
+	_, editErr := service.SetTaskLimits(ctx, "summa", 20, 256)
+	require.ErrorIs(t, editErr, srvc.ErrInvalidTaskEdit)
+	revision, editErr := service.SetTaskLimits(ctx, "summa", 0.5, 512)
+	require.Nil(t, editErr)
+	require.Equal(t, 3, revision)
+	require.Equal(t, 0.5, next.CpuTimeLimSecs)
+	require.Equal(t, 512, next.MemLimMegabytes)
+
+	revision, editErr = service.AddTaskTest(ctx, "summa", 1, []byte("7\n"), []byte("7\n"))
+	require.Nil(t, editErr)
+	require.Equal(t, 3, revision)
+	require.Len(t, next.Tests, 4)
+	require.Equal(t, sha2ForTest("7\n"), next.Tests[1].InpSha2)
+	require.Equal(t, []int{1, 2}, next.TestGroups[0].TestIDs)
+	require.Equal(t, []int{3, 4}, next.TestGroups[1].TestIDs)
+	require.Equal(t, []int{1, 2}, next.Subtasks[0].TestIDs)
+	require.Equal(t, []srvc.VisInpSubtaskTest{
+		{TestId: 1, Input: "1\n"}, {TestId: 2, Input: "7\n"},
+	}, next.VisInpSubtasks[0].Tests)
+	require.Equal(t, []int{1}, current.TestGroups[0].TestIDs, "the previous revision must not change")
+
+	_, editErr = service.AddTaskTest(ctx, "summa", 1, []byte(""), []byte("7\n"))
+	require.ErrorIs(t, editErr, srvc.ErrInvalidTaskEdit)
+	_, editErr = service.RemoveTaskTest(ctx, "summa", 4)
+	require.ErrorIs(t, editErr, srvc.ErrTestNotFound)
+	_, editErr = service.RemoveTaskTest(ctx, "summa", 1)
+	require.ErrorIs(t, editErr, srvc.ErrInvalidTaskEdit, "test group 1 would be empty")
+
+	_, editErr = service.RemoveTaskTest(ctx, "summa", 2)
+	require.Nil(t, editErr)
+	require.Equal(t, []srvc.Test{tests[0], tests[2]}, next.Tests)
+	require.Equal(t, []int{2}, next.TestGroups[1].TestIDs)
+
+	_, editErr = service.ReplaceTaskTest(ctx, "summa", 3, nil, []byte("4\n"))
+	require.Nil(t, editErr)
+	require.Equal(t, srvc.Test{InpSha2: tests[2].InpSha2, AnsSha2: sha2ForTest("4\n")}, next.Tests[2])
+
+	_, editErr = service.SetTaskScoring(ctx, "summa", srvc.TaskScoring{
+		Subtasks: []srvc.Subtask{{Score: 10, TestIDs: []int{1, 3}}},
+	})
+	require.ErrorIs(t, editErr, srvc.ErrInvalidTaskEdit)
+	_, editErr = service.SetTaskScoring(ctx, "summa", srvc.TaskScoring{
+		Subtasks:         []srvc.Subtask{{TestIDs: []int{1, 2, 3}}},
+		TestGroups:       []srvc.TestGroup{{Points: 3, TestIDs: []int{1, 2}}, {Points: 7, TestIDs: []int{3}}},
+		VisInpSubtaskIds: []int{1},
+	})
+	require.Nil(t, editErr)
+	require.Equal(t, 10, next.Subtasks[0].Score)
+	require.Len(t, next.VisInpSubtasks[0].Tests, 3)
+
+	_, editErr = service.SetTaskChecker(ctx, "summa", "int main() {}")
+	require.Nil(t, editErr)
+	require.Equal(t, "int main() {}", next.Checker)
+}
+
+func sha2ForTest(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
@@ -59,22 +59,58 @@ func errInvalidTaskZip(reason string) srvcerror.E {
 	return ErrInvalidTaskZip.WithMsg(fmt.Sprintf("nederīgs TaskZip: %s", reason))
 }
 
-var ErrUnsupportedTaskZip = srvcerror.New(
-	"unsupported_task_zip",
-	"neatbalstīts TaskZip",
+var ErrInvalidTaskPackage = srvcerror.New(
+	"invalid_task_package",
+	"nederīga uzdevuma pakotne",
 ).SetHttpStatusCode(http.StatusBadRequest)
 
-func errUnsupportedTaskZip(reason string) srvcerror.E {
-	return ErrUnsupportedTaskZip.WithMsg(fmt.Sprintf("neatbalstīts TaskZip: %s", reason))
+func errInvalidTaskPackage(format, reason string) srvcerror.E {
+	return ErrInvalidTaskPackage.WithMsg(fmt.Sprintf("nederīga %s pakotne: %s", format, reason))
 }
 
-var ErrTaskAlreadyExists = srvcerror.New(
-	"task_already_exists",
-	"uzdevums ar šādu ID jau eksistē",
-).SetHttpStatusCode(http.StatusConflict)
+var ErrUnsupportedTaskPackage = srvcerror.New(
+	"unsupported_task_package",
+	"neatbalstīta uzdevuma pakotne",
+).SetHttpStatusCode(http.StatusBadRequest)
+
+func errUnsupportedTaskPackage(format, reason string) srvcerror.E {
+	return ErrUnsupportedTaskPackage.WithMsg(fmt.Sprintf("neatbalstīta %s pakotne: %s", format, reason))
+}
+
+var ErrRevisionNotFound = srvcerror.New(
+	"revision_not_found",
+	"uzdevuma versija netika atrasta",
+).SetHttpStatusCode(http.StatusNotFound)
+
+func errRevisionNotFound(taskId string, revision int) srvcerror.E {
+	return ErrRevisionNotFound.WithMsg(fmt.Sprintf("uzdevumam '%s' nav versijas %d", taskId, revision))
+}
+
+var ErrInvalidVisibility = srvcerror.New(
+	"invalid_visibility",
+	"nederīga uzdevuma redzamība",
+).SetHttpStatusCode(http.StatusBadRequest)
+
+func errInvalidVisibility(reason string) srvcerror.E {
+	return ErrInvalidVisibility.WithMsg(fmt.Sprintf("nederīga uzdevuma redzamība: %s", reason))
+}
+
+var ErrInvalidTaskListQuery = srvcerror.New(
+	"invalid_task_list_query",
+	"nederīgs uzdevumu saraksta pieprasījums",
+).SetHttpStatusCode(http.StatusBadRequest)
+
+func errInvalidTaskListQuery(reason string) srvcerror.E {
+	return ErrInvalidTaskListQuery.WithMsg(fmt.Sprintf("nederīgs uzdevumu saraksta pieprasījums: %s", reason))
+}
 
-func errTaskAlreadyExists(taskId string) srvcerror.E {
-	return ErrTaskAlreadyExists.WithMsg(fmt.Sprintf("uzdevums ar ID '%s' jau eksistē", taskId))
+var ErrInvalidSearchQuery = srvcerror.New(
+	"invalid_search_query",
+	"nederīgs meklēšanas vaicājums",
+).SetHttpStatusCode(http.StatusBadRequest)
+
+func errInvalidSearchQuery(reason string) srvcerror.E {
+	return ErrInvalidSearchQuery.WithMsg(fmt.Sprintf("nederīgs meklēšanas vaicājums: %s", reason))
 }
 
 var ErrImageNotFound = srvcerror.New(
@@ -90,3 +126,41 @@ var ErrIllustrationNotFound = srvcerror.New(
 	"illustration_not_found",
 	"uzdevumam nav ilustrācijas",
 ).SetHttpStatusCode(http.StatusNotFound)
+
+var ErrSolutionChecksUnavailable = srvcerror.New(
+	"solution_checks_unavailable",
+	"risinājumu pārbaude nav pieejama",
+).SetHttpStatusCode(http.StatusServiceUnavailable)
+
+var ErrSolutionChecksRunning = srvcerror.New(
+	"solution_checks_running",
+	"uzdevuma risinājumi jau tiek pārbaudīti",
+).SetHttpStatusCode(http.StatusConflict)
+
+var ErrSolutionReportNotFound = srvcerror.New(
+	"solution_report_not_found",
+	"uzdevuma risinājumi vēl nav pārbaudīti",
+).SetHttpStatusCode(http.StatusNotFound)
+
+var ErrTestfileDownloadUnavailable = srvcerror.New(
+	"testfile_download_unavailable",
+	"testu lejupielāde nav konfigurēta",
+).SetHttpStatusCode(http.StatusServiceUnavailable)
+
+var ErrInvalidTaskEdit = srvcerror.New(
+	"invalid_task_edit",
+	"nederīgas uzdevuma izmaiņas",
+).SetHttpStatusCode(http.StatusBadRequest)
+
+func errInvalidTaskEdit(reason string) srvcerror.E {
+	return ErrInvalidTaskEdit.WithMsg(fmt.Sprintf("nederīgas uzdevuma izmaiņas: %s", reason))
+}
+
+var ErrTestNotFound = srvcerror.New(
+	"test_not_found",
+	"tests netika atrasts",
+).SetHttpStatusCode(http.StatusNotFound)
+
+func errTestNotFound(taskId string, testId int) srvcerror.E {
+	return ErrTestNotFound.WithMsg(fmt.Sprintf("uzdevumam '%s' nav testa %d", taskId, testId))
+}
@@ -0,0 +1,182 @@
+package srvc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/programme-lv/backend/common/filestore"
+	"github.com/programme-lv/backend/common/srvcerror"
+)
+
+// ReferencedObjects are the object keys that tasks and their revisions
+// refer to, in the form they are stored in the database.
+type ReferencedObjects struct {
+	// SHA256 hashes of tests and authoring files
+	TestSha2s []string
+	// stored keys, without their public store directory
+	IllustrKeys    []string
+	MdImageKeys    []string
+	AttachmentKeys []string
+	// original archive keys, used verbatim
+	ArchiveKeys []string
+}
+
+// TestfileRefLister returns the SHA256 hashes of test files that are
+// used outside of tasks, e.g. by evaluations kept for re-judging.
+type TestfileRefLister func(ctx context.Context) ([]string, error)
+
+// WithTestfileRefs makes garbage collection keep the test files
+// returned by listers.
+func WithTestfileRefs(listers ...TestfileRefLister) TaskSrvcOption {
+	return func(ts *taskSrvc) {
+		ts.testfileRefs = append(ts.testfileRefs, listers...)
+	}
+}
+
+// GCReport is the outcome of CollectGarbage.
+type GCReport struct {
+	DryRun    bool
+	Testfiles StoreGCReport
+	Public    StoreGCReport
+}
+
+// StoreGCReport counts the objects of one store. Sizes are in bytes.
+type StoreGCReport struct {
+	Objects int
+	Bytes   int64
+	// objects nothing refers to
+	Orphans     int
+	OrphanBytes int64
+	// orphans older than the grace period, deleted unless in a dry run
+	Expired      int
+	ExpiredBytes int64
+	Deleted      int
+	DeletedBytes int64
+}
+
+// gcPublicDirs are the public store directories whose object keys
+// the task service assigns. Other public objects are never collected.
+var gcPublicDirs = []string{taskIllustrationDir, taskStatementImageDir, taskAttachmentDir}
+
+var testfileKeyRE = regexp.MustCompile(`^[0-9a-f]{64}\.zst$`)
+
+// CollectGarbage deletes test files and public assets that no task,
+// task revision or test file ref lister refers to and that were last
+// modified more than grace ago. A dry run only reports them.
+//
+// References are read before the stores are listed, so an object
+// uploaded in between is younger than grace and kept.
+func (ts *taskSrvc) CollectGarbage(ctx context.Context, grace time.Duration, dryRun bool) (GCReport, srvcerror.E) {
+	l := ts.logger(ctx)
+	report := GCReport{DryRun: dryRun}
+	liveTestfiles, livePublic, err := ts.liveObjectKeys(ctx)
+	if err != nil {
+		l.Error("list referenced objects", "error", err)
+		return report, srvcerror.InternalServerError()
+	}
+	cutoff := time.Now().Add(-grace)
+
+	stored, err := ts.testfileStore.List("")
+	if err != nil {
+		l.Error("list test files", "error", err)
+		return report, srvcerror.InternalServerError()
+	}
+	var testfiles []filestore.Object
+	for _, obj := range stored {
+		if testfileKeyRE.MatchString(obj.Key) {
+			testfiles = append(testfiles, obj)
+		}
+	}
+	report.Testfiles, err = ts.collectStore(ctx, ts.testfileStore, testfiles, liveTestfiles, cutoff, dryRun)
+	if err != nil {
+		l.Error("collect test files", "error", err)
+		return report, srvcerror.InternalServerError()
+	}
+
+	var assets []filestore.Object
+	for _, dir := range gcPublicDirs {
+		stored, err := ts.publicStore.List(dir)
+		if err != nil {
+			l.Error("list public assets", "dir", dir, "error", err)
+			return report, srvcerror.InternalServerError()
+		}
+		assets = append(assets, stored...)
+	}
+	report.Public, err = ts.collectStore(ctx, ts.publicStore, assets, livePublic, cutoff, dryRun)
+	if err != nil {
+		l.Error("collect public assets", "error", err)
+		return report, srvcerror.InternalServerError()
+	}
+	return report, nil
+}
+
+// liveObjectKeys returns the referenced object keys
+// of the test file store and of the public store.
+func (ts *taskSrvc) liveObjectKeys(ctx context.Context) (map[string]bool, map[string]bool, error) {
+	refs, err := ts.repo.ListReferencedObjects(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	testSha2s := refs.TestSha2s
+	for _, list := range ts.testfileRefs {
+		sha2s, err := list(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("list test file refs: %w", err)
+		}
+		testSha2s = append(testSha2s, sha2s...)
+	}
+	testfiles := make(map[string]bool, len(testSha2s))
+	for _, sha2 := range testSha2s {
+		testfiles[sha2+".zst"] = true
+	}
+
+	public := make(map[string]bool)
+	for _, key := range refs.IllustrKeys {
+		public[taskIllustrationObjectKey(key)] = true
+	}
+	for _, key := range refs.MdImageKeys {
+		public[taskStatementImageObjectKey(key)] = true
+	}
+	for _, key := range refs.AttachmentKeys {
+		public[taskAttachmentObjectKey(key)] = true
+	}
+	for _, key := range refs.ArchiveKeys {
+		public[key] = true
+	}
+	return testfiles, public, nil
+}
+
+// collectStore counts objects and deletes the orphans
+// last modified before cutoff unless dryRun is set.
+func (ts *taskSrvc) collectStore(
+	ctx context.Context, store ObjectStore, objects []filestore.Object,
+	live map[string]bool, cutoff time.Time, dryRun bool,
+) (StoreGCReport, error) {
+	var report StoreGCReport
+	for _, obj := range objects {
+		report.Objects++
+		report.Bytes += obj.Size
+		if live[obj.Key] {
+			continue
+		}
+		report.Orphans++
+		report.OrphanBytes += obj.Size
+		if !obj.ModTime.Before(cutoff) {
+			continue
+		}
+		report.Expired++
+		report.ExpiredBytes += obj.Size
+		if dryRun {
+			continue
+		}
+		if err := store.Delete(obj.Key); err != nil {
+			return report, fmt.Errorf("delete %s: %w", obj.Key, err)
+		}
+		ts.logger(ctx).Debug("deleted orphaned object", "object_key", obj.Key, "size", obj.Size)
+		report.Deleted++
+		report.DeletedBytes += obj.Size
+	}
+	return report, nil
+}
@@ -0,0 +1,97 @@
+package srvc_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/programme-lv/backend/common/filestore"
+	"github.com/programme-lv/backend/gen/mocks/mocktasksrvc"
+	"github.com/programme-lv/backend/modules/task/srvc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	publicStore, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	testfileStore, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	service := srvc.NewTaskSrvc(repo, publicStore, testfileStore,
+		srvc.WithTestfileRefs(func(context.Context) ([]string, error) {
+			return []string{sha2ForTest("evaluated\n")}, nil
+		}),
+	)
+
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	upload := func(store *filestore.Store, key string, data string, modTime time.Time) {
+		_, err := store.Upload([]byte(data), key, "application/octet-stream")
+		require.NoError(t, err)
+		fullPath, err := store.Path(key)
+		require.NoError(t, err)
+		require.NoError(t, os.Chtimes(fullPath, modTime, modTime))
+	}
+	for _, data := range []string{"live\n", "evaluated\n", "orphan\n", "reused\n"} {
+		upload(testfileStore, sha2ForTest(data)+".zst", data, old)
+	}
+	upload(testfileStore, sha2ForTest("recent\n")+".zst", "recent\n", time.Now())
+	upload(testfileStore, "notes.txt", "not a test file", old)
+	upload(publicStore, "illustrations/live.png", "png", old)
+	upload(publicStore, "md-images/summa/orphan.png", "png!", old)
+	upload(publicStore, "attachments/summa/0123456789ab/grader.h", "int f();", old)
+	upload(publicStore, "task/legacy.png", "png", old)
+
+	repo.EXPECT().ListReferencedObjects(ctx).Return(srvc.ReferencedObjects{
+		TestSha2s:      []string{sha2ForTest("live\n")},
+		IllustrKeys:    []string{"live.png"},
+		AttachmentKeys: []string{"summa/0123456789ab/grader.h"},
+	}, nil)
+
+	// a re-upload refreshes the modification time of an orphan
+	require.Nil(t, service.UploadTestFile(ctx, []byte("reused\n")))
+
+	report, gcErr := service.CollectGarbage(ctx, 7*24*time.Hour, true)
+	require.Nil(t, gcErr)
+	require.True(t, report.DryRun)
+	require.Equal(t, 5, report.Testfiles.Objects)
+	require.Equal(t, 3, report.Testfiles.Orphans)
+	require.Equal(t, 1, report.Testfiles.Expired)
+	require.Equal(t, int64(len("orphan\n")), report.Testfiles.ExpiredBytes)
+	require.Zero(t, report.Testfiles.Deleted)
+	require.Equal(t, 3, report.Public.Objects)
+	require.Equal(t, 1, report.Public.Expired)
+	require.Equal(t, int64(4), report.Public.ExpiredBytes)
+	exists, err := testfileStore.Exists(sha2ForTest("orphan\n") + ".zst")
+	require.NoError(t, err)
+	require.True(t, exists, "a dry run must not delete")
+
+	report, gcErr = service.CollectGarbage(ctx, 7*24*time.Hour, false)
+	require.Nil(t, gcErr)
+	require.Equal(t, 1, report.Testfiles.Deleted)
+	require.Equal(t, 1, report.Public.Deleted)
+
+	for key, want := range map[string]bool{
+		sha2ForTest("live\n") + ".zst":      true,
+		sha2ForTest("evaluated\n") + ".zst": true,
+		sha2ForTest("reused\n") + ".zst":    true,
+		sha2ForTest("recent\n") + ".zst":    true,
+		sha2ForTest("orphan\n") + ".zst":    false,
+		"notes.txt":                         true,
+	} {
+		exists, err := testfileStore.Exists(key)
+		require.NoError(t, err)
+		require.Equal(t, want, exists, key)
+	}
+	for key, want := range map[string]bool{
+		"illustrations/live.png":                  true,
+		"md-images/summa/orphan.png":              false,
+		"attachments/summa/0123456789ab/grader.h": true,
+		"task/legacy.png":                         true,
+	} {
+		exists, err := publicStore.Exists(key)
+		require.NoError(t, err)
+		require.Equal(t, want, exists, key)
+	}
+}
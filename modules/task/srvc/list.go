@@ -0,0 +1,223 @@
+package srvc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/programme-lv/backend/common/srvcerror"
+)
+
+// TaskSort orders the task list.
+type TaskSort string
+
+const (
+	// TaskSortDefault orders tasks by short ID.
+	TaskSortDefault TaskSort = ""
+	// TaskSortName orders tasks by default full name in Latvian alphabetical order.
+	TaskSortName       TaskSort = "name"
+	TaskSortDifficulty TaskSort = "difficulty"
+	// TaskSortYear orders tasks by normalized edition year;
+	// tasks without a year come last.
+	TaskSortYear TaskSort = "year"
+	// TaskSortSolves orders tasks by how many users have fully solved them.
+	TaskSortSolves TaskSort = "solves"
+)
+
+const (
+	DefaultTaskListLimit = 100
+	MaxTaskListLimit     = 500
+)
+
+// TaskListQuery selects one page of the task list. Olympiad, Year, Stage
+// and Division take the ids of [BuildFilterTree]; empty fields match all.
+type TaskListQuery struct {
+	Olympiad string
+	Year     string
+	Stage    string
+	Division string
+
+	// inclusive difficulty range, 0 for unbounded
+	MinDifficulty int
+	MaxDifficulty int
+
+	// tasks must have every one of these problem tags
+	Tags []string
+
+	Sort TaskSort
+	Desc bool
+
+	Offset int
+	// Limit defaults to DefaultTaskListLimit
+	Limit int
+
+	IncludeUnlisted bool
+}
+
+// TaskPage is one page of the task list and
+// how many tasks match the query in total.
+type TaskPage struct {
+	Tasks []TaskPreview
+	Total int
+}
+
+// TaskPreviewQuery is a [TaskListQuery] resolved to stored column
+// values, so that the repository can filter, order and page in SQL.
+type TaskPreviewQuery struct {
+	IncludeUnlisted bool
+
+	// Origins are the stored origin tuples a task must have one of;
+	// their counts are ignored. nil keeps tasks of every origin.
+	Origins []OriginCount
+
+	MinDifficulty int
+	MaxDifficulty int
+	Tags          []string
+
+	Sort TaskSort
+	Desc bool
+	// YearKeys maps stored origin years to edition years for
+	// TaskSortYear; tasks whose year is missing sort last.
+	YearKeys map[string]int
+	// Solves maps task short IDs to solve counts for TaskSortSolves.
+	Solves map[string]int
+
+	Offset int
+	Limit  int
+}
+
+// SolveCounter returns the number of distinct users who have fully
+// solved each of the tasks, keyed by task short ID.
+// nil taskIds counts every task.
+type SolveCounter func(ctx context.Context, taskIds []string) (map[string]int, error)
+
+// WithSolveCounter enables sorting the task list by solve count.
+// Without it every task has zero solves.
+func WithSolveCounter(counter SolveCounter) TaskSrvcOption {
+	return func(ts *taskSrvc) {
+		ts.solveCounter = counter
+	}
+}
+
+// ListTaskPreviews returns the page of task previews selected by q.
+// Only listed tasks are considered unless q.IncludeUnlisted is set.
+// Solves are counted for the page only, or for every task when the
+// list is sorted by them.
+func (ts *taskSrvc) ListTaskPreviews(ctx context.Context, q TaskListQuery) (TaskPage, srvcerror.E) {
+	if err := q.validate(); err != nil {
+		return TaskPage{}, err
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultTaskListLimit
+	}
+
+	pq, err := ts.resolveTaskListQuery(ctx, q)
+	if err != nil {
+		ts.logger(ctx).Error("resolve task list query", "error", err)
+		return TaskPage{}, srvcerror.InternalServerError()
+	}
+	previews, total, err := ts.repo.ListTaskPreviews(ctx, pq)
+	if err != nil {
+		ts.logger(ctx).Error("list task previews", "error", err)
+		return TaskPage{}, srvcerror.InternalServerError()
+	}
+	solves := pq.Solves
+	if ts.solveCounter != nil && solves == nil && len(previews) > 0 {
+		taskIds := make([]string, len(previews))
+		for i, p := range previews {
+			taskIds[i] = p.ShortId
+		}
+		if solves, err = ts.solveCounter(ctx, taskIds); err != nil {
+			ts.logger(ctx).Error("count task solves", "error", err)
+			return TaskPage{}, srvcerror.InternalServerError()
+		}
+	}
+	for i := range previews {
+		previews[i].SolveCount = solves[previews[i].ShortId]
+		applyPreviewOriginNotes(&previews[i])
+	}
+	return TaskPage{Tasks: previews, Total: total}, nil
+}
+
+// resolveTaskListQuery maps the filter tree ids of q to the stored
+// origins they normalize from, the same way as BuildFilterTree.
+func (ts *taskSrvc) resolveTaskListQuery(ctx context.Context, q TaskListQuery) (TaskPreviewQuery, error) {
+	pq := TaskPreviewQuery{
+		IncludeUnlisted: q.IncludeUnlisted,
+		MinDifficulty:   q.MinDifficulty,
+		MaxDifficulty:   q.MaxDifficulty,
+		Tags:            q.Tags,
+		Sort:            q.Sort,
+		Desc:            q.Desc,
+		Offset:          q.Offset,
+		Limit:           q.Limit,
+	}
+	filtersOrigin := q.Olympiad != "" || q.Year != "" || q.Stage != "" || q.Division != ""
+	if filtersOrigin || q.Sort == TaskSortYear {
+		origins, err := ts.repo.ListOriginCounts(ctx, q.IncludeUnlisted)
+		if err != nil {
+			return pq, err
+		}
+		if filtersOrigin {
+			pq.Origins = make([]OriginCount, 0, len(origins))
+			for _, origin := range origins {
+				if q.matchesOrigin(origin) {
+					pq.Origins = append(pq.Origins, origin)
+				}
+			}
+		}
+		if q.Sort == TaskSortYear {
+			pq.YearKeys = make(map[string]int)
+			for _, origin := range origins {
+				if year, err := strconv.Atoi(NormalizeYear(origin.Year)); err == nil {
+					pq.YearKeys[origin.Year] = year
+				}
+			}
+		}
+	}
+	if q.Sort == TaskSortSolves && ts.solveCounter != nil {
+		solves, err := ts.solveCounter(ctx, nil)
+		if err != nil {
+			return pq, fmt.Errorf("count task solves: %w", err)
+		}
+		pq.Solves = solves
+	}
+	return pq, nil
+}
+
+func (q TaskListQuery) validate() srvcerror.E {
+	switch q.Sort {
+	case TaskSortDefault, TaskSortName, TaskSortDifficulty, TaskSortYear, TaskSortSolves:
+	default:
+		return errInvalidTaskListQuery("nezināma kārtošana '" + string(q.Sort) + "'")
+	}
+	if q.Offset < 0 {
+		return errInvalidTaskListQuery("nobīde nevar būt negatīva")
+	}
+	if q.Limit < 0 || q.Limit > MaxTaskListLimit {
+		return errInvalidTaskListQuery("limitam jābūt no 1 līdz " + strconv.Itoa(MaxTaskListLimit))
+	}
+	if q.MinDifficulty < 0 || q.MaxDifficulty < 0 ||
+		(q.MaxDifficulty > 0 && q.MinDifficulty > q.MaxDifficulty) {
+		return errInvalidTaskListQuery("nederīgs grūtības intervāls")
+	}
+	return nil
+}
+
+// matchesOrigin normalizes the stored origin the same way as BuildFilterTree.
+func (q TaskListQuery) matchesOrigin(o OriginCount) bool {
+	if q.Olympiad != "" && NormalizeOlympiad(o.Olympiad) != q.Olympiad {
+		return false
+	}
+	if q.Year != "" && NormalizeYear(o.Year) != q.Year {
+		return false
+	}
+	if q.Stage != "" && strings.TrimSpace(o.Stage) != q.Stage {
+		return false
+	}
+	if q.Division != "" && divisionKind(o.Divisions) != q.Division {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package srvc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/programme-lv/backend/common/filestore"
+	"github.com/programme-lv/backend/gen/mocks/mocktasksrvc"
+	"github.com/programme-lv/backend/modules/task/srvc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTaskPreviewsResolvesQuery(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	var counted [][]string
+	service := srvc.NewTaskSrvc(repo, store, store,
+		srvc.WithSolveCounter(func(_ context.Context, taskIds []string) (map[string]int, error) {
+			counted = append(counted, taskIds)
+			return map[string]int{"cuska": 7, "summa": 3}, nil
+		}),
+	)
+
+	origins := []srvc.OriginCount{
+		{Olympiad: "LIO", Year: "2024/2025", Stage: "national", Divisions: []string{"junior", "senior"}, Count: 1},
+		{Olympiad: "", Year: "", Stage: "", Divisions: []string{}, Count: 1},
+		{Olympiad: "LIO", Year: "2023", Stage: "school", Divisions: []string{"junior"}, Count: 1},
+	}
+	list := func(q srvc.TaskListQuery, want srvc.TaskPreviewQuery) srvc.TaskPage {
+		repo.EXPECT().ListTaskPreviews(ctx, want).Return([]srvc.TaskPreview{{ShortId: "cuska"}}, 3, nil).Once()
+		page, listErr := service.ListTaskPreviews(ctx, q)
+		require.Nil(t, listErr)
+		return page
+	}
+	withOrigins := func() {
+		repo.EXPECT().ListOriginCounts(ctx, false).Return(origins, nil).Once()
+	}
+
+	page := list(srvc.TaskListQuery{}, srvc.TaskPreviewQuery{Limit: srvc.DefaultTaskListLimit})
+	require.Equal(t, 3, page.Total)
+	require.Equal(t, 7, page.Tasks[0].SolveCount)
+	require.Equal(t, [][]string{{"cuska"}}, counted, "solves are counted for the page only")
+
+	withOrigins()
+	list(srvc.TaskListQuery{Olympiad: "LIO", Year: "2025", Division: "both"}, srvc.TaskPreviewQuery{
+		Origins: origins[:1], Limit: srvc.DefaultTaskListLimit,
+	})
+	withOrigins()
+	list(srvc.TaskListQuery{Olympiad: "other"}, srvc.TaskPreviewQuery{
+		Origins: origins[1:2], Limit: srvc.DefaultTaskListLimit,
+	})
+	withOrigins()
+	list(srvc.TaskListQuery{Stage: "municipal"}, srvc.TaskPreviewQuery{
+		Origins: []srvc.OriginCount{}, Limit: srvc.DefaultTaskListLimit,
+	})
+	list(srvc.TaskListQuery{Tags: []string{"bfs", "graphs"}, MaxDifficulty: 2, Offset: 1, Limit: 1},
+		srvc.TaskPreviewQuery{Tags: []string{"bfs", "graphs"}, MaxDifficulty: 2, Offset: 1, Limit: 1})
+
+	withOrigins()
+	list(srvc.TaskListQuery{Sort: srvc.TaskSortYear, Desc: true}, srvc.TaskPreviewQuery{
+		Sort: srvc.TaskSortYear, Desc: true, Limit: srvc.DefaultTaskListLimit,
+		YearKeys: map[string]int{"2024/2025": 2025, "2023": 2023},
+	})
+
+	counted = nil
+	list(srvc.TaskListQuery{Sort: srvc.TaskSortSolves}, srvc.TaskPreviewQuery{
+		Sort: srvc.TaskSortSolves, Limit: srvc.DefaultTaskListLimit,
+		Solves: map[string]int{"cuska": 7, "summa": 3},
+	})
+	require.Equal(t, [][]string{nil}, counted, "sorting by solves counts every task once")
+
+	_, listErr := service.ListTaskPreviews(ctx, srvc.TaskListQuery{Sort: "popularity"})
+	require.ErrorIs(t, listErr, srvc.ErrInvalidTaskListQuery)
+	_, listErr = service.ListTaskPreviews(ctx, srvc.TaskListQuery{MinDifficulty: 3, MaxDifficulty: 2})
+	require.ErrorIs(t, listErr, srvc.ErrInvalidTaskListQuery)
+}
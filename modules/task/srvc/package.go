@@ -0,0 +1,71 @@
+package srvc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/programme-lv/backend/common/srvcerror"
+	"github.com/programme-lv/backend/modules/task/cms"
+	"github.com/programme-lv/backend/modules/task/polygon"
+	taskzipv1 "github.com/programme-lv/backend/modules/task/taskzip"
+)
+
+// ImportTaskFromPolygon creates a task from a zipped Polygon full
+// package, or a new revision of it, the same way as ImportTaskFromZip.
+// Problem tags known to the TaskZip metadata lists are kept.
+func (ts *taskSrvc) ImportTaskFromPolygon(
+	ctx context.Context, zipBytes []byte, overrideID string, opts ...ImportOption,
+) (string, srvcerror.E) {
+	pkg, err := polygon.Read(zipBytes)
+	if err != nil {
+		return "", taskPackageReadError("Polygon", err, polygon.ErrUnsupported)
+	}
+	return ts.importTaskPackage(ctx, "Polygon", pkg.Task, pkg.Tags, overrideID, opts)
+}
+
+// ImportTaskFromCMS creates a task from a zipped CMS task directory,
+// or a new revision of it, the same way as ImportTaskFromZip.
+func (ts *taskSrvc) ImportTaskFromCMS(
+	ctx context.Context, zipBytes []byte, overrideID string, opts ...ImportOption,
+) (string, srvcerror.E) {
+	archive, err := cms.Read(zipBytes)
+	if err != nil {
+		return "", taskPackageReadError("CMS", err, cms.ErrUnsupported)
+	}
+	return ts.importTaskPackage(ctx, "CMS", archive, nil, overrideID, opts)
+}
+
+func taskPackageReadError(format string, err, unsupported error) srvcerror.E {
+	if errors.Is(err, unsupported) {
+		return errUnsupportedTaskPackage(format, err.Error())
+	}
+	return errInvalidTaskPackage(format, err.Error())
+}
+
+// importTaskPackage validates a package converted to TaskZip and imports it.
+func (ts *taskSrvc) importTaskPackage(
+	ctx context.Context, format string, archive taskzipv1.Task, tags []string,
+	overrideID string, opts []ImportOption,
+) (string, srvcerror.E) {
+	o, optErr := ts.applyImportOptions(opts)
+	if optErr != nil {
+		return "", optErr
+	}
+	if overrideID != "" {
+		archive.ID = overrideID
+	}
+	if err := taskzipv1.Validate(&archive); err != nil {
+		return "", errInvalidTaskPackage(format, err.Error())
+	}
+	task, err := mapFromTaskZip(archive, "")
+	if err != nil {
+		return "", errInvalidTaskPackage(format, err.Error())
+	}
+	task.ProblemTags = appendAllowed(task.ProblemTags, tags, taskZipTopics)
+	task.Techniques = appendAllowed(task.Techniques, tags, taskZipTechniques)
+	task.DataStructures = appendAllowed(task.DataStructures, tags, taskZipDataStructures)
+	if err := prepareTaskZipImages(archive, &task); err != nil {
+		return "", errInvalidTaskPackage(format, err.Error())
+	}
+	return ts.importTask(ctx, archive, task, o)
+}
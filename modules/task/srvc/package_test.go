@@ -0,0 +1,143 @@
+package srvc_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/programme-lv/backend/common/filestore"
+	"github.com/programme-lv/backend/gen/mocks/mocktasksrvc"
+	"github.com/programme-lv/backend/modules/task/srvc"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const polygonProblemXML = `<problem short-name="echo">
+  <names><name language="latvian" value="Atbalss"/></names>
+  <statements><statement language="latvian" path="statements/latvian/problem.tex" type="application/x-tex"/></statements>
+  <judging><testset name="tests">
+    <time-limit>1000</time-limit><memory-limit>268435456</memory-limit>
+    <input-path-pattern>tests/%02d</input-path-pattern>
+    <answer-path-pattern>tests/%02d.a</answer-path-pattern>
+    <tests><test sample="true"/><test/></tests>
+  </testset></judging>
+  <assets>
+    <checker type="testlib"><source path="files/check.cpp"/></checker>
+    INTERACTOR
+    <solutions><solution tag="main"><source path="solutions/echo.py"/></solution></solutions>
+  </assets>
+  <tags><tag value="implementation"/><tag value="Brute Force"/><tag value="*special"/></tags>
+</problem>`
+
+func polygonZipForTest(t *testing.T, interactor string) []byte {
+	t.Helper()
+	return zipStringsForTest(t, map[string]string{
+		"problem.xml":                           strings.Replace(polygonProblemXML, "INTERACTOR", interactor, 1),
+		"files/check.cpp":                       "#include \"testlib.h\"\n",
+		"solutions/echo.py":                     "print(input())\n",
+		"statement-sections/latvian/legend.tex": "Izvadiet \\textbf{to pašu}.",
+		"statement-sections/latvian/input.tex":  "Vesels skaitlis $n$.",
+		"statement-sections/latvian/output.tex": "Skaitlis $n$.",
+		"tests/01":                              "1\n",
+		"tests/01.a":                            "1\n",
+		"tests/02":                              "2\n",
+		"tests/02.a":                            "2\n",
+	})
+}
+
+func zipStringsForTest(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return out.Bytes()
+}
+
+func TestImportTaskFromPolygon(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	service := srvc.NewTaskSrvc(repo, store, store)
+
+	var imported srvc.Task
+	repo.EXPECT().Exists(ctx, "echo-lv").Return(false, nil).Once()
+	repo.EXPECT().CreateTask(ctx, mock.Anything).RunAndReturn(
+		func(_ context.Context, task srvc.Task) error {
+			imported = task
+			return nil
+		},
+	).Once()
+
+	id, importErr := service.ImportTaskFromPolygon(ctx, polygonZipForTest(t, ""), "echo-lv")
+	require.NoError(t, importErr)
+	require.Equal(t, "echo-lv", id)
+	require.Equal(t, "Atbalss", imported.FullName["lv"])
+	require.Equal(t, 1.0, imported.CpuTimeLimSecs)
+	require.Equal(t, 256, imported.MemLimMegabytes)
+	require.Len(t, imported.Tests, 2)
+	require.Len(t, imported.Examples, 1)
+	require.Empty(t, imported.Subtasks)
+	require.Len(t, imported.Solutions, 1)
+	require.Equal(t, "Izvadiet **to pašu**.", imported.MdStatements[0].Story)
+	require.Equal(t, "Vesels skaitlis $n$.", imported.MdStatements[0].Input)
+	require.Equal(t, []string{"implementation"}, imported.ProblemTags)
+	require.Equal(t, []string{"brute-force"}, imported.Techniques)
+
+	_, importErr = service.ImportTaskFromPolygon(ctx,
+		polygonZipForTest(t, `<interactor><source path="files/interactor.cpp"/></interactor>`), "")
+	require.ErrorIs(t, importErr, srvc.ErrUnsupportedTaskPackage)
+
+	_, importErr = service.ImportTaskFromPolygon(ctx, []byte("not a zip"), "")
+	require.ErrorIs(t, importErr, srvc.ErrInvalidTaskPackage)
+}
+
+func TestImportTaskFromCMS(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	service := srvc.NewTaskSrvc(repo, store, store)
+
+	files := map[string]string{
+		"task.yaml":              "name: echo\ntitle: Atbalss\ntime_limit: 0.5\nmemory_limit: 64\nn_input: 2\nprimary_language: lv\n",
+		"gen/GEN":                "# ST: 30\n1\n# ST: 70\n2\n",
+		"statement/statement.md": "Izvadiet to pašu.\n\nIevaddati\n---------\nVesels skaitlis $n$.\n",
+		"input/input0.txt":       "1\n",
+		"output/output0.txt":     "1\n",
+		"input/input1.txt":       "2\n",
+		"output/output1.txt":     "2\n",
+	}
+	var imported srvc.Task
+	repo.EXPECT().Exists(ctx, "echo").Return(false, nil).Once()
+	repo.EXPECT().CreateTask(ctx, mock.Anything).RunAndReturn(
+		func(_ context.Context, task srvc.Task) error {
+			imported = task
+			return nil
+		},
+	).Once()
+
+	id, importErr := service.ImportTaskFromCMS(ctx, zipStringsForTest(t, files), "")
+	require.NoError(t, importErr)
+	require.Equal(t, "echo", id)
+	require.Equal(t, "Atbalss", imported.FullName["lv"])
+	require.Equal(t, 0.5, imported.CpuTimeLimSecs)
+	require.Equal(t, 64, imported.MemLimMegabytes)
+	require.Len(t, imported.Tests, 2)
+	require.Len(t, imported.Subtasks, 2)
+	require.Equal(t, 70, imported.Subtasks[1].Score)
+	require.Equal(t, []int{2}, imported.Subtasks[1].TestIDs)
+	require.Equal(t, "Vesels skaitlis $n$.", imported.MdStatements[0].Input)
+
+	files["check/manager.cpp"] = "int main() {}\n"
+	_, importErr = service.ImportTaskFromCMS(ctx, zipStringsForTest(t, files), "")
+	require.ErrorIs(t, importErr, srvc.ErrUnsupportedTaskPackage)
+}
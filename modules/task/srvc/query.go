@@ -34,23 +34,10 @@ func (ts *taskSrvc) GetTaskPreview(ctx context.Context, id string) (res TaskPrev
 	return taskPreview, nil
 }
 
-// ListTaskPreviews returns up to 100 task previews.
-func (ts *taskSrvc) ListTaskPreviews(ctx context.Context) ([]TaskPreview, srvcerror.E) {
-	taskPreviews, err := ts.repo.ListTaskPreviews(ctx, 100, 0)
-	if err != nil {
-		ts.logger(ctx).Error("list task previews", "error", err)
-		return nil, srvcerror.InternalServerError()
-	}
-	for i := range taskPreviews {
-		applyPreviewOriginNotes(&taskPreviews[i])
-	}
-	return taskPreviews, nil
-}
-
 // ListTaskFilters returns the origin catalog for the public task list.
-// The tree is built from every task row, not the 100-preview list cap.
-func (ts *taskSrvc) ListTaskFilters(ctx context.Context) (FilterTree, srvcerror.E) {
-	rows, err := ts.repo.ListOriginCounts(ctx)
+// Only listed tasks are counted unless includeUnlisted is set.
+func (ts *taskSrvc) ListTaskFilters(ctx context.Context, includeUnlisted bool) (FilterTree, srvcerror.E) {
+	rows, err := ts.repo.ListOriginCounts(ctx, includeUnlisted)
 	if err != nil {
 		ts.logger(ctx).Error("list origin counts", "error", err)
 		return FilterTree{}, srvcerror.InternalServerError()
@@ -62,6 +49,23 @@ func (ts *taskSrvc) ListTaskFilters(ctx context.Context) (FilterTree, srvcerror.
 var itTaskNote string
 
 func (ts *taskSrvc) GetTask(ctx context.Context, id string) (Task, srvcerror.E) {
+	task, getErr := ts.getStoredTask(ctx, id)
+	if getErr != nil {
+		return Task{}, getErr
+	}
+	if task.Interactor != "" {
+		for i := range task.MdStatements {
+			if task.MdStatements[i].Notes == "" {
+				task.MdStatements[i].Notes = itTaskNote
+			}
+		}
+	}
+	return task, nil
+}
+
+// getStoredTask returns the task as stored, without the generated
+// interactive-task note, so that exports do not persist it.
+func (ts *taskSrvc) getStoredTask(ctx context.Context, id string) (Task, srvcerror.E) {
 	exists, err := ts.repo.Exists(ctx, id)
 	if err != nil {
 		ts.logger(ctx).Error("check if task exists", "error", err)
@@ -76,13 +80,6 @@ func (ts *taskSrvc) GetTask(ctx context.Context, id string) (Task, srvcerror.E)
 		return Task{}, srvcerror.InternalServerError()
 	}
 	applyOriginNotes(&task)
-	if task.Interactor != "" {
-		for i := range task.MdStatements {
-			if task.MdStatements[i].Notes == "" {
-				task.MdStatements[i].Notes = itTaskNote
-			}
-		}
-	}
 	return task, nil
 }
 
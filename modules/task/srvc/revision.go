@@ -0,0 +1,250 @@
+package srvc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/programme-lv/backend/common/srvcerror"
+)
+
+// TaskRevision is one version of a task's content. Re-importing a task
+// and rolling it back create revisions; statement and image edits
+// change the current revision in place.
+type TaskRevision struct {
+	Revision int
+	// CreatedAt is zero for the first revision of tasks
+	// created before revisions were kept
+	CreatedAt time.Time
+	Current   bool
+}
+
+// RevisionChange describes how one part of a task differs between revisions.
+type RevisionChange struct {
+	Field  string
+	Detail string
+}
+
+type RevisionDiff struct {
+	From    int
+	To      int
+	Changes []RevisionChange
+}
+
+// ListTaskRevisions returns the revisions of a task, oldest first.
+func (ts *taskSrvc) ListTaskRevisions(ctx context.Context, taskId string) ([]TaskRevision, srvcerror.E) {
+	task, err := ts.getRawTask(ctx, taskId)
+	if err != nil {
+		return nil, err
+	}
+	revisions, repoErr := ts.repo.ListTaskRevisions(ctx, taskId)
+	if repoErr != nil {
+		ts.logger(ctx).Error("list task revisions", "task_id", taskId, "error", repoErr)
+		return nil, srvcerror.InternalServerError()
+	}
+	current := slices.IndexFunc(revisions, func(r TaskRevision) bool {
+		return r.Revision == task.Revision
+	})
+	if current < 0 {
+		revisions = append(revisions, TaskRevision{Revision: task.Revision})
+		current = len(revisions) - 1
+	}
+	revisions[current].Current = true
+	return revisions, nil
+}
+
+// DiffTaskRevisions lists what changed from one revision to another.
+func (ts *taskSrvc) DiffTaskRevisions(ctx context.Context, taskId string, from, to int) (RevisionDiff, srvcerror.E) {
+	current, err := ts.getRawTask(ctx, taskId)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	a, err := ts.getTaskRevision(ctx, current, from)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	b, err := ts.getTaskRevision(ctx, current, to)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	return RevisionDiff{From: from, To: to, Changes: diffTasks(a, b)}, nil
+}
+
+// RollbackTask restores the content of an earlier revision
+// as a new revision and returns its number.
+func (ts *taskSrvc) RollbackTask(ctx context.Context, taskId string, revision int) (int, srvcerror.E) {
+	current, err := ts.getRawTask(ctx, taskId)
+	if err != nil {
+		return 0, err
+	}
+	restored, err := ts.getTaskRevision(ctx, current, revision)
+	if err != nil {
+		return 0, err
+	}
+	next, err := ts.reviseTask(ctx, current, restored)
+	if err != nil {
+		return 0, err
+	}
+	ts.logger(ctx).Info("task rolled back", "task_id", taskId,
+		"from_revision", revision, "revision", next)
+	return next, nil
+}
+
+// reviseTask stores next as the revision after prev and returns its number.
+func (ts *taskSrvc) reviseTask(ctx context.Context, prev, next Task) (int, srvcerror.E) {
+	next.ShortId = prev.ShortId
+	next.Revision = prev.Revision + 1
+	if err := ts.repo.ReviseTask(ctx, prev, next); err != nil {
+		ts.logger(ctx).Error("revise task", "task_id", prev.ShortId, "error", err)
+		return 0, srvcerror.InternalServerError()
+	}
+	return next.Revision, nil
+}
+
+// getRawTask returns the task without generated origin notes,
+// in the same form as revision snapshots.
+func (ts *taskSrvc) getRawTask(ctx context.Context, id string) (Task, srvcerror.E) {
+	exists, err := ts.repo.Exists(ctx, id)
+	if err != nil {
+		ts.logger(ctx).Error("check if task exists", "error", err)
+		return Task{}, srvcerror.InternalServerError()
+	}
+	if !exists {
+		return Task{}, errTaskNotFound(id)
+	}
+	task, err := ts.repo.GetTask(ctx, id)
+	if err != nil {
+		ts.logger(ctx).Error("get task", "error", err)
+		return Task{}, srvcerror.InternalServerError()
+	}
+	return task, nil
+}
+
+func (ts *taskSrvc) getTaskRevision(ctx context.Context, current Task, revision int) (Task, srvcerror.E) {
+	if revision == current.Revision {
+		return current, nil
+	}
+	task, err := ts.repo.GetTaskRevision(ctx, current.ShortId, revision)
+	if err != nil {
+		ts.logger(ctx).Error("get task revision", "task_id", current.ShortId,
+			"revision", revision, "error", err)
+		return Task{}, srvcerror.InternalServerError()
+	}
+	if task == nil {
+		return Task{}, errRevisionNotFound(current.ShortId, revision)
+	}
+	return *task, nil
+}
+
+// diffTasks compares the parts of a task that affect grading
+// and what contestants see.
+func diffTasks(a, b Task) []RevisionChange {
+	var changes []RevisionChange
+	add := func(field, detail string) {
+		changes = append(changes, RevisionChange{Field: field, Detail: detail})
+	}
+	if a.CpuTimeLimSecs != b.CpuTimeLimSecs {
+		add("cpu_time_lim_secs", fmt.Sprintf("%g → %g", a.CpuTimeLimSecs, b.CpuTimeLimSecs))
+	}
+	if a.MemLimMegabytes != b.MemLimMegabytes {
+		add("mem_lim_megabytes", fmt.Sprintf("%d → %d", a.MemLimMegabytes, b.MemLimMegabytes))
+	}
+	if detail := diffSource(a.Checker, b.Checker); detail != "" {
+		add("checker", detail)
+	}
+	if detail := diffSource(a.Interactor, b.Interactor); detail != "" {
+		add("interactor", detail)
+	}
+	for i := 0; i < min(len(a.Tests), len(b.Tests)); i++ {
+		if a.Tests[i].InpSha2 != b.Tests[i].InpSha2 {
+			add("tests", fmt.Sprintf("test %d: input changed", i+1))
+		}
+		if a.Tests[i].AnsSha2 != b.Tests[i].AnsSha2 {
+			add("tests", fmt.Sprintf("test %d: answer changed", i+1))
+		}
+	}
+	if len(a.Tests) < len(b.Tests) {
+		add("tests", strconv.Itoa(len(b.Tests)-len(a.Tests))+" added")
+	} else if len(a.Tests) > len(b.Tests) {
+		add("tests", strconv.Itoa(len(a.Tests)-len(b.Tests))+" removed")
+	}
+	if !reflect.DeepEqual(a.Subtasks, b.Subtasks) {
+		add("subtasks", "changed")
+	}
+	if !reflect.DeepEqual(a.TestGroups, b.TestGroups) {
+		add("test_groups", "changed")
+	}
+	changes = append(changes, diffStatements(a.MdStatements, b.MdStatements)...)
+	if !reflect.DeepEqual(a.Examples, b.Examples) {
+		add("examples", "changed")
+	}
+	if !reflect.DeepEqual(a.FullName, b.FullName) {
+		add("full_name", "changed")
+	}
+	if !reflect.DeepEqual(a.Solutions, b.Solutions) {
+		add("solutions", "changed")
+	}
+	if !reflect.DeepEqual(a.Attachments, b.Attachments) {
+		add("attachments", "changed")
+	}
+	if !reflect.DeepEqual(a.AuthoringFiles, b.AuthoringFiles) {
+		add("authoring_files", "changed")
+	}
+	return changes
+}
+
+func diffSource(a, b string) string {
+	switch {
+	case a == b:
+		return ""
+	case a == "":
+		return "added"
+	case b == "":
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+func diffStatements(a, b []MarkdownStatement) []RevisionChange {
+	byLang := func(statements []MarkdownStatement) map[string]MarkdownStatement {
+		res := make(map[string]MarkdownStatement, len(statements))
+		for _, s := range statements {
+			res[s.LangIso639] = s
+		}
+		return res
+	}
+	before, after := byLang(a), byLang(b)
+	var langs []string
+	for lang := range before {
+		langs = append(langs, lang)
+	}
+	for lang := range after {
+		if _, ok := before[lang]; !ok {
+			langs = append(langs, lang)
+		}
+	}
+	slices.Sort(langs)
+
+	var changes []RevisionChange
+	for _, lang := range langs {
+		old, hadOld := before[lang]
+		cur, hasCur := after[lang]
+		detail := ""
+		switch {
+		case !hadOld:
+			detail = "added"
+		case !hasCur:
+			detail = "removed"
+		case old != cur:
+			detail = "changed"
+		}
+		if detail != "" {
+			changes = append(changes, RevisionChange{Field: "statement." + lang, Detail: detail})
+		}
+	}
+	return changes
+}
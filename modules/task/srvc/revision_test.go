@@ -0,0 +1,103 @@
+package srvc_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/programme-lv/backend/common/filestore"
+	"github.com/programme-lv/backend/gen/mocks/mocktasksrvc"
+	"github.com/programme-lv/backend/modules/task/srvc"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReimportCreatesNextRevision(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	service := srvc.NewTaskSrvc(repo, store, store)
+
+	data, err := os.ReadFile("testdata/lio2026cuska.zip")
+	require.NoError(t, err)
+
+	prev := srvc.Task{
+		ShortId: "lio2026cuska", Revision: 2, CpuTimeLimSecs: 5,
+		IllustrImg:          &srvc.IllustrationImage{ObjectKey: "illustr.png", WidthPx: 10, HeightPx: 10},
+		OgFilesZipObjectKey: "og.zip",
+	}
+	var next srvc.Task
+	repo.EXPECT().Exists(ctx, "lio2026cuska").Return(true, nil).Once()
+	repo.EXPECT().GetTask(ctx, "lio2026cuska").Return(prev, nil).Once()
+	repo.EXPECT().ReviseTask(ctx, prev, mock.Anything).RunAndReturn(
+		func(_ context.Context, _ srvc.Task, task srvc.Task) error {
+			next = task
+			return nil
+		},
+	).Once()
+
+	id, importErr := service.ImportTaskFromZip(ctx, data, "")
+	require.Nil(t, importErr)
+	require.Equal(t, "lio2026cuska", id)
+	require.Equal(t, 3, next.Revision)
+	require.Len(t, next.Tests, 159)
+	require.Equal(t, prev.IllustrImg, next.IllustrImg)
+	require.Equal(t, "og.zip", next.OgFilesZipObjectKey)
+}
+
+func TestDiffAndRollbackTaskRevisions(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	service := srvc.NewTaskSrvc(repo, store, store)
+
+	first := srvc.Task{
+		ShortId: "summa", Revision: 1, CpuTimeLimSecs: 1, MemLimMegabytes: 256,
+		Tests: []srvc.Test{{InpSha2: "a", AnsSha2: "b"}, {InpSha2: "c", AnsSha2: "d"}},
+		MdStatements: []srvc.MarkdownStatement{
+			{LangIso639: "lv", Story: "stāsts"},
+		},
+	}
+	current := srvc.Task{
+		ShortId: "summa", Revision: 2, CpuTimeLimSecs: 0.5, MemLimMegabytes: 256,
+		Checker: "checker",
+		Tests:   []srvc.Test{{InpSha2: "a", AnsSha2: "x"}, {InpSha2: "c", AnsSha2: "d"}, {InpSha2: "e", AnsSha2: "f"}},
+		MdStatements: []srvc.MarkdownStatement{
+			{LangIso639: "lv", Story: "stāsts"},
+			{LangIso639: "en", Story: "story"},
+		},
+	}
+	repo.EXPECT().Exists(ctx, "summa").Return(true, nil)
+	repo.EXPECT().GetTask(ctx, "summa").Return(current, nil)
+	repo.EXPECT().GetTaskRevision(ctx, "summa", 1).Return(&first, nil)
+	repo.EXPECT().GetTaskRevision(ctx, "summa", 7).Return(nil, nil)
+
+	diff, diffErr := service.DiffTaskRevisions(ctx, "summa", 1, 2)
+	require.Nil(t, diffErr)
+	require.Equal(t, []srvc.RevisionChange{
+		{Field: "cpu_time_lim_secs", Detail: "1 → 0.5"},
+		{Field: "checker", Detail: "added"},
+		{Field: "tests", Detail: "test 1: answer changed"},
+		{Field: "tests", Detail: "1 added"},
+		{Field: "statement.en", Detail: "added"},
+	}, diff.Changes)
+
+	_, diffErr = service.DiffTaskRevisions(ctx, "summa", 1, 7)
+	require.ErrorIs(t, diffErr, srvc.ErrRevisionNotFound)
+
+	var restored srvc.Task
+	repo.EXPECT().ReviseTask(ctx, current, mock.Anything).RunAndReturn(
+		func(_ context.Context, _ srvc.Task, task srvc.Task) error {
+			restored = task
+			return nil
+		},
+	).Once()
+	revision, rollbackErr := service.RollbackTask(ctx, "summa", 1)
+	require.Nil(t, rollbackErr)
+	require.Equal(t, 3, revision)
+	require.Equal(t, 3, restored.Revision)
+	require.Equal(t, first.Tests, restored.Tests)
+	require.Equal(t, first.CpuTimeLimSecs, restored.CpuTimeLimSecs)
+}
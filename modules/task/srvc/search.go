@@ -0,0 +1,56 @@
+package srvc
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/programme-lv/backend/common/srvcerror"
+)
+
+const (
+	maxSearchQueryLen = 200
+	searchResultLimit = 50
+)
+
+// TaskSearchHit is a task matching a full-text search query.
+type TaskSearchHit struct {
+	ShortId  string
+	FullName map[string]string
+	// Lang is the language of the best-matching statement and name
+	Lang string
+	Rank float64
+	// Snippet is HTML-escaped statement text
+	// with the matched words wrapped in <mark>
+	Snippet string
+}
+
+// Name returns the task name in the language of the match,
+// falling back to the default full name.
+func (h TaskSearchHit) Name() string {
+	if name, ok := h.FullName[h.Lang]; ok && name != "" {
+		return name
+	}
+	preview := TaskPreview{FullName: h.FullName}
+	return preview.DefaultFullName()
+}
+
+// SearchTasks finds tasks whose names, statement story, input and output,
+// problem tags or authors match query, best matches first. The query
+// accepts web-search syntax: quoted phrases, "or" and -excluded words.
+// Only listed tasks are searched unless includeUnlisted is set.
+func (ts *taskSrvc) SearchTasks(ctx context.Context, query string, includeUnlisted bool) ([]TaskSearchHit, srvcerror.E) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errInvalidSearchQuery("vaicājums ir tukšs")
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLen {
+		return nil, errInvalidSearchQuery("vaicājums ir garāks par 200 rakstzīmēm")
+	}
+	hits, err := ts.repo.SearchTasks(ctx, query, searchResultLimit, includeUnlisted)
+	if err != nil {
+		ts.logger(ctx).Error("search tasks", "error", err)
+		return nil, srvcerror.InternalServerError()
+	}
+	return hits, nil
+}
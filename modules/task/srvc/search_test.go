@@ -0,0 +1,34 @@
+package srvc_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/programme-lv/backend/common/filestore"
+	"github.com/programme-lv/backend/gen/mocks/mocktasksrvc"
+	"github.com/programme-lv/backend/modules/task/srvc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchTasks(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	service := srvc.NewTaskSrvc(repo, store, store)
+
+	_, searchErr := service.SearchTasks(ctx, "   ", false)
+	require.ErrorIs(t, searchErr, srvc.ErrInvalidSearchQuery)
+	_, searchErr = service.SearchTasks(ctx, strings.Repeat("ā", 201), false)
+	require.ErrorIs(t, searchErr, srvc.ErrInvalidSearchQuery)
+
+	repo.EXPECT().SearchTasks(ctx, "kartītes", 50, false).Return([]srvc.TaskSearchHit{
+		{ShortId: "aplusbirc", FullName: map[string]string{"lv": "A+B=C", "en": "A+B=C (en)"}, Lang: "en"},
+		{ShortId: "summa", FullName: map[string]string{"lv": "Summa"}, Lang: "en"},
+	}, nil).Once()
+	hits, searchErr := service.SearchTasks(ctx, " kartītes ", false)
+	require.Nil(t, searchErr)
+	require.Equal(t, "A+B=C (en)", hits[0].Name())
+	require.Equal(t, "Summa", hits[1].Name())
+}
@@ -0,0 +1,358 @@
+package srvc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/common/srvcerror"
+	"github.com/programme-lv/backend/modules/exec"
+	"github.com/programme-lv/backend/modules/plang"
+)
+
+// Solution check statuses.
+const (
+	SolutionPassed       = "passed"
+	SolutionMismatch     = "mismatch"
+	SolutionCompileError = "compile_error"
+	// SolutionNotRun means the language is not supported
+	// or the execution failed before grading
+	SolutionNotRun = "not_run"
+)
+
+// scoreEpsilon absorbs rounding of partial checker scores.
+const scoreEpsilon = 1e-6
+
+// SolutionReport tells whether the bundled solutions of a task
+// earn the subtasks and score that they declare.
+type SolutionReport struct {
+	TaskId     string
+	StartedAt  time.Time
+	FinishedAt *time.Time // nil while the solutions run
+	// Flagged is set when some solution did not pass its check.
+	Flagged bool
+	// Interrupted is set when the process running the
+	// solutions stopped before every solution was graded.
+	Interrupted bool
+	Solutions   []SolutionCheck
+}
+
+// SolutionCheck is the graded run of one bundled solution.
+type SolutionCheck struct {
+	Fname    string
+	LangId   string
+	ExecUuid *uuid.UUID
+	Status   string
+	// SubtaskPoints are the points received for each subtask
+	SubtaskPoints []float64
+	ReceivedScore float64
+	PossibleScore int
+	Problems      []string
+}
+
+// WithSolutionChecks runs the bundled solutions against the imported
+// task in the background and stores a [SolutionReport].
+func WithSolutionChecks() ImportOption {
+	return func(o *importOptions) {
+		o.checkSolutions = true
+	}
+}
+
+// CheckSolutions runs the bundled solutions of a stored task again.
+// The report is replaced once every solution has been graded.
+func (ts *taskSrvc) CheckSolutions(ctx context.Context, taskId string) srvcerror.E {
+	if ts.execSrvc == nil {
+		return ErrSolutionChecksUnavailable
+	}
+	task, err := ts.getStoredTask(ctx, taskId)
+	if err != nil {
+		return err
+	}
+	return ts.startSolutionChecks(ctx, task)
+}
+
+func (ts *taskSrvc) GetSolutionReport(ctx context.Context, taskId string) (SolutionReport, srvcerror.E) {
+	exists, err := ts.repo.Exists(ctx, taskId)
+	if err != nil {
+		ts.logger(ctx).Error("check if task exists", "error", err)
+		return SolutionReport{}, srvcerror.InternalServerError()
+	}
+	if !exists {
+		return SolutionReport{}, errTaskNotFound(taskId)
+	}
+	report, err := ts.repo.GetSolutionReport(ctx, taskId)
+	if err != nil {
+		ts.logger(ctx).Error("get solution report", "task_id", taskId, "error", err)
+		return SolutionReport{}, srvcerror.InternalServerError()
+	}
+	if report == nil {
+		return SolutionReport{}, ErrSolutionReportNotFound
+	}
+	return *report, nil
+}
+
+// InterruptSolutionChecks finishes the reports left unfinished by
+// checks that were running when the service stopped, so that they do
+// not stay in progress forever. It is meant to run on start; a check
+// still running on another replica replaces the marker once it ends.
+func (ts *taskSrvc) InterruptSolutionChecks(ctx context.Context) srvcerror.E {
+	count, err := ts.repo.InterruptSolutionReports(ctx, time.Now())
+	if err != nil {
+		ts.logger(ctx).Error("interrupt unfinished solution reports", "error", err)
+		return srvcerror.InternalServerError()
+	}
+	if count > 0 {
+		ts.logger(ctx).Warn("interrupted unfinished solution checks", "count", count)
+	}
+	return nil
+}
+
+// startSolutionChecks stores a pending report and grades the
+// solutions in the background, one execution at a time.
+func (ts *taskSrvc) startSolutionChecks(ctx context.Context, task Task) srvcerror.E {
+	if _, running := ts.solutionChecks.LoadOrStore(task.ShortId, struct{}{}); running {
+		return ErrSolutionChecksRunning
+	}
+	report := SolutionReport{TaskId: task.ShortId, StartedAt: time.Now()}
+	if err := ts.repo.SaveSolutionReport(ctx, report); err != nil {
+		ts.solutionChecks.Delete(task.ShortId)
+		ts.logger(ctx).Error("save solution report", "task_id", task.ShortId, "error", err)
+		return srvcerror.InternalServerError()
+	}
+	go func() {
+		defer ts.solutionChecks.Delete(task.ShortId)
+		ts.runSolutionChecks(context.WithoutCancel(ctx), task, report)
+	}()
+	return nil
+}
+
+func (ts *taskSrvc) runSolutionChecks(ctx context.Context, task Task, report SolutionReport) {
+	log := ts.logger(ctx).With("task_id", task.ShortId)
+	for _, solution := range task.Solutions {
+		check := ts.checkSolution(ctx, task, solution)
+		report.Flagged = report.Flagged || check.Status != SolutionPassed
+		report.Solutions = append(report.Solutions, check)
+	}
+	finishedAt := time.Now()
+	report.FinishedAt = &finishedAt
+	if err := ts.repo.SaveSolutionReport(ctx, report); err != nil {
+		log.Error("save solution report", "error", err)
+		return
+	}
+	if report.Flagged {
+		log.Warn("bundled solutions do not earn what they declare")
+		return
+	}
+	log.Info("bundled solutions passed", "count", len(report.Solutions))
+}
+
+func (ts *taskSrvc) checkSolution(ctx context.Context, task Task, solution Solution) SolutionCheck {
+	check := SolutionCheck{
+		Fname: solution.Fname, Status: SolutionNotRun,
+		PossibleScore: possibleScore(task),
+	}
+	lang, langErr := plang.GetEnabledProgrLangByFname(solution.Fname)
+	if langErr != nil {
+		check.Problems = []string{fmt.Sprintf("no enabled language for %q files", path.Ext(solution.Fname))}
+		return check
+	}
+	check.LangId = lang.ID
+
+	tests, err := ts.solutionCheckTests(ctx, task)
+	if err != nil {
+		check.Problems = []string{"get test download url: " + err.Error()}
+		return check
+	}
+	id := uuid.New()
+	check.ExecUuid = &id
+	params := exec.TestingParams{
+		CpuMs: task.CpuMillis(), MemKiB: task.MemoryKiB(),
+		Checker: task.CheckerPtr(), Interactor: task.InteractorPtr(),
+		Lane: exec.LaneBulk,
+	}
+	if err := ts.execSrvc.Enqueue(ctx, id, solution.Content, lang.ID, tests, params); err != nil {
+		check.Problems = []string{"enqueue execution: " + err.Error()}
+		return check
+	}
+	res, err := ts.execSrvc.Get(ctx, id)
+	if err != nil {
+		check.Problems = []string{"get execution: " + err.Error()}
+		return check
+	}
+	switch res.Stage {
+	case exec.StageFinished:
+	case exec.StageCompileError:
+		check.Status = SolutionCompileError
+		return check
+	default:
+		check.Problems = []string{fmt.Sprintf("execution ended in stage %s", res.Stage)}
+		return check
+	}
+
+	fractions := make([]float64, len(task.Tests))
+	for _, t := range res.TestRes {
+		if t.ID >= 1 && t.ID <= len(fractions) {
+			fractions[t.ID-1] = testFraction(t, params)
+		}
+	}
+	check.SubtaskPoints, check.ReceivedScore = solutionScores(task, fractions)
+	check.Problems = compareSolution(task, solution, fractions, check.SubtaskPoints, check.ReceivedScore)
+	check.Status = SolutionPassed
+	if len(check.Problems) > 0 {
+		check.Status = SolutionMismatch
+	}
+	return check
+}
+
+func (ts *taskSrvc) solutionCheckTests(ctx context.Context, task Task) ([]exec.TestFile, error) {
+	tests := make([]exec.TestFile, len(task.Tests))
+	for i, test := range task.Tests {
+		inpUrl, err := ts.GetTestDownlUrl(ctx, test.InpSha2)
+		if err != nil {
+			return nil, err
+		}
+		ansUrl, err := ts.GetTestDownlUrl(ctx, test.AnsSha2)
+		if err != nil {
+			return nil, err
+		}
+		tests[i] = exec.TestFile{
+			InSha256: &test.InpSha2, InDownlUrl: &inpUrl,
+			AnsSha256: &test.AnsSha2, AnsDownlUrl: &ansUrl,
+		}
+	}
+	return tests, nil
+}
+
+// testFraction grades a test like a submission's evaluation:
+// only a run within limits earns the checker's share of points.
+func testFraction(t exec.TestRes, params exec.TestingParams) float64 {
+	r := t.Subm
+	switch {
+	case !t.Finished || r == nil || t.Checker == nil:
+		return 0
+	case r.IsOomKilled || r.MemKiB >= int64(params.MemKiB):
+		return 0
+	case r.ExitCode != 0 || r.StdErr != "" || r.Signal != nil:
+		return 0
+	case r.CpuMs >= int64(params.CpuMs):
+		return 0
+	}
+	return exec.CheckerScore(t.Checker.ExitCode, t.Checker.StdOut)
+}
+
+// solutionScores returns the points earned for each subtask and in
+// total. Test groups, subtasks and single tests are the scoring units
+// in that order of precedence; a unit earns its points times the
+// smallest share among its tests.
+func solutionScores(task Task, fractions []float64) ([]float64, float64) {
+	subtaskPoints := make([]float64, len(task.Subtasks))
+	for i, subtask := range task.Subtasks {
+		if len(task.TestGroups) == 0 {
+			subtaskPoints[i] = float64(subtask.Score) * minFraction(subtask.TestIDs, fractions)
+			continue
+		}
+		for _, group := range task.TestGroups {
+			if containsAll(subtask.TestIDs, group.TestIDs) {
+				subtaskPoints[i] += float64(group.Points) * minFraction(group.TestIDs, fractions)
+			}
+		}
+	}
+
+	total := 0.0
+	switch {
+	case len(task.TestGroups) > 0:
+		for _, group := range task.TestGroups {
+			total += float64(group.Points) * minFraction(group.TestIDs, fractions)
+		}
+	case len(task.Subtasks) > 0:
+		for _, points := range subtaskPoints {
+			total += points
+		}
+	default:
+		for _, fraction := range fractions {
+			total += fraction
+		}
+	}
+	return subtaskPoints, total
+}
+
+func possibleScore(task Task) int {
+	total := 0
+	switch {
+	case len(task.TestGroups) > 0:
+		for _, group := range task.TestGroups {
+			total += group.Points
+		}
+	case len(task.Subtasks) > 0:
+		for _, subtask := range task.Subtasks {
+			total += subtask.Score
+		}
+	default:
+		total = len(task.Tests)
+	}
+	return total
+}
+
+// compareSolution lists how the run differs from the declaration.
+// Every test of a declared subtask must be passed and no other subtask
+// may be passed fully; a solution that declares nothing must earn
+// every point.
+func compareSolution(task Task, solution Solution, fractions, subtaskPoints []float64, total float64) []string {
+	var problems []string
+	if len(solution.Subtasks) == 0 && solution.Score == nil {
+		if possible := possibleScore(task); total < float64(possible)-scoreEpsilon {
+			problems = append(problems, fmt.Sprintf("earned %s of %d points", formatPoints(total), possible))
+		}
+		return problems
+	}
+	if len(solution.Subtasks) > 0 {
+		for i, subtask := range task.Subtasks {
+			solved := minFraction(subtask.TestIDs, fractions) >= 1-scoreEpsilon
+			declared := slices.Contains(solution.Subtasks, i+1)
+			switch {
+			case declared && !solved:
+				problems = append(problems, fmt.Sprintf("subtask %d: earned %s of %d points",
+					i+1, formatPoints(subtaskPoints[i]), subtask.Score))
+			case !declared && solved:
+				problems = append(problems, fmt.Sprintf("subtask %d: solved but not declared", i+1))
+			}
+		}
+	}
+	if solution.Score != nil && math.Abs(total-float64(*solution.Score)) > scoreEpsilon {
+		problems = append(problems, fmt.Sprintf("earned %s points, declared %d",
+			formatPoints(total), *solution.Score))
+	}
+	return problems
+}
+
+func minFraction(testIDs []int, fractions []float64) float64 {
+	if len(testIDs) == 0 {
+		return 0
+	}
+	res := 1.0
+	for _, id := range testIDs {
+		if id < 1 || id > len(fractions) {
+			return 0
+		}
+		res = min(res, fractions[id-1])
+	}
+	return res
+}
+
+func containsAll(set, values []int) bool {
+	for _, value := range values {
+		if !slices.Contains(set, value) {
+			return false
+		}
+	}
+	return len(values) > 0
+}
+
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(math.Round(points*100)/100, 'f', -1, 64)
+}
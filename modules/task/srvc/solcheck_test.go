@@ -0,0 +1,239 @@
+package srvc_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/programme-lv/backend/common/filestore"
+	"github.com/programme-lv/backend/common/srvcerror"
+	"github.com/programme-lv/backend/gen/mocks/mocktasksrvc"
+	"github.com/programme-lv/backend/modules/exec"
+	"github.com/programme-lv/backend/modules/task/srvc"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeExec passes the tests listed for each source code
+// and fails the rest with a wrong answer.
+type fakeExec struct {
+	exec.CodeExecutionService
+	passes map[string][]int
+	execs  map[uuid.UUID]exec.Execution
+}
+
+func (f *fakeExec) Enqueue(_ context.Context, id uuid.UUID, src string, _ string, tests []exec.TestFile, params exec.TestingParams) srvcerror.E {
+	e := exec.Execution{UUID: id, Stage: exec.StageFinished, Params: params}
+	for i := range tests {
+		checkerExit := int64(1)
+		for _, passed := range f.passes[src] {
+			if passed == i+1 {
+				checkerExit = 0
+			}
+		}
+		e.TestRes = append(e.TestRes, exec.TestRes{
+			ID: i + 1, Reached: true, Finished: true,
+			Subm:    &exec.RunData{CpuMs: 10, MemKiB: 1024},
+			Checker: &exec.RunData{ExitCode: checkerExit},
+		})
+	}
+	f.execs[id] = e
+	return nil
+}
+
+func (f *fakeExec) Get(_ context.Context, id uuid.UUID) (exec.Execution, srvcerror.E) {
+	return f.execs[id], nil
+}
+
+func TestCheckSolutionsFlagsMismatchedSolutions(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	runner := &fakeExec{
+		passes: map[string][]int{
+			"full":  {1, 2, 3, 4},
+			"brute": {1, 2},
+			"wrong": {1, 2, 3},
+		},
+		execs: map[uuid.UUID]exec.Execution{},
+	}
+	service := srvc.NewTaskSrvc(repo, store, store, srvc.WithExecSrvc(runner))
+
+	score := 40
+	task := srvc.Task{
+		ShortId: "summa", MemLimMegabytes: 256, CpuTimeLimSecs: 1, Checker: "checker",
+		Tests: make([]srvc.Test, 4),
+		Subtasks: []srvc.Subtask{
+			{Score: 40, TestIDs: []int{1, 2}},
+			{Score: 60, TestIDs: []int{3, 4}},
+		},
+		Solutions: []srvc.Solution{
+			{Fname: "full.cpp", Content: "full", Subtasks: []int{1, 2}},
+			{Fname: "brute.py", Content: "brute", Subtasks: []int{1}, Score: &score},
+			{Fname: "wrong.cpp", Content: "wrong", Subtasks: []int{1, 2}},
+			{Fname: "sol.rs", Content: "full"},
+		},
+	}
+	repo.EXPECT().Exists(ctx, "summa").Return(true, nil).Once()
+	repo.EXPECT().GetTask(ctx, "summa").Return(task, nil).Once()
+	reports := make(chan srvc.SolutionReport, 2)
+	repo.EXPECT().SaveSolutionReport(mock.Anything, mock.Anything).RunAndReturn(
+		func(_ context.Context, report srvc.SolutionReport) error {
+			reports <- report
+			return nil
+		},
+	).Twice()
+
+	require.Nil(t, service.CheckSolutions(ctx, "summa"))
+	pending := <-reports
+	require.Nil(t, pending.FinishedAt)
+
+	var report srvc.SolutionReport
+	select {
+	case report = <-reports:
+	case <-time.After(5 * time.Second):
+		t.Fatal("solution checks did not finish")
+	}
+	require.NotNil(t, report.FinishedAt)
+	require.True(t, report.Flagged)
+	require.Len(t, report.Solutions, 4)
+
+	full, brute, wrong, rust := report.Solutions[0], report.Solutions[1], report.Solutions[2], report.Solutions[3]
+	require.Equal(t, srvc.SolutionPassed, full.Status)
+	require.Equal(t, "cpp17", full.LangId)
+	require.Equal(t, []float64{40, 60}, full.SubtaskPoints)
+	require.Equal(t, 100.0, full.ReceivedScore)
+	require.Equal(t, 100, full.PossibleScore)
+
+	require.Equal(t, srvc.SolutionPassed, brute.Status)
+	require.Equal(t, "python3.13", brute.LangId)
+
+	require.Equal(t, srvc.SolutionMismatch, wrong.Status)
+	require.Equal(t, []string{"subtask 2: earned 0 of 60 points"}, wrong.Problems)
+
+	require.Equal(t, srvc.SolutionNotRun, rust.Status)
+	require.Nil(t, rust.ExecUuid)
+
+	for _, e := range runner.execs {
+		require.Equal(t, exec.LaneBulk, e.Params.Lane)
+		require.Equal(t, "checker", *e.Params.Checker)
+	}
+}
+
+func TestImportWithSolutionChecksNeedsExecSrvc(t *testing.T) {
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	service := srvc.NewTaskSrvc(repo, store, store)
+
+	_, importErr := service.ImportTaskFromZip(context.Background(), nil, "", srvc.WithSolutionChecks())
+	require.Equal(t, srvc.ErrSolutionChecksUnavailable, importErr)
+}
+
+func TestImportWithSolutionChecksCreatesDraft(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	runner := &fakeExec{execs: map[uuid.UUID]exec.Execution{}}
+	service := srvc.NewTaskSrvc(repo, store, store, srvc.WithExecSrvc(runner))
+	data, err := os.ReadFile("testdata/lio2026cuska.zip")
+	require.NoError(t, err)
+
+	reports := make(chan srvc.SolutionReport, 4)
+	repo.EXPECT().SaveSolutionReport(mock.Anything, mock.Anything).RunAndReturn(
+		func(_ context.Context, report srvc.SolutionReport) error {
+			reports <- report
+			return nil
+		},
+	).Times(4)
+	for id, want := range map[string]srvc.Visibility{
+		"checked":   srvc.VisibilityDraft,
+		"published": srvc.VisibilityPublished,
+	} {
+		var created srvc.Task
+		repo.EXPECT().Exists(ctx, id).Return(false, nil).Once()
+		repo.EXPECT().CreateTask(ctx, mock.Anything).RunAndReturn(
+			func(_ context.Context, task srvc.Task) error {
+				created = task
+				return nil
+			},
+		).Once()
+		opts := []srvc.ImportOption{srvc.WithSolutionChecks()}
+		if want == srvc.VisibilityPublished {
+			opts = append(opts, srvc.WithVisibility(want))
+		}
+		_, importErr := service.ImportTaskFromZip(ctx, data, id, opts...)
+		require.Nil(t, importErr)
+		require.Equal(t, want, created.Visibility, id)
+	}
+
+	for finished := 0; finished < 2; {
+		select {
+		case report := <-reports:
+			if report.FinishedAt != nil {
+				finished++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("solution checks did not finish")
+		}
+	}
+}
+
+func TestCheckSolutionsWithoutSigningKeyDoesNotRun(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	runner := &fakeExec{execs: map[uuid.UUID]exec.Execution{}}
+	service := srvc.NewTaskSrvc(repo, store, store,
+		srvc.WithExecSrvc(runner), srvc.WithTestfileDownloadSigningKey(nil))
+
+	task := srvc.Task{
+		ShortId: "summa", MemLimMegabytes: 256, CpuTimeLimSecs: 1,
+		Tests:     make([]srvc.Test, 2),
+		Solutions: []srvc.Solution{{Fname: "full.cpp", Content: "full"}},
+	}
+	repo.EXPECT().Exists(ctx, "summa").Return(true, nil).Once()
+	repo.EXPECT().GetTask(ctx, "summa").Return(task, nil).Once()
+	reports := make(chan srvc.SolutionReport, 2)
+	repo.EXPECT().SaveSolutionReport(mock.Anything, mock.Anything).RunAndReturn(
+		func(_ context.Context, report srvc.SolutionReport) error {
+			reports <- report
+			return nil
+		},
+	).Twice()
+
+	require.Nil(t, service.CheckSolutions(ctx, "summa"))
+	<-reports
+	var report srvc.SolutionReport
+	select {
+	case report = <-reports:
+	case <-time.After(5 * time.Second):
+		t.Fatal("solution checks did not finish")
+	}
+	require.True(t, report.Flagged)
+	require.Len(t, report.Solutions, 1)
+	require.Equal(t, srvc.SolutionNotRun, report.Solutions[0].Status)
+	require.Nil(t, report.Solutions[0].ExecUuid)
+	require.Len(t, report.Solutions[0].Problems, 1)
+	require.Contains(t, report.Solutions[0].Problems[0], "get test download url")
+	require.Empty(t, runner.execs)
+}
+
+func TestInterruptSolutionChecks(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	service := srvc.NewTaskSrvc(repo, store, store)
+
+	before := time.Now()
+	repo.EXPECT().InterruptSolutionReports(ctx, mock.MatchedBy(func(startedBefore time.Time) bool {
+		return !startedBefore.Before(before)
+	})).Return(2, nil).Once()
+	require.Nil(t, service.InterruptSolutionChecks(ctx))
+}
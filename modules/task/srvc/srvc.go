@@ -6,9 +6,13 @@ package srvc
 import (
 	"context"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/programme-lv/backend/common/ctxlog"
+	"github.com/programme-lv/backend/common/filestore"
 	"github.com/programme-lv/backend/common/srvcerror"
+	"github.com/programme-lv/backend/modules/exec"
 	"golang.org/x/sync/singleflight"
 )
 
@@ -35,18 +39,42 @@ type TaskService interface {
 	UploadStatementImage(ctx context.Context, taskId string, filename string, mimeType string, body []byte) (string, srvcerror.E)
 	DeleteStatementImage(ctx context.Context, taskId string, filename string) srvcerror.E
 	GetHttpUrlForStatementImage(ctx context.Context, statementImageObjectKey string) (string, srvcerror.E)
+	GetHttpUrlForAttachment(ctx context.Context, attachmentObjectKey string) (string, srvcerror.E)
 
 	// website
 	GetTaskPreview(ctx context.Context, shortId string) (TaskPreview, srvcerror.E)
-	ListTaskPreviews(ctx context.Context) ([]TaskPreview, srvcerror.E)
-	ListTaskFilters(ctx context.Context) (FilterTree, srvcerror.E)
+	ListTaskPreviews(ctx context.Context, q TaskListQuery) (TaskPage, srvcerror.E)
+	ListTaskFilters(ctx context.Context, includeUnlisted bool) (FilterTree, srvcerror.E)
+
+	// limits, checker, tests and scoring; each edit creates a revision
+	SetTaskLimits(ctx context.Context, taskId string, cpuTimeLimSecs float64, memLimMegabytes int) (int, srvcerror.E)
+	SetTaskChecker(ctx context.Context, taskId string, checker string) (int, srvcerror.E)
+	AddTaskTest(ctx context.Context, taskId string, after int, input, answer []byte) (int, srvcerror.E)
+	ReplaceTaskTest(ctx context.Context, taskId string, testId int, input, answer []byte) (int, srvcerror.E)
+	RemoveTaskTest(ctx context.Context, taskId string, testId int) (int, srvcerror.E)
+	SetTaskScoring(ctx context.Context, taskId string, scoring TaskScoring) (int, srvcerror.E)
+
+	// visibility
+	SetTaskVisibility(ctx context.Context, taskId string, visibility Visibility, publishAt *time.Time) srvcerror.E
 
 	// taskzip archive format
-	ImportTaskFromZip(ctx context.Context, zipBytes []byte, overrideId string) (string, srvcerror.E)
+	ImportTaskFromZip(ctx context.Context, zipBytes []byte, overrideId string, opts ...ImportOption) (string, srvcerror.E)
 	ExportTaskAsZip(ctx context.Context, taskId string) ([]byte, srvcerror.E)
+	ImportTaskFromPolygon(ctx context.Context, zipBytes []byte, overrideId string, opts ...ImportOption) (string, srvcerror.E)
+	ImportTaskFromCMS(ctx context.Context, zipBytes []byte, overrideId string, opts ...ImportOption) (string, srvcerror.E)
+
+	// content revisions
+	ListTaskRevisions(ctx context.Context, taskId string) ([]TaskRevision, srvcerror.E)
+	DiffTaskRevisions(ctx context.Context, taskId string, from, to int) (RevisionDiff, srvcerror.E)
+	RollbackTask(ctx context.Context, taskId string, revision int) (int, srvcerror.E)
+
+	// bundled solutions
+	CheckSolutions(ctx context.Context, taskId string) srvcerror.E
+	GetSolutionReport(ctx context.Context, taskId string) (SolutionReport, srvcerror.E)
 
 	ResolveNames(ctx context.Context, shortIds []string) ([]string, srvcerror.E)
 	SearchTasksByName(ctx context.Context, name string) ([]string, srvcerror.E)
+	SearchTasks(ctx context.Context, query string, includeUnlisted bool) ([]TaskSearchHit, srvcerror.E)
 }
 
 type ObjectStore interface {
@@ -54,23 +82,38 @@ type ObjectStore interface {
 	Download(key string) ([]byte, error)
 	Exists(key string) (bool, error)
 	Delete(key string) error
+	Touch(key string) error
+	List(prefix string) ([]filestore.Object, error)
 }
 
 type TaskPgRepo interface {
 	GetTask(ctx context.Context, shortId string) (Task, error)
 	GetTaskPreview(ctx context.Context, shortId string) (TaskPreview, error)
 	SearchTasksByName(ctx context.Context, name string) ([]string, error)
+	SearchTasks(ctx context.Context, query string, limit int, includeUnlisted bool) ([]TaskSearchHit, error)
 	ListTasks(ctx context.Context, limit int, offset int) ([]Task, error)
-	ListTaskPreviews(ctx context.Context, limit int, offset int) ([]TaskPreview, error)
-	ListOriginCounts(ctx context.Context) ([]OriginCount, error)
+	// ListTaskPreviews returns the page selected by q and how many tasks match it.
+	ListTaskPreviews(ctx context.Context, q TaskPreviewQuery) ([]TaskPreview, int, error)
+	ListOriginCounts(ctx context.Context, includeUnlisted bool) ([]OriginCount, error)
 	ResolveNames(ctx context.Context, shortIds []string) ([]string, error)
 	Exists(ctx context.Context, shortId string) (bool, error)
 	CreateTask(ctx context.Context, task Task) error
+	ReviseTask(ctx context.Context, prev Task, next Task) error
+	ListTaskRevisions(ctx context.Context, taskId string) ([]TaskRevision, error)
+	GetTaskRevision(ctx context.Context, taskId string, revision int) (*Task, error)
 	DeleteTask(ctx context.Context, shortId string) error
 	UpdateStatement(ctx context.Context, taskId string, statement MarkdownStatement) error
 	AddStatementImg(ctx context.Context, taskId string, img StatementImage) error
 	DeleteStatementImg(ctx context.Context, taskId string, filename string) error
 	UpdateIllustrationImg(ctx context.Context, taskId string, img IllustrationImage) error
+	UpdateVisibility(ctx context.Context, taskId string, visibility Visibility, publishAt *time.Time) error
+	SaveSolutionReport(ctx context.Context, report SolutionReport) error
+	// GetSolutionReport returns nil when the solutions were never checked.
+	GetSolutionReport(ctx context.Context, taskId string) (*SolutionReport, error)
+	// InterruptSolutionReports flags and finishes the reports started before
+	// startedBefore that have no FinishedAt and returns how many there were.
+	InterruptSolutionReports(ctx context.Context, startedBefore time.Time) (int, error)
+	ListReferencedObjects(ctx context.Context) (ReferencedObjects, error)
 }
 
 type taskSrvc struct {
@@ -86,6 +129,17 @@ type taskSrvc struct {
 
 	// dlGroup coalesces concurrent DownloadTestFile calls for the same key.
 	dlGroup singleflight.Group
+
+	// execSrvc runs bundled solutions; solution checks are disabled without it
+	execSrvc exec.CodeExecutionService
+	// solutionChecks holds IDs of tasks whose solutions are running
+	solutionChecks sync.Map
+
+	// solveCounter counts solvers per task for the task list
+	solveCounter SolveCounter
+
+	// testfileRefs list test files referenced outside of tasks
+	testfileRefs []TestfileRefLister
 }
 
 type TaskSrvcOption func(*taskSrvc)
@@ -104,6 +158,13 @@ func WithTestfileDownloadSigningKey(key []byte) TaskSrvcOption {
 	}
 }
 
+// WithExecSrvc enables checking bundled solutions on the execution service.
+func WithExecSrvc(execSrvc exec.CodeExecutionService) TaskSrvcOption {
+	return func(ts *taskSrvc) {
+		ts.execSrvc = execSrvc
+	}
+}
+
 func NewTaskSrvc(
 	repo TaskPgRepo,
 	publicStore, testfileStore ObjectStore,
@@ -20,6 +20,8 @@ const taskIllustrationDir = "illustrations"
 
 const taskStatementImageDir = "md-images"
 
+const taskAttachmentDir = "attachments"
+
 func taskIllustrationObjectKey(storedKey string) string {
 	if storedKey == "" {
 		return ""
@@ -50,6 +52,18 @@ func taskStatementImageStoredKey(objectKey string) string {
 	return strings.TrimPrefix(objectKey, taskStatementImageDir+"/")
 }
 
+func taskAttachmentObjectKey(storedKey string) string {
+	return path.Join(taskAttachmentDir, storedKey)
+}
+
+// attachmentMime is the content type attachments are stored with.
+func attachmentMime(name string) string {
+	if mimeType := mime.TypeByExtension(path.Ext(name)); mimeType != "" {
+		return mimeType
+	}
+	return "application/octet-stream"
+}
+
 // UploadIllustrationImg stores an illustration and returns the stored key without the illustrations/ prefix.
 // The object key is illustrations/<sha256>.<ext>.
 func (ts *taskSrvc) UploadIllustrationImg(ctx context.Context, mimeType string, body []byte) (string, srvcerror.E) {
@@ -241,6 +255,15 @@ func (ts *taskSrvc) GetHttpUrlForIllustrImg(ctx context.Context, objectKey strin
 	return url, nil
 }
 
+func (ts *taskSrvc) GetHttpUrlForAttachment(ctx context.Context, objectKey string) (string, srvcerror.E) {
+	url, err := filestore.AssetURL(ts.apiPublicBaseURL, taskAttachmentObjectKey(objectKey))
+	if err != nil {
+		ts.logger(ctx).Error("build attachment URL", "error", err)
+		return "", srvcerror.InternalServerError()
+	}
+	return url, nil
+}
+
 func (ts *taskSrvc) GetHttpUrlForStatementImage(ctx context.Context, objectKey string) (string, srvcerror.E) {
 	url, err := filestore.AssetURL(ts.apiPublicBaseURL, taskStatementImageObjectKey(objectKey))
 	if err != nil {
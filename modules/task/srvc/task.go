@@ -1,6 +1,8 @@
 package srvc
 
 import (
+	"time"
+
 	"github.com/thoas/go-funk"
 )
 
@@ -21,12 +23,27 @@ type TaskPreview struct {
 	OriginYear      string
 	OlympStage      string
 	OriginDivisions []string
+
+	ProblemTags []string
+	// distinct users with a full score, filled in by the task list
+	SolveCount int
+
+	Visibility Visibility
+	PublishAt  *time.Time
 }
 
 type Task struct {
 	// url slug friendly identifier
 	ShortId string
 
+	// content revision, increased by every re-import or rollback
+	Revision int
+
+	// who can find, open and solve the task; not part of revisions
+	Visibility Visibility
+	// scheduled publication of a draft or hidden task
+	PublishAt *time.Time
+
 	// full name of the task in multiple languages (key: ISO 639 code)
 	FullName map[string]string
 
@@ -51,6 +68,9 @@ type Task struct {
 	OriginYear       string
 	OlympStage       string
 	OriginDivisions  []string
+	// how many took part in the contest and how many solved the task
+	OriginContestants *int
+	OriginSolvers     *int
 
 	// statement
 	MdStatements   []MarkdownStatement
@@ -70,13 +90,22 @@ type Task struct {
 	// metadata: authors (free-form names)
 	Authors []string
 
-	// metadata: problem tags (free-form short labels)
+	// metadata: problem tags (free-form short labels), TaskZip topics
 	ProblemTags []string
+	// metadata: TaskZip techniques and data structures
+	Techniques     []string
+	DataStructures []string
 
 	// original full archive object key (optional)
 	OgFilesZipObjectKey string
 
 	Solutions []Solution
+
+	// files handed to contestants, e.g. grader headers
+	Attachments []Attachment
+
+	// TaskZip archive/ and testspec/ files, kept for export
+	AuthoringFiles []AuthoringFile
 }
 
 func (t *Task) CpuMillis() int {
@@ -156,6 +185,18 @@ type IllustrationImage struct {
 	SzInBytes int
 }
 
+type Attachment struct {
+	Path      string // path within the TaskZip attached/ directory, e.g. cpp/grader.h
+	ObjectKey string // e.g. <task_id>/<sha256-prefix>/cpp/grader.h; attachments/ is implicit
+	SzInBytes int
+}
+
+type AuthoringFile struct {
+	Path      string // path within the TaskZip, e.g. testspec/gen.py
+	Sha2      string // key in the test file store
+	SzInBytes int
+}
+
 type StatementImage struct {
 	ObjectKey string // e.g. <task_id>/<sha256-prefix>.png; md-images/ is implicit
 	Filename  string // filename of the image, e.g., nekoks.png
@@ -234,4 +275,5 @@ type Solution struct {
 	Fname    string
 	Content  string
 	Subtasks []int // which subtasks should it solve
+	Score    *int  // total points it should earn, if declared
 }
@@ -19,7 +19,8 @@ func testfileObjectKey(body []byte) string {
 }
 
 // UploadTestFile stores a test input or output after compressing it with Zstandard.
-// If the object already exists, it returns no error and does nothing.
+// If the object already exists, only its modification time is refreshed,
+// so that garbage collection keeps it for the grace period.
 // The object key is the SHA256 hash of the uncompressed body with a .zst extension.
 func (ts *taskSrvc) UploadTestFile(ctx context.Context, body []byte) srvcerror.E {
 	l := ts.logger(ctx)
@@ -33,6 +34,10 @@ func (ts *taskSrvc) UploadTestFile(ctx context.Context, body []byte) srvcerror.E
 	}
 
 	if exists {
+		if err := ts.testfileStore.Touch(objectKey); err != nil {
+			l.Error("touch test file", "error", err)
+			return srvcerror.InternalServerError()
+		}
 		return nil
 	}
 
@@ -93,7 +98,11 @@ func (ts *taskSrvc) DownloadTestFile(ctx context.Context, testFileSha256 string)
 }
 
 // GetTestDownlUrl returns a time-limited signed URL for a test file.
+// Without a signing key test files are not served, so it fails.
 func (ts *taskSrvc) GetTestDownlUrl(ctx context.Context, testFileSha256 string) (string, srvcerror.E) {
+	if len(ts.testfileDownloadSigningKey) == 0 {
+		return "", ErrTestfileDownloadUnavailable
+	}
 	return filestore.SignedTestfileURL(
 		ts.apiPublicBaseURL,
 		testFileSha256,
@@ -0,0 +1,106 @@
+package srvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/programme-lv/backend/common/srvcerror"
+)
+
+// Visibility controls who can find, open and solve a task.
+// Admins can always do all three.
+type Visibility string
+
+const (
+	// VisibilityDraft tasks are seen only by admins.
+	VisibilityDraft Visibility = "draft"
+	// VisibilityHidden tasks are left out of the task list but can be
+	// opened and solved by anyone who knows the ID.
+	VisibilityHidden Visibility = "hidden"
+	// VisibilityPublished tasks are listed and open to everyone.
+	VisibilityPublished Visibility = "published"
+	// VisibilityArchived tasks can be opened by ID
+	// but are not listed and take no submissions.
+	VisibilityArchived Visibility = "archived"
+)
+
+func (v Visibility) Valid() bool {
+	switch v {
+	case VisibilityDraft, VisibilityHidden, VisibilityPublished, VisibilityArchived:
+		return true
+	}
+	return false
+}
+
+// Listed reports whether the task appears in the task list and filters.
+func (v Visibility) Listed() bool {
+	return v == VisibilityPublished
+}
+
+// Viewable reports whether the task can be opened by ID.
+func (v Visibility) Viewable() bool {
+	return v != VisibilityDraft
+}
+
+// AcceptsSubms reports whether solutions can be submitted to the task.
+func (v Visibility) AcceptsSubms() bool {
+	return v == VisibilityPublished || v == VisibilityHidden
+}
+
+// EffectiveVisibility returns the visibility in force at now.
+// A draft or hidden task becomes published once publishAt passes,
+// and tasks without a stored visibility count as published.
+func EffectiveVisibility(v Visibility, publishAt *time.Time, now time.Time) Visibility {
+	if v == "" {
+		return VisibilityPublished
+	}
+	if publishAt != nil && !now.Before(*publishAt) &&
+		(v == VisibilityDraft || v == VisibilityHidden) {
+		return VisibilityPublished
+	}
+	return v
+}
+
+func (t Task) VisibilityAt(now time.Time) Visibility {
+	return EffectiveVisibility(t.Visibility, t.PublishAt, now)
+}
+
+func (p TaskPreview) VisibilityAt(now time.Time) Visibility {
+	return EffectiveVisibility(p.Visibility, p.PublishAt, now)
+}
+
+// WithVisibility sets the visibility of a task created by the import.
+// Re-imports keep the visibility of the existing task.
+func WithVisibility(v Visibility) ImportOption {
+	return func(o *importOptions) {
+		o.visibility = v
+	}
+}
+
+// SetTaskVisibility changes who can see the task. publishAt schedules
+// a draft or hidden task to be published and must be nil otherwise.
+func (ts *taskSrvc) SetTaskVisibility(
+	ctx context.Context, taskId string, visibility Visibility, publishAt *time.Time,
+) srvcerror.E {
+	if !visibility.Valid() {
+		return errInvalidVisibility("nezināms redzamības stāvoklis '" + string(visibility) + "'")
+	}
+	if publishAt != nil && visibility != VisibilityDraft && visibility != VisibilityHidden {
+		return errInvalidVisibility("publicēšanas laiku var norādīt tikai melnrakstam vai slēptam uzdevumam")
+	}
+	exists, err := ts.repo.Exists(ctx, taskId)
+	if err != nil {
+		ts.logger(ctx).Error("check if task exists", "error", err)
+		return srvcerror.InternalServerError()
+	}
+	if !exists {
+		return errTaskNotFound(taskId)
+	}
+	if err := ts.repo.UpdateVisibility(ctx, taskId, visibility, publishAt); err != nil {
+		ts.logger(ctx).Error("update task visibility", "task_id", taskId, "error", err)
+		return srvcerror.InternalServerError()
+	}
+	ts.logger(ctx).Info("task visibility changed", "task_id", taskId,
+		"visibility", visibility, "publish_at", publishAt)
+	return nil
+}
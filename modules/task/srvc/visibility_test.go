@@ -0,0 +1,54 @@
+package srvc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/programme-lv/backend/common/filestore"
+	"github.com/programme-lv/backend/gen/mocks/mocktasksrvc"
+	"github.com/programme-lv/backend/modules/task/srvc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveVisibility(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	for _, tc := range []struct {
+		stored    srvc.Visibility
+		publishAt *time.Time
+		want      srvc.Visibility
+	}{
+		{"", nil, srvc.VisibilityPublished},
+		{srvc.VisibilityDraft, nil, srvc.VisibilityDraft},
+		{srvc.VisibilityDraft, &future, srvc.VisibilityDraft},
+		{srvc.VisibilityDraft, &past, srvc.VisibilityPublished},
+		{srvc.VisibilityHidden, &past, srvc.VisibilityPublished},
+		{srvc.VisibilityArchived, &past, srvc.VisibilityArchived},
+	} {
+		require.Equal(t, tc.want, srvc.EffectiveVisibility(tc.stored, tc.publishAt, now))
+	}
+
+	require.False(t, srvc.VisibilityDraft.Viewable())
+	require.True(t, srvc.VisibilityHidden.AcceptsSubms())
+	require.False(t, srvc.VisibilityHidden.Listed())
+	require.True(t, srvc.VisibilityArchived.Viewable())
+	require.False(t, srvc.VisibilityArchived.AcceptsSubms())
+}
+
+func TestSetTaskVisibility(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	store, err := filestore.NewStore(t.TempDir())
+	require.NoError(t, err)
+	service := srvc.NewTaskSrvc(repo, store, store)
+
+	publishAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	require.ErrorIs(t, service.SetTaskVisibility(ctx, "summa", "secret", nil), srvc.ErrInvalidVisibility)
+	require.ErrorIs(t, service.SetTaskVisibility(ctx, "summa", srvc.VisibilityArchived, &publishAt), srvc.ErrInvalidVisibility)
+
+	repo.EXPECT().Exists(ctx, "summa").Return(true, nil).Once()
+	repo.EXPECT().UpdateVisibility(ctx, "summa", srvc.VisibilityDraft, &publishAt).Return(nil).Once()
+	require.Nil(t, service.SetTaskVisibility(ctx, "summa", srvc.VisibilityDraft, &publishAt))
+}
@@ -2,10 +2,10 @@ package srvc
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"math"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -28,14 +28,27 @@ func validateTaskId(id string) error {
 	return nil
 }
 
-// ImportTaskFromZip creates a task from a TaskZip archive.
+// ImportOption configures a TaskZip import.
+type ImportOption func(*importOptions)
+
+type importOptions struct {
+	checkSolutions bool
+	visibility     Visibility
+}
+
+// ImportTaskFromZip creates a task from a TaskZip archive, or a new
+// revision of it when the task already exists.
 // overrideID replaces the archive task ID when non-empty.
 func (ts *taskSrvc) ImportTaskFromZip(
-	ctx context.Context, zipBytes []byte, overrideID string,
+	ctx context.Context, zipBytes []byte, overrideID string, opts ...ImportOption,
 ) (string, srvcerror.E) {
+	o, optErr := ts.applyImportOptions(opts)
+	if optErr != nil {
+		return "", optErr
+	}
 	archive, err := taskzipv1.Read(zipBytes)
 	if err != nil {
-		return "", taskZipReadError(err)
+		return "", errInvalidTaskZip(err.Error())
 	}
 	task, err := mapFromTaskZip(archive, overrideID)
 	if err != nil {
@@ -44,29 +57,84 @@ func (ts *taskSrvc) ImportTaskFromZip(
 	if err := prepareTaskZipImages(archive, &task); err != nil {
 		return "", errInvalidTaskZip(err.Error())
 	}
+	return ts.importTask(ctx, archive, task, o)
+}
+
+func (ts *taskSrvc) applyImportOptions(opts []ImportOption) (importOptions, srvcerror.E) {
+	var o importOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.checkSolutions && ts.execSrvc == nil {
+		return o, ErrSolutionChecksUnavailable
+	}
+	if o.visibility != "" && !o.visibility.Valid() {
+		return o, errInvalidVisibility("nezināms redzamības stāvoklis '" + string(o.visibility) + "'")
+	}
+	return o, nil
+}
+
+// importTask uploads the archive assets of a mapped task and stores it
+// as a new task or as the next revision of an existing one.
+func (ts *taskSrvc) importTask(
+	ctx context.Context, archive taskzipv1.Task, task Task, o importOptions,
+) (string, srvcerror.E) {
 	exists, err := ts.repo.Exists(ctx, task.ShortId)
 	if err != nil {
 		ts.logger(ctx).Error("check task existence", "error", err)
 		return "", srvcerror.InternalServerError()
 	}
-	if exists {
-		return "", errTaskAlreadyExists(task.ShortId)
-	}
 	if err := ts.uploadTaskZipAssets(ctx, archive, &task); err != nil {
 		return "", err
 	}
-	if err := ts.repo.CreateTask(ctx, task); err != nil {
-		ts.logger(ctx).Error("create task", "error", err)
-		return "", srvcerror.InternalServerError()
+	if exists {
+		revision, err := ts.reimportTask(ctx, task)
+		if err != nil {
+			return "", err
+		}
+		task.Revision = revision
+	} else {
+		task.Revision = 1
+		task.Visibility = VisibilityPublished
+		if o.checkSolutions {
+			// stays unpublished until an admin has read the report
+			task.Visibility = VisibilityDraft
+		}
+		if o.visibility != "" {
+			task.Visibility = o.visibility
+		}
+		if err := ts.repo.CreateTask(ctx, task); err != nil {
+			ts.logger(ctx).Error("create task", "error", err)
+			return "", srvcerror.InternalServerError()
+		}
+	}
+	if o.checkSolutions {
+		// the task is imported either way; checks can be restarted
+		_ = ts.startSolutionChecks(ctx, task)
 	}
 	return task.ShortId, nil
 }
 
-func taskZipReadError(err error) srvcerror.E {
-	if errors.Is(err, taskzipv1.ErrInteractive) || errors.Is(err, taskzipv1.ErrAttached) {
-		return errUnsupportedTaskZip(err.Error())
+// reimportTask stores an imported task as the next revision of the
+// existing one. Parts that a TaskZip does not carry are kept.
+func (ts *taskSrvc) reimportTask(ctx context.Context, task Task) (int, srvcerror.E) {
+	prev, err := ts.repo.GetTask(ctx, task.ShortId)
+	if err != nil {
+		ts.logger(ctx).Error("get task", "task_id", task.ShortId, "error", err)
+		return 0, srvcerror.InternalServerError()
+	}
+	if task.IllustrImg == nil || task.IllustrImg.ObjectKey == "" {
+		task.IllustrImg = prev.IllustrImg
 	}
-	return errInvalidTaskZip(err.Error())
+	if task.OgFilesZipObjectKey == "" {
+		task.OgFilesZipObjectKey = prev.OgFilesZipObjectKey
+	}
+	revision, srvcErr := ts.reviseTask(ctx, prev, task)
+	if srvcErr != nil {
+		return 0, srvcErr
+	}
+	ts.logger(ctx).Info("task re-imported", "task_id", task.ShortId, "revision", revision)
+	return revision, nil
 }
 
 func prepareTaskZipImages(archive taskzipv1.Task, task *Task) error {
@@ -103,6 +171,24 @@ func (ts *taskSrvc) uploadTaskZipAssets(
 			return srvcerror.InternalServerError()
 		}
 	}
+	attached := make(map[string][]byte, len(archive.Attachments))
+	for _, attachment := range archive.Attachments {
+		attached[attachment.Path] = attachment.Data
+	}
+	for _, attachment := range task.Attachments {
+		data := attached[attachment.Path]
+		if _, err := ts.publicStore.Upload(
+			data, taskAttachmentObjectKey(attachment.ObjectKey), attachmentMime(attachment.Path),
+		); err != nil {
+			ts.logger(ctx).Error("upload attachment", "path", attachment.Path, "error", err)
+			return srvcerror.InternalServerError()
+		}
+	}
+	for _, file := range task.AuthoringFiles {
+		if err := ts.UploadTestFile(ctx, archive.AuthoringFiles[file.Path]); err != nil {
+			return err
+		}
+	}
 	task.Tests = make([]Test, len(archive.Tests))
 	for i, test := range archive.Tests {
 		if err := ts.uploadTaskZipTest(ctx, i, test, task); err != nil {
@@ -144,7 +230,7 @@ func (ts *taskSrvc) uploadTaskZipTest(
 }
 
 func (ts *taskSrvc) ExportTaskAsZip(ctx context.Context, taskID string) ([]byte, srvcerror.E) {
-	task, getErr := ts.GetTask(ctx, taskID)
+	task, getErr := ts.getStoredTask(ctx, taskID)
 	if getErr != nil {
 		return nil, getErr
 	}
@@ -176,6 +262,23 @@ func (ts *taskSrvc) downloadTaskZipAssets(
 		}
 		archive.StatementImages[image.Filename] = data
 	}
+	for _, attachment := range task.Attachments {
+		data, err := ts.publicStore.Download(taskAttachmentObjectKey(attachment.ObjectKey))
+		if err != nil {
+			return fmt.Errorf("download attachment %s: %w", attachment.Path, err)
+		}
+		archive.Attachments = append(archive.Attachments, taskzipv1.Attachment{Path: attachment.Path, Data: data})
+	}
+	for _, file := range task.AuthoringFiles {
+		data, err := ts.DownloadTestFile(ctx, file.Sha2)
+		if err != nil {
+			return fmt.Errorf("download authoring file %s: %w", file.Path, err)
+		}
+		if archive.AuthoringFiles == nil {
+			archive.AuthoringFiles = map[string][]byte{}
+		}
+		archive.AuthoringFiles[file.Path] = data
+	}
 	for _, test := range task.Tests {
 		input, err := ts.DownloadTestFile(ctx, test.InpSha2)
 		if err != nil {
@@ -223,12 +326,14 @@ func mapTaskZipOrigin(t taskzipv1.Task, res *Task) {
 		if t.Origin.Year != nil {
 			res.OriginYear = strconv.Itoa(*t.Origin.Year)
 		}
+		res.OriginContestants = uint32PtrToInt(t.Origin.Contestants)
+		res.OriginSolvers = uint32PtrToInt(t.Origin.Solvers)
 	}
 	if t.Metadata != nil {
 		res.DifficultyRating = int(*t.Metadata.Difficulty)
-		res.ProblemTags = appendAllowed(res.ProblemTags, t.Metadata.Topics, taskZipTopics)
-		res.ProblemTags = appendAllowed(res.ProblemTags, t.Metadata.Techniques, taskZipTechniques)
-		res.ProblemTags = appendAllowed(res.ProblemTags, t.Metadata.DataStructures, taskZipDataStructures)
+		res.ProblemTags = t.Metadata.Topics
+		res.Techniques = t.Metadata.Techniques
+		res.DataStructures = t.Metadata.DataStructures
 	}
 }
 
@@ -246,12 +351,33 @@ func mapTaskZipContent(t taskzipv1.Task, res *Task) {
 		})
 	}
 	res.Checker = string(t.Checker)
+	res.Interactor = string(t.Interactor)
 	for _, solution := range t.Solutions {
 		res.Solutions = append(res.Solutions, Solution{
 			Fname: solution.Filename, Content: string(solution.Data),
 			Subtasks: uint32sToInts(solution.Subtasks),
+			Score:    uint32PtrToInt(solution.Score),
+		})
+	}
+	for _, attachment := range t.Attachments {
+		res.Attachments = append(res.Attachments, Attachment{
+			Path:      attachment.Path,
+			ObjectKey: fmt.Sprintf("%s/%s/%s", res.ShortId, sha2Hex(attachment.Data)[:12], attachment.Path),
+			SzInBytes: len(attachment.Data),
 		})
 	}
+	// the repository lists attachments by path
+	sort.Slice(res.Attachments, func(i, j int) bool {
+		return res.Attachments[i].Path < res.Attachments[j].Path
+	})
+	for name, data := range t.AuthoringFiles {
+		res.AuthoringFiles = append(res.AuthoringFiles, AuthoringFile{
+			Path: name, Sha2: sha2Hex(data), SzInBytes: len(data),
+		})
+	}
+	sort.Slice(res.AuthoringFiles, func(i, j int) bool {
+		return res.AuthoringFiles[i].Path < res.AuthoringFiles[j].Path
+	})
 }
 
 func mapTaskZipScoring(t taskzipv1.Task, res *Task) error {
@@ -345,6 +471,9 @@ func mapToTaskZip(t Task) (taskzipv1.Task, error) {
 	if t.Checker != "" {
 		res.Checker = []byte(t.Checker)
 	}
+	if t.Interactor != "" {
+		res.Interactor = []byte(t.Interactor)
+	}
 	mapServiceOrigin(t, &res)
 	mapServiceContent(t, &res)
 	if err := mapServiceScoring(t, &res); err != nil {
@@ -357,19 +486,23 @@ func mapServiceOrigin(t Task, res *taskzipv1.Task) {
 	origin := &taskzipv1.Origin{
 		Olymp: t.OriginOlympiad, Stage: t.OlympStage, Org: t.OriginOrg,
 		Divisions: t.OriginDivisions, Authors: t.Authors, Lang: t.OrigLang,
+		Contestants: intPtrToUint32(t.OriginContestants),
+		Solvers:     intPtrToUint32(t.OriginSolvers),
 	}
 	if year, err := strconv.Atoi(t.OriginYear); err == nil {
 		origin.Year = &year
 	}
 	if origin.Olymp != "" || origin.Stage != "" || origin.Org != "" ||
 		len(origin.Divisions) != 0 || len(origin.Authors) != 0 ||
-		origin.Lang != "" || origin.Year != nil {
+		origin.Lang != "" || origin.Year != nil ||
+		origin.Contestants != nil || origin.Solvers != nil {
 		res.Origin = origin
 	}
 	if t.DifficultyRating != 0 {
 		difficulty := uint8(t.DifficultyRating)
 		res.Metadata = &taskzipv1.Metadata{
-			Topics: t.ProblemTags, Difficulty: &difficulty,
+			Topics: t.ProblemTags, Techniques: t.Techniques,
+			DataStructures: t.DataStructures, Difficulty: &difficulty,
 		}
 	}
 }
@@ -391,6 +524,7 @@ func mapServiceContent(t Task, res *taskzipv1.Task) {
 		res.Solutions = append(res.Solutions, taskzipv1.Solution{
 			Filename: solution.Fname, Data: []byte(solution.Content),
 			Subtasks: intsToUint32s(solution.Subtasks),
+			Score:    intPtrToUint32(solution.Score),
 		})
 	}
 }
@@ -530,6 +664,22 @@ func intsToUint32s(values []int) []uint32 {
 	return res
 }
 
+func uint32PtrToInt(value *uint32) *int {
+	if value == nil {
+		return nil
+	}
+	res := int(*value)
+	return &res
+}
+
+func intPtrToUint32(value *int) *uint32 {
+	if value == nil || *value < 0 {
+		return nil
+	}
+	res := uint32(*value)
+	return &res
+}
+
 func equalInts(a, b []int) bool {
 	if len(a) != len(b) {
 		return false
@@ -542,6 +692,8 @@ func equalInts(a, b []int) bool {
 	return true
 }
 
+// The TaskZip vocabularies. Migration 084 copies them to split the tags
+// of tasks stored before the categories were kept apart.
 var taskZipTopics = stringSet(
 	"implementation", "arrays", "strings", "sorting-searching", "mathematics",
 	"number-theory", "combinatorics", "graphs", "trees", "grids", "geometry",
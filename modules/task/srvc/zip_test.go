@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/programme-lv/backend/common/filestore"
@@ -49,13 +50,14 @@ func TestImportExportTaskZip(t *testing.T) {
 	require.Contains(t, imported.MdStatements[0].Story, "veģetāra čūska")
 	require.NotEmpty(t, imported.Checker)
 	require.Empty(t, imported.OgFilesZipObjectKey)
+	require.NotEmpty(t, imported.AuthoringFiles)
 	imported.OriginDivisions = []string{"junior", "senior"}
 
 	repo.EXPECT().Exists(ctx, id).Return(true, nil).Once()
 	repo.EXPECT().GetTask(ctx, id).Return(imported, nil).Once()
 	exported, exportErr := service.ExportTaskAsZip(ctx, id)
 	require.NoError(t, exportErr)
-	assertIgnoredDirectoriesOmitted(t, exported)
+	assertAuthoringFilesKept(t, data, exported)
 
 	parsed, err := taskzipv1.Read(exported)
 	require.NoError(t, err)
@@ -82,13 +84,76 @@ func TestImportExportTaskZip(t *testing.T) {
 	require.Len(t, reimported.Subtasks, len(imported.Subtasks))
 	require.Equal(t, imported.FullName, reimported.FullName)
 	require.Equal(t, imported.OriginDivisions, reimported.OriginDivisions)
+	require.Equal(t, imported.AuthoringFiles, reimported.AuthoringFiles)
 }
 
-func assertIgnoredDirectoriesOmitted(t *testing.T, data []byte) {
+func assertAuthoringFilesKept(t *testing.T, original, exported []byte) {
 	t.Helper()
-	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	authoringFiles := func(data []byte) map[string]uint64 {
+		reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+		files := map[string]uint64{}
+		for _, file := range reader.File {
+			if regexp.MustCompile(`^(archive|testspec)/.*[^/]$`).MatchString(file.Name) {
+				files[file.Name] = uint64(file.CRC32)
+			}
+		}
+		return files
+	}
+	want := authoringFiles(original)
+	require.NotEmpty(t, want)
+	require.Equal(t, want, authoringFiles(exported))
+}
+
+func TestImportExportTaskZipAttachments(t *testing.T) {
+	ctx := context.Background()
+	repo := mocktasksrvc.NewMockTaskPgRepo(t)
+	publicStore, err := filestore.NewStore(t.TempDir())
 	require.NoError(t, err)
-	for _, file := range reader.File {
-		require.NotRegexp(t, `^(archive|testspec)/`, file.Name)
+	service := srvc.NewTaskSrvc(repo, publicStore, publicStore, srvc.WithPublicAPIBaseURL("https://api.example"))
+
+	archive := taskzipv1.Task{
+		Version:    1,
+		ID:         "guess",
+		Name:       map[string]string{"lv": "Minēšana"},
+		Testing:    taskzipv1.Testing{Type: "simple", CPUMs: 1000, MemMiB: 256},
+		Statements: map[string][]byte{"lv": []byte("Uzminiet skaitli.\n")},
+		Tests:      []taskzipv1.Test{{Input: []byte("1\n"), Output: []byte("1\n")}},
+		Attachments: []taskzipv1.Attachment{
+			{Path: "cpp/grader.cpp", Data: []byte("int main() {}\n")},
+			{Path: "cpp/guess.h", Data: []byte("int guess(int x);\n")},
+		},
 	}
+	data, err := taskzipv1.Write(archive)
+	require.NoError(t, err)
+
+	var imported srvc.Task
+	repo.EXPECT().Exists(ctx, "guess").Return(false, nil).Once()
+	repo.EXPECT().CreateTask(ctx, mock.Anything).RunAndReturn(
+		func(_ context.Context, task srvc.Task) error {
+			imported = task
+			return nil
+		},
+	).Once()
+	_, importErr := service.ImportTaskFromZip(ctx, data, "")
+	require.NoError(t, importErr)
+	require.Len(t, imported.Attachments, 2)
+	header := imported.Attachments[1]
+	require.Equal(t, "cpp/guess.h", header.Path)
+	require.Equal(t, 18, header.SzInBytes)
+	require.Regexp(t, `^guess/[0-9a-f]{12}/cpp/guess\.h$`, header.ObjectKey)
+	stored, err := publicStore.Download("attachments/" + header.ObjectKey)
+	require.NoError(t, err)
+	require.Equal(t, "int guess(int x);\n", string(stored))
+	url, urlErr := service.GetHttpUrlForAttachment(ctx, header.ObjectKey)
+	require.NoError(t, urlErr)
+	require.Equal(t, "https://api.example/assets/attachments/"+header.ObjectKey, url)
+
+	repo.EXPECT().Exists(ctx, "guess").Return(true, nil).Once()
+	repo.EXPECT().GetTask(ctx, "guess").Return(imported, nil).Once()
+	exported, exportErr := service.ExportTaskAsZip(ctx, "guess")
+	require.NoError(t, exportErr)
+	parsed, err := taskzipv1.Read(exported)
+	require.NoError(t, err)
+	require.Equal(t, archive.Attachments, parsed.Attachments)
 }
@@ -7,10 +7,13 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestMapTaskZipMetadataDropsInvalidSlugs(t *testing.T) {
+func TestMapTaskZipMetadataRoundTrip(t *testing.T) {
 	difficulty := uint8(3)
+	contestants, solvers := uint32(120), uint32(7)
 	archive := taskzipv1.Task{
-		Origin: &taskzipv1.Origin{Divisions: []string{"junior", "senior"}},
+		Origin: &taskzipv1.Origin{
+			Divisions: []string{"junior", "senior"}, Contestants: &contestants, Solvers: &solvers,
+		},
 		Metadata: &taskzipv1.Metadata{
 			Topics:         []string{"graphs", "invalid"},
 			Techniques:     []string{"bfs", "invalid"},
@@ -22,7 +25,33 @@ func TestMapTaskZipMetadataDropsInvalidSlugs(t *testing.T) {
 
 	mapTaskZipOrigin(archive, &task)
 
-	require.Equal(t, []string{"graphs", "bfs", "queue"}, task.ProblemTags)
+	require.Equal(t, []string{"graphs", "invalid"}, task.ProblemTags)
+	require.Equal(t, []string{"bfs", "invalid"}, task.Techniques)
+	require.Equal(t, []string{"queue", "invalid"}, task.DataStructures)
 	require.Equal(t, []string{"junior", "senior"}, task.OriginDivisions)
 	require.Equal(t, 3, task.DifficultyRating)
+	require.Equal(t, 120, *task.OriginContestants)
+
+	var exported taskzipv1.Task
+	mapServiceOrigin(task, &exported)
+	require.Equal(t, archive.Metadata, exported.Metadata)
+	require.Equal(t, archive.Origin.Contestants, exported.Origin.Contestants)
+	require.Equal(t, archive.Origin.Solvers, exported.Origin.Solvers)
+}
+
+func TestMapTaskZipInteractorRoundTrip(t *testing.T) {
+	var task Task
+	mapTaskZipContent(taskzipv1.Task{Interactor: []byte("int main() {}")}, &task)
+	require.Equal(t, "int main() {}", task.Interactor)
+	require.Empty(t, task.Checker)
+
+	task.ShortId = "guess"
+	task.FullName = map[string]string{"lv": "Minēšana"}
+	task.CpuTimeLimSecs = 1
+	task.MemLimMegabytes = 256
+	archive, err := mapToTaskZip(task)
+	require.NoError(t, err)
+	require.Equal(t, "interactor", archive.Testing.Type)
+	require.Equal(t, []byte("int main() {}"), archive.Interactor)
+	require.Nil(t, archive.Checker)
 }
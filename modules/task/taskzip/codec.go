@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"path"
 	"regexp"
 	"sort"
@@ -95,24 +96,18 @@ func Read(data []byte) (Task, error) {
 	if err := dec.Decode(&meta); err != nil {
 		return Task{}, fmt.Errorf("task.toml: %w", err)
 	}
-	if len(meta.Attached) != 0 || hasPrefix(files, "attached/") {
-		return Task{}, ErrAttached
-	}
-	if meta.Testing.Type == "interactor" {
-		return Task{}, ErrInteractive
-	}
 	task := fromTOML(meta)
 	if err := consumeFiles(&task, files); err != nil {
 		return Task{}, err
 	}
-	if err := validate(&task); err != nil {
+	if err := Validate(&task); err != nil {
 		return Task{}, err
 	}
 	return task, nil
 }
 
 func Write(task Task) ([]byte, error) {
-	if err := validate(&task); err != nil {
+	if err := Validate(&task); err != nil {
 		return nil, err
 	}
 	meta := toTOML(task)
@@ -151,12 +146,9 @@ func Write(task Task) ([]byte, error) {
 }
 
 func readZIP(data []byte) (map[string][]byte, error) {
-	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	zr, err := openZIP(data)
 	if err != nil {
-		return nil, fmt.Errorf("zip: %w", err)
-	}
-	if len(zr.File) > maxImportFiles {
-		return nil, errors.New("zip has too many entries")
+		return nil, err
 	}
 	flat := false
 	for _, f := range zr.File {
@@ -166,6 +158,62 @@ func readZIP(data []byte) (map[string][]byte, error) {
 		}
 		flat = flat || name == "task.toml"
 	}
+	raw, err := readEntries(zr, func(name string) uint64 {
+		return maxFileSize(rootRelativePath(name, flat))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resolveRoot(raw)
+}
+
+// ReadFiles reads every file of a ZIP archive from another package format
+// with the same path checks and size limits as a TaskZip import.
+// Directory entries are skipped. The archive holds marker, such as
+// problem.xml, at its root or inside one directory, which is stripped.
+func ReadFiles(data []byte, marker string) (map[string][]byte, error) {
+	zr, err := openZIP(data)
+	if err != nil {
+		return nil, err
+	}
+	files, err := readEntries(zr, func(string) uint64 { return 256 << 20 })
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := files[marker]; ok {
+		return files, nil
+	}
+	var root string
+	for name := range files {
+		if dir, base := path.Split(name); base == marker && strings.Count(dir, "/") == 1 {
+			root = dir
+		}
+	}
+	if root == "" {
+		return nil, fmt.Errorf("%s missing", marker)
+	}
+	res := make(map[string][]byte, len(files))
+	for name, data := range files {
+		if rest, ok := strings.CutPrefix(name, root); ok {
+			res[rest] = data
+		}
+	}
+	return res, nil
+}
+
+func openZIP(data []byte) (*zip.Reader, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("zip: %w", err)
+	}
+	if len(zr.File) > maxImportFiles {
+		return nil, errors.New("zip has too many entries")
+	}
+	return zr, nil
+}
+
+// readEntries reads the files of zr, each up to fileLimit(name) bytes.
+func readEntries(zr *zip.Reader, fileLimit func(name string) uint64) (map[string][]byte, error) {
 	raw := make(map[string][]byte)
 	seen := make(map[string]struct{})
 	var totalSize uint64
@@ -185,25 +233,40 @@ func readZIP(data []byte) (map[string][]byte, error) {
 		if f.FileInfo().IsDir() {
 			continue
 		}
-		logicalName := rootRelativePath(name, flat)
-		if ignoredPath(logicalName) {
-			continue
-		}
-		fileLimit := maxFileSize(logicalName)
-		if f.UncompressedSize64 > fileLimit {
+		limit := fileLimit(name)
+		if f.UncompressedSize64 > limit {
 			return nil, fmt.Errorf("%s too large", name)
 		}
 		if f.UncompressedSize64 > maxImportSize-totalSize {
 			return nil, errors.New("zip contents too large")
 		}
 		totalSize += f.UncompressedSize64
-		b, err := readEntry(f, fileLimit)
+		b, err := readEntry(f, limit)
 		if err != nil {
 			return nil, fmt.Errorf("read %s: %w", name, err)
 		}
 		raw[name] = b
 	}
-	return resolveRoot(raw)
+	return raw, nil
+}
+
+// LF converts CRLF line endings, which TaskZip rejects.
+func LF(data []byte) []byte {
+	return []byte(strings.ReplaceAll(string(data), "\r\n", "\n"))
+}
+
+// ParsePoints accepts decimal points of other package formats when they
+// are whole. Fractional points, which TaskZip cannot store, are reported
+// wrapping unsupported.
+func ParsePoints(s string, unsupported error) (float64, error) {
+	points, err := strconv.ParseFloat(s, 64)
+	if err != nil || points < 0 {
+		return 0, fmt.Errorf("invalid points %q", s)
+	}
+	if points != math.Trunc(points) {
+		return 0, fmt.Errorf("%w: fractional points %s", unsupported, s)
+	}
+	return points, nil
 }
 
 func rootRelativePath(name string, flat bool) string {
@@ -217,7 +280,7 @@ func rootRelativePath(name string, flat bool) string {
 	return rest
 }
 
-func ignoredPath(name string) bool {
+func authoringPath(name string) bool {
 	return strings.HasPrefix(name, "archive/") || strings.HasPrefix(name, "testspec/")
 }
 
@@ -323,6 +386,9 @@ func fromTOML(m taskTOML) Task {
 			Filename: s.Filename, Subtasks: s.Subtasks, Score: s.Score,
 		})
 	}
+	for _, a := range m.Attached {
+		t.Attachments = append(t.Attachments, Attachment{Path: a.Path})
+	}
 	for _, s := range m.Subtasks {
 		t.Subtasks = append(t.Subtasks, Subtask{
 			Points: s.Points, Tests: s.Tests, Groups: s.Groups,
@@ -356,6 +422,9 @@ func toTOML(t Task) taskTOML {
 			Filename: s.Filename, Subtasks: s.Subtasks, Score: s.Score,
 		})
 	}
+	for _, a := range t.Attachments {
+		m.Attached = append(m.Attached, attachedTOML{Path: a.Path})
+	}
 	for _, s := range t.Subtasks {
 		m.Subtasks = append(m.Subtasks, subtaskTOML{
 			Points: s.Points, Tests: s.Tests, Groups: s.Groups,
@@ -379,15 +448,6 @@ func toTOML(t Task) taskTOML {
 	return m
 }
 
-func hasPrefix(files map[string][]byte, prefix string) bool {
-	for name := range files {
-		if strings.HasPrefix(name, prefix) {
-			return true
-		}
-	}
-	return false
-}
-
 func number(match []string) (int, error) {
 	n, err := strconv.Atoi(match[1])
 	if err != nil || n == 0 {
@@ -3,8 +3,8 @@ package taskzip
 import (
 	"archive/zip"
 	"bytes"
-	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -75,7 +75,7 @@ func TestGroupedScoring(t *testing.T) {
 	}
 }
 
-func TestWrapperAndIgnoredDirectories(t *testing.T) {
+func TestWrapperAndAuthoringDirectories(t *testing.T) {
 	data, err := Write(minimalTask())
 	if err != nil {
 		t.Fatal(err)
@@ -86,29 +86,104 @@ func TestWrapperAndIgnoredDirectories(t *testing.T) {
 		"sum/statement/en.md":    files["statement/en.md"],
 		"sum/tests/001i.txt":     files["tests/001i.txt"],
 		"sum/tests/001o.txt":     files["tests/001o.txt"],
-		"sum/archive/source.pdf": []byte("ignored"),
-		"sum/testspec/tests.txt": []byte("ignored"),
+		"sum/archive/source.pdf": []byte("%PDF"),
+		"sum/testspec/tests.txt": []byte("1\n"),
 	}
-	if _, err := Read(zipForTest(t, wrapped)); err != nil {
+	task, err := Read(zipForTest(t, wrapped))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]byte{"archive/source.pdf": []byte("%PDF"), "testspec/tests.txt": []byte("1\n")}
+	if !reflect.DeepEqual(task.AuthoringFiles, want) {
+		t.Fatalf("authoring files not kept: %v", task.AuthoringFiles)
+	}
+	data, err = Write(task)
+	if err != nil {
 		t.Fatal(err)
 	}
+	if got := unzipForTest(t, data); string(got["archive/source.pdf"]) != "%PDF" {
+		t.Fatal("authoring files not written")
+	}
 	wrapped["other/file"] = []byte("bad")
 	if _, err := Read(zipForTest(t, wrapped)); err == nil {
 		t.Fatal("accepted multiple wrappers")
 	}
 }
 
-func TestClassifiedUnsupportedFeatures(t *testing.T) {
+func TestReadFilesStripsMarkerDirectory(t *testing.T) {
+	flat := map[string][]byte{"problem.xml": []byte("<problem/>"), "files/check.cpp": []byte("int main() {}")}
+	for name, data := range map[string][]byte{
+		"flat":    zipForTest(t, flat),
+		"wrapped": zipForTest(t, map[string][]byte{"a-plus-b/problem.xml": flat["problem.xml"], "a-plus-b/files/check.cpp": flat["files/check.cpp"]}),
+	} {
+		files, err := ReadFiles(data, "problem.xml")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(files, flat) {
+			t.Fatalf("%s: unexpected files %v", name, files)
+		}
+	}
+	nested := zipForTest(t, map[string][]byte{"a/b/problem.xml": flat["problem.xml"]})
+	if _, err := ReadFiles(nested, "problem.xml"); err == nil {
+		t.Fatal("accepted a marker nested twice")
+	}
+}
+
+func TestInteractiveRoundTrip(t *testing.T) {
 	task := minimalTask()
 	task.Testing.Type = "interactor"
-	if _, err := Write(task); !errors.Is(err, ErrInteractive) {
-		t.Fatalf("got %v", err)
+	if _, err := Write(task); err == nil {
+		t.Fatal("accepted interactive task without interactor.cpp")
+	}
+
+	task.Interactor = []byte("int main() {}\n")
+	data, err := Write(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Testing.Type != "interactor" || !bytes.Equal(got.Interactor, task.Interactor) {
+		t.Fatalf("unexpected interactive task: %#v", got)
+	}
+
+	files := archiveFiles(t, minimalTask())
+	files["interactor.cpp"] = []byte("int main() {}\n")
+	if _, err := Read(zipForTest(t, files)); err == nil {
+		t.Fatal("accepted interactor.cpp for simple task")
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	task := minimalTask()
+	task.Attachments = []Attachment{
+		{Path: "grader.h", Data: []byte("int solve(int n);\n")},
+		{Path: "cpp/grader.cpp", Data: []byte("int main() {}\n")},
+	}
+	data, err := Write(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Attachments) != 2 || got.Attachments[1].Path != "cpp/grader.cpp" ||
+		string(got.Attachments[0].Data) != "int solve(int n);\n" {
+		t.Fatalf("attachments not round-tripped: %+v", got.Attachments)
 	}
 
 	files := archiveFiles(t, minimalTask())
 	files["attached/grader.h"] = []byte("x")
-	if _, err := Read(zipForTest(t, files)); !errors.Is(err, ErrAttached) {
-		t.Fatalf("got %v", err)
+	if _, err := Read(zipForTest(t, files)); err == nil {
+		t.Fatal("accepted unlisted attachment")
+	}
+	task.Attachments = []Attachment{{Path: "../grader.h", Data: []byte("x")}}
+	if _, err := Write(task); err == nil {
+		t.Fatal("accepted attachment outside attached/")
 	}
 }
 
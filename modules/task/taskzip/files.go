@@ -17,6 +17,10 @@ func consumeFiles(task *Task, files map[string][]byte) error {
 	for i := range task.Solutions {
 		solutions[task.Solutions[i].Filename] = &task.Solutions[i]
 	}
+	attachments := map[string]*Attachment{}
+	for i := range task.Attachments {
+		attachments[task.Attachments[i].Path] = &task.Attachments[i]
+	}
 	for name, data := range files {
 		switch {
 		case name == "task.toml":
@@ -25,16 +29,24 @@ func consumeFiles(task *Task, files map[string][]byte) error {
 		case name == "checker.cpp":
 			task.Checker = data
 		case name == "interactor.cpp":
-			return ErrInteractive
+			task.Interactor = data
 		case name == "tgroups.txt":
 			groups, err := parseGroups(data)
 			if err != nil {
 				return err
 			}
 			task.TestGroups = groups
-		case strings.HasPrefix(name, "archive/"), strings.HasPrefix(name, "testspec/"):
+		case authoringPath(name):
+			if task.AuthoringFiles == nil {
+				task.AuthoringFiles = map[string][]byte{}
+			}
+			task.AuthoringFiles[name] = data
 		case strings.HasPrefix(name, "attached/"):
-			return ErrAttached
+			a, ok := attachments[strings.TrimPrefix(name, "attached/")]
+			if !ok {
+				return fmt.Errorf("unlisted attachment %s", name)
+			}
+			a.Data = data
 		case testRE.MatchString(name):
 			m := testRE.FindStringSubmatch(name)
 			n, err := number(m)
@@ -107,6 +119,11 @@ func consumeFiles(task *Task, files map[string][]byte) error {
 			return fmt.Errorf("missing solutions/%s", s.Filename)
 		}
 	}
+	for _, a := range task.Attachments {
+		if a.Data == nil {
+			return fmt.Errorf("missing attached/%s", a.Path)
+		}
+	}
 	return nil
 }
 
@@ -263,9 +280,18 @@ func taskFiles(task Task, meta []byte) (map[string][]byte, error) {
 	if task.Checker != nil {
 		files["checker.cpp"] = task.Checker
 	}
+	if task.Interactor != nil {
+		files["interactor.cpp"] = task.Interactor
+	}
 	for _, solution := range task.Solutions {
 		files[path.Join("solutions", solution.Filename)] = solution.Data
 	}
+	for _, attachment := range task.Attachments {
+		files["attached/"+attachment.Path] = attachment.Data
+	}
+	for name, data := range task.AuthoringFiles {
+		files[name] = data
+	}
 	if len(task.TestGroups) != 0 {
 		files["tgroups.txt"] = renderGroups(task.TestGroups)
 	}
@@ -1,12 +1,5 @@
 package taskzip
 
-import "errors"
-
-var (
-	ErrInteractive = errors.New("interactive TaskZip is unsupported")
-	ErrAttached    = errors.New("attached TaskZip files are unsupported")
-)
-
 type Task struct {
 	Version         uint32
 	ID              string
@@ -18,12 +11,17 @@ type Task struct {
 	Examples        []Example
 	Tests           []Test
 	Checker         []byte
+	Interactor      []byte
 	Solutions       []Solution
-	Subtasks        []Subtask
-	TestGroups      []TestGroup
-	Origin          *Origin
-	Metadata        *Metadata
-	Extensions      map[string]any
+	Attachments     []Attachment
+	// AuthoringFiles are the archive/ and testspec/ files by their path
+	// in the archive. They are kept as they are and not interpreted.
+	AuthoringFiles map[string][]byte
+	Subtasks       []Subtask
+	TestGroups     []TestGroup
+	Origin         *Origin
+	Metadata       *Metadata
+	Extensions     map[string]any
 }
 
 type Testing struct {
@@ -50,6 +48,13 @@ type Solution struct {
 	Data     []byte
 }
 
+// Attachment is a file handed to contestants, such as a grader header
+// or a sample archive, stored under attached/ at Path.
+type Attachment struct {
+	Path string
+	Data []byte
+}
+
 type Subtask struct {
 	Points       *uint32
 	Tests        string
@@ -17,10 +17,8 @@ var (
 	divisionSlugRE = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
 )
 
-func validate(task *Task) error {
-	if task.Testing.Type == "interactor" {
-		return ErrInteractive
-	}
+// Validate checks that task can be written as a TaskZip v1 archive.
+func Validate(task *Task) error {
 	if task.Version != 1 {
 		return errors.New("taskzip must be 1")
 	}
@@ -33,10 +31,18 @@ func validate(task *Task) error {
 	if err := validateContent(task); err != nil {
 		return err
 	}
-	if err := validateScoring(task); err != nil {
+	if err := ValidateScoring(task); err != nil {
 		return err
 	}
-	return validateSolutions(task)
+	if err := validateAttachments(task.Attachments); err != nil {
+		return err
+	}
+	for name, data := range task.AuthoringFiles {
+		if clean, err := safePath(name); err != nil || clean != name || !authoringPath(name) || data == nil {
+			return fmt.Errorf("invalid authoring file %q", name)
+		}
+	}
+	return nil
 }
 
 func validateMetadata(task *Task) error {
@@ -48,17 +54,50 @@ func validateMetadata(task *Task) error {
 			return fmt.Errorf("invalid name entry %q", lang)
 		}
 	}
+	if err := ValidateTesting(task); err != nil {
+		return err
+	}
+	if task.Origin != nil {
+		if err := validateOrigin(task.Origin); err != nil {
+			return err
+		}
+	}
+	if task.Metadata != nil {
+		if task.Metadata.Difficulty == nil {
+			return errors.New("metadata.difficulty missing")
+		}
+		if *task.Metadata.Difficulty < 1 || *task.Metadata.Difficulty > 5 {
+			return errors.New("metadata.difficulty out of range")
+		}
+	}
+	return nil
+}
+
+// ValidateTesting checks the testing type against the checker and
+// interactor sources, and the CPU and memory limits.
+func ValidateTesting(task *Task) error {
 	switch task.Testing.Type {
 	case "simple":
 		if task.Checker != nil {
 			return errors.New("checker.cpp present for simple task")
 		}
+		if task.Interactor != nil {
+			return errors.New("interactor.cpp present for simple task")
+		}
 	case "checker":
 		if task.Checker == nil {
 			return errors.New("checker.cpp missing")
 		}
+		if task.Interactor != nil {
+			return errors.New("interactor.cpp present for checker task")
+		}
 	case "interactor":
-		return ErrInteractive
+		if task.Interactor == nil {
+			return errors.New("interactor.cpp missing")
+		}
+		if task.Checker != nil {
+			return errors.New("checker.cpp present for interactive task")
+		}
 	default:
 		return fmt.Errorf("unknown testing type %q", task.Testing.Type)
 	}
@@ -68,19 +107,6 @@ func validateMetadata(task *Task) error {
 	if task.Testing.MemMiB < 40 || task.Testing.MemMiB > 4096 {
 		return errors.New("testing.mem_mib out of range")
 	}
-	if task.Origin != nil {
-		if err := validateOrigin(task.Origin); err != nil {
-			return err
-		}
-	}
-	if task.Metadata != nil {
-		if task.Metadata.Difficulty == nil {
-			return errors.New("metadata.difficulty missing")
-		}
-		if *task.Metadata.Difficulty < 1 || *task.Metadata.Difficulty > 5 {
-			return errors.New("metadata.difficulty out of range")
-		}
-	}
 	return nil
 }
 
@@ -128,20 +154,8 @@ func validateDivisions(divisions []string) error {
 }
 
 func validateContent(task *Task) error {
-	if len(task.Tests) == 0 {
-		return errors.New("no official tests")
-	}
-	if len(task.Tests) > 999 {
-		return errors.New("too many official tests")
-	}
 	for i, test := range task.Tests {
-		if test.Input == nil || len(test.Input) == 0 || test.Output == nil {
-			return fmt.Errorf("test %03d needs input and output", i+1)
-		}
-		if err := checkText(fmt.Sprintf("tests/%03di.txt", i+1), test.Input, true); err != nil {
-			return err
-		}
-		if err := checkText(fmt.Sprintf("tests/%03do.txt", i+1), test.Output, false); err != nil {
+		if err := ValidateTest(i+1, test); err != nil {
 			return err
 		}
 	}
@@ -198,6 +212,33 @@ func validateExamples(examples []Example) error {
 	return nil
 }
 
+// ValidateTest checks the input and answer of test number id.
+func ValidateTest(id int, test Test) error {
+	if len(test.Input) == 0 || test.Output == nil {
+		return fmt.Errorf("test %03d needs input and output", id)
+	}
+	if err := checkText(fmt.Sprintf("tests/%03di.txt", id), test.Input, true); err != nil {
+		return err
+	}
+	return checkText(fmt.Sprintf("tests/%03do.txt", id), test.Output, false)
+}
+
+// ValidateScoring checks the number of tests, that subtasks and test
+// groups partition them, and the subtasks that solutions declare.
+// Test contents are not read.
+func ValidateScoring(task *Task) error {
+	if len(task.Tests) == 0 {
+		return errors.New("no official tests")
+	}
+	if len(task.Tests) > 999 {
+		return errors.New("too many official tests")
+	}
+	if err := validateScoring(task); err != nil {
+		return err
+	}
+	return validateSolutions(task)
+}
+
 func validateScoring(task *Task) error {
 	if len(task.Subtasks) == 0 {
 		if len(task.TestGroups) != 0 {
@@ -274,6 +315,24 @@ func validateSolutions(task *Task) error {
 	return nil
 }
 
+// validateAttachments allows nested relative paths, so that graders
+// for several languages can keep their own directories.
+func validateAttachments(attachments []Attachment) error {
+	if len(attachments) > 100 {
+		return errors.New("too many attachments")
+	}
+	seen := map[string]bool{}
+	for _, a := range attachments {
+		if a.Path == "" || strings.Contains(a.Path, `\`) || strings.HasPrefix(a.Path, "/") ||
+			path.Clean(a.Path) != a.Path || a.Path == ".." || strings.HasPrefix(a.Path, "../") ||
+			seen[a.Path] || a.Data == nil {
+			return fmt.Errorf("invalid attachment %q", a.Path)
+		}
+		seen[a.Path] = true
+	}
+	return nil
+}
+
 func taskTotal(task *Task) uint32 {
 	if len(task.Subtasks) == 0 {
 		return uint32(len(task.Tests))
@@ -0,0 +1,12 @@
+-- Subtask-scored evaluations were stored with zero received and possible
+-- points. Clearing the score columns makes the server recompute them on
+-- startup (see runScoreMigrationIfNeeded).
+UPDATE evaluations
+SET received_score = NULL,
+    possible_score = NULL,
+    scorebar_green = NULL,
+    scorebar_red = NULL,
+    scorebar_gray = NULL,
+    scorebar_yellow = NULL,
+    scorebar_purple = NULL
+WHERE score_unit = 'subtask';
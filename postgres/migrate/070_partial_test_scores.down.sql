@@ -0,0 +1,6 @@
+ALTER TABLE evaluations
+	ALTER COLUMN received_score TYPE INTEGER USING floor(received_score);
+
+ALTER TABLE eval_test_results
+	DROP COLUMN IF EXISTS score,
+	DROP COLUMN IF EXISTS pc;
@@ -0,0 +1,7 @@
+ALTER TABLE eval_test_results
+	ADD COLUMN pc BOOLEAN NOT NULL DEFAULT FALSE,
+	ADD COLUMN score DOUBLE PRECISION;
+
+-- Partially correct tests make received points fractional.
+ALTER TABLE evaluations
+	ALTER COLUMN received_score TYPE DOUBLE PRECISION;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS exec_pending;
+DROP TABLE IF EXISTS exec_run_data;
+DROP TABLE IF EXISTS exec_tests;
+DROP TABLE IF EXISTS executions;
@@ -0,0 +1,59 @@
+CREATE TABLE executions (
+    uuid UUID PRIMARY KEY,
+    stage VARCHAR(20) NOT NULL,
+    lang_id VARCHAR(20) NOT NULL,
+    pr_lang JSONB NOT NULL,
+    cpu_lim_ms INTEGER NOT NULL,
+    mem_lim_kib INTEGER NOT NULL,
+    params JSONB NOT NULL,
+    error_msg TEXT,
+    sys_info TEXT,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_executions_lang_created ON executions (lang_id, created_at);
+
+CREATE TABLE exec_tests (
+    exec_uuid UUID NOT NULL REFERENCES executions(uuid) ON DELETE CASCADE,
+    test_id INTEGER NOT NULL,
+    input_preview TEXT,
+    answer_preview TEXT,
+    reached BOOLEAN NOT NULL,
+    ignored BOOLEAN NOT NULL,
+    finished BOOLEAN NOT NULL,
+    PRIMARY KEY (exec_uuid, test_id)
+);
+
+-- One row per program run: the submission's compilation (test_id 0),
+-- and the submission and checker runs of each test.
+-- Streams longer than a few KiB live in the exec file store under *_key.
+CREATE TABLE exec_run_data (
+    exec_uuid UUID NOT NULL REFERENCES executions(uuid) ON DELETE CASCADE,
+    test_id INTEGER NOT NULL,
+    program VARCHAR(20) NOT NULL,
+    stdin TEXT,
+    stdin_key TEXT,
+    stdout TEXT,
+    stdout_key TEXT,
+    stderr TEXT,
+    stderr_key TEXT,
+    cpu_ms BIGINT NOT NULL,
+    wall_ms BIGINT NOT NULL,
+    mem_kib BIGINT NOT NULL,
+    exit_code BIGINT NOT NULL,
+    ctx_sw_v BIGINT NOT NULL,
+    ctx_sw_f BIGINT NOT NULL,
+    signal BIGINT,
+    is_oom_killed BOOLEAN NOT NULL,
+    isol_status TEXT,
+    isol_msg TEXT,
+    PRIMARY KEY (exec_uuid, test_id, program),
+    CONSTRAINT program_check
+        CHECK (program IN ('compile', 'subm', 'checker'))
+);
+
+CREATE TABLE exec_pending (
+    uuid UUID PRIMARY KEY,
+    request JSONB NOT NULL,
+    enqueued_at TIMESTAMPTZ NOT NULL
+);
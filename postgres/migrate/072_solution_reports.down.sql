@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_solution_reports;
+ALTER TABLE task_solutions DROP COLUMN IF EXISTS score;
@@ -0,0 +1,11 @@
+ALTER TABLE task_solutions ADD COLUMN score INTEGER;
+
+-- Outcome of running a task's bundled solutions against its own tests.
+-- finished_at stays NULL while the solutions are running.
+CREATE TABLE task_solution_reports (
+    task_id TEXT PRIMARY KEY REFERENCES tasks(short_id),
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ,
+    flagged BOOLEAN NOT NULL,
+    solutions JSONB NOT NULL
+);
@@ -0,0 +1,3 @@
+ALTER TABLE evaluations DROP COLUMN IF EXISTS task_revision;
+DROP TABLE IF EXISTS task_revisions;
+ALTER TABLE tasks DROP COLUMN IF EXISTS revision;
@@ -0,0 +1,15 @@
+ALTER TABLE tasks ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+
+-- The task tables hold the current revision. Each revision is also
+-- kept as a JSON copy of the task; tasks created before revisions get
+-- the copy of their first revision when they are revised.
+CREATE TABLE task_revisions (
+    task_id TEXT NOT NULL REFERENCES tasks(short_id),
+    revision INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    snapshot JSONB NOT NULL,
+    PRIMARY KEY (task_id, revision)
+);
+
+-- 0 marks evaluations graded before revisions were tracked
+ALTER TABLE evaluations ADD COLUMN task_revision INTEGER NOT NULL DEFAULT 0;
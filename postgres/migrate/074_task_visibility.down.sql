@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS publish_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS visibility;
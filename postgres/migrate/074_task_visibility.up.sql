@@ -0,0 +1,5 @@
+-- Existing tasks stay public. publish_at schedules a draft or hidden
+-- task to become published.
+ALTER TABLE tasks ADD COLUMN visibility TEXT NOT NULL DEFAULT 'published'
+    CHECK (visibility IN ('draft', 'hidden', 'published', 'archived'));
+ALTER TABLE tasks ADD COLUMN publish_at TIMESTAMPTZ;
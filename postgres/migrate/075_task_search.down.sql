@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_search;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS latvian;
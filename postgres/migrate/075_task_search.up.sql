@@ -0,0 +1,47 @@
+-- Postgres has no Latvian stemmer, so Latvian text is only lowercased.
+CREATE TEXT SEARCH CONFIGURATION latvian (COPY = pg_catalog.simple);
+
+-- One search document per task language. Names weigh most, then tags
+-- and authors, then the story, then the input and output sections.
+CREATE TABLE task_search (
+    task_short_id TEXT NOT NULL REFERENCES tasks(short_id) ON DELETE CASCADE,
+    lang TEXT NOT NULL,
+    config TEXT NOT NULL,
+    body TEXT NOT NULL,
+    document TSVECTOR NOT NULL,
+    PRIMARY KEY (task_short_id, lang)
+);
+
+CREATE INDEX task_search_document_idx ON task_search USING GIN (document);
+
+WITH langs AS (
+    SELECT t.short_id, k.lang
+    FROM tasks t, jsonb_object_keys(COALESCE(t.full_name_dict, '{}'::jsonb)) AS k(lang)
+    UNION
+    SELECT s.task_short_id, s.lang_iso639 FROM task_md_statements s
+    WHERE s.lang_iso639 IS NOT NULL
+), docs AS (
+    SELECT l.short_id, l.lang,
+           CASE l.lang WHEN 'lv' THEN 'latvian' WHEN 'en' THEN 'english' ELSE 'simple' END AS config,
+           COALESCE(t.full_name_dict->>l.lang, '') AS name,
+           concat_ws(' ',
+               (SELECT string_agg(x, ' ') FROM jsonb_array_elements_text(COALESCE(t.problem_tags, '[]'::jsonb)) AS x),
+               (SELECT string_agg(x, ' ') FROM jsonb_array_elements_text(COALESCE(t.authors, '[]'::jsonb)) AS x)
+           ) AS meta,
+           COALESCE(s.story, '') AS story,
+           concat_ws(E'\n\n', s.input, s.output) AS io
+    FROM langs l
+    JOIN tasks t ON t.short_id = l.short_id
+    LEFT JOIN LATERAL (
+        SELECT story, input, output FROM task_md_statements
+        WHERE task_short_id = l.short_id AND lang_iso639 = l.lang
+        ORDER BY id DESC LIMIT 1
+    ) s ON TRUE
+)
+INSERT INTO task_search (task_short_id, lang, config, body, document)
+SELECT short_id, lang, config, concat_ws(E'\n\n', story, io),
+       setweight(to_tsvector(config::regconfig, name), 'A') ||
+       setweight(to_tsvector(config::regconfig, meta), 'B') ||
+       setweight(to_tsvector(config::regconfig, story), 'C') ||
+       setweight(to_tsvector(config::regconfig, io), 'D')
+FROM docs;
@@ -0,0 +1,9 @@
+-- Files handed to contestants with the statement, such as grader
+-- headers. The files live in the public store under attachments/.
+CREATE TABLE task_attachments (
+    task_short_id TEXT NOT NULL REFERENCES tasks(short_id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    object_key TEXT NOT NULL,
+    filesize_bytes INTEGER NOT NULL,
+    PRIMARY KEY (task_short_id, path)
+);
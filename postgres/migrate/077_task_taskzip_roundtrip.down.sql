@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS task_authoring_files;
+ALTER TABLE tasks DROP COLUMN IF EXISTS origin_solvers;
+ALTER TABLE tasks DROP COLUMN IF EXISTS origin_contestants;
+ALTER TABLE tasks DROP COLUMN IF EXISTS data_structures;
+ALTER TABLE tasks DROP COLUMN IF EXISTS techniques;
@@ -0,0 +1,17 @@
+-- TaskZip metadata that used to be flattened or dropped on import.
+-- problem_tags holds the topics; techniques and data structures are
+-- kept apart so that export can restore the categories.
+ALTER TABLE tasks ADD COLUMN techniques JSONB;
+ALTER TABLE tasks ADD COLUMN data_structures JSONB;
+ALTER TABLE tasks ADD COLUMN origin_contestants INTEGER;
+ALTER TABLE tasks ADD COLUMN origin_solvers INTEGER;
+
+-- The archive/ and testspec/ trees of an imported TaskZip. The files
+-- are content-addressed in the private test file store like tests.
+CREATE TABLE task_authoring_files (
+    task_short_id TEXT NOT NULL REFERENCES tasks(short_id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    sha2 TEXT NOT NULL,
+    filesize_bytes INTEGER NOT NULL,
+    PRIMARY KEY (task_short_id, path)
+);
@@ -0,0 +1 @@
+ALTER TABLE evaluations DROP COLUMN lane;
@@ -0,0 +1,3 @@
+-- job queue lane, so that resumed evaluations keep their priority;
+-- evaluations enqueued before lanes were live submissions or re-evaluations alike
+ALTER TABLE evaluations ADD COLUMN lane TEXT NOT NULL DEFAULT 'high';
@@ -0,0 +1,17 @@
+-- fails on rows that contain NUL bytes
+ALTER TABLE exec_pending
+    ALTER COLUMN request TYPE JSONB USING request::jsonb;
+
+ALTER TABLE executions
+    ALTER COLUMN sys_info TYPE TEXT USING convert_from(sys_info, 'UTF8'),
+    ALTER COLUMN error_msg TYPE TEXT USING convert_from(error_msg, 'UTF8'),
+    ALTER COLUMN params TYPE JSONB USING params::jsonb;
+
+ALTER TABLE exec_tests
+    ALTER COLUMN answer_preview TYPE TEXT USING convert_from(answer_preview, 'UTF8'),
+    ALTER COLUMN input_preview TYPE TEXT USING convert_from(input_preview, 'UTF8');
+
+ALTER TABLE exec_run_data
+    ALTER COLUMN stderr TYPE TEXT USING convert_from(stderr, 'UTF8'),
+    ALTER COLUMN stdout TYPE TEXT USING convert_from(stdout, 'UTF8'),
+    ALTER COLUMN stdin TYPE TEXT USING convert_from(stdin, 'UTF8');
@@ -0,0 +1,18 @@
+-- program output, test previews and tester messages may contain NUL bytes,
+-- which TEXT and JSONB reject; JSON documents containing \u0000 are kept as TEXT
+ALTER TABLE exec_run_data
+    ALTER COLUMN stdin TYPE BYTEA USING convert_to(stdin, 'UTF8'),
+    ALTER COLUMN stdout TYPE BYTEA USING convert_to(stdout, 'UTF8'),
+    ALTER COLUMN stderr TYPE BYTEA USING convert_to(stderr, 'UTF8');
+
+ALTER TABLE exec_tests
+    ALTER COLUMN input_preview TYPE BYTEA USING convert_to(input_preview, 'UTF8'),
+    ALTER COLUMN answer_preview TYPE BYTEA USING convert_to(answer_preview, 'UTF8');
+
+ALTER TABLE executions
+    ALTER COLUMN params TYPE TEXT USING params::text,
+    ALTER COLUMN error_msg TYPE BYTEA USING convert_to(error_msg, 'UTF8'),
+    ALTER COLUMN sys_info TYPE BYTEA USING convert_to(sys_info, 'UTF8');
+
+ALTER TABLE exec_pending
+    ALTER COLUMN request TYPE TEXT USING request::text;
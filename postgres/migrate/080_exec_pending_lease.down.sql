@@ -0,0 +1,3 @@
+ALTER TABLE exec_pending
+    DROP COLUMN lease_until,
+    DROP COLUMN owner;
@@ -0,0 +1,5 @@
+-- the backend replica that publishes the job; another replica
+-- takes the execution over once the lease has run out
+ALTER TABLE exec_pending
+    ADD COLUMN owner TEXT NOT NULL DEFAULT '',
+    ADD COLUMN lease_until TIMESTAMPTZ NOT NULL DEFAULT 'epoch';
@@ -0,0 +1 @@
+ALTER TABLE task_solution_reports DROP COLUMN IF EXISTS interrupted;
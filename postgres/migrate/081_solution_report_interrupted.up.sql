@@ -0,0 +1,2 @@
+-- Set when the process running the solutions stopped before they were graded.
+ALTER TABLE task_solution_reports ADD COLUMN interrupted BOOLEAN NOT NULL DEFAULT false;
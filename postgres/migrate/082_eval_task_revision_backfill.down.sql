@@ -0,0 +1,2 @@
+-- The backfilled evaluations cannot be told apart from later ones.
+SELECT 1;
@@ -0,0 +1,3 @@
+-- Evaluations graded before revisions were tracked were graded against
+-- the first revision, which every task had when revisions were added.
+UPDATE evaluations SET task_revision = 1 WHERE task_revision = 0;
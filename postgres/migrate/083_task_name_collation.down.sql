@@ -0,0 +1 @@
+DROP COLLATION IF EXISTS lv_ci;
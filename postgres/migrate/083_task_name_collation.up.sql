@@ -0,0 +1,2 @@
+-- Case-insensitive Latvian alphabetical order of task names.
+CREATE COLLATION IF NOT EXISTS lv_ci (provider = icu, locale = 'lv-u-ks-level2', deterministic = false);
@@ -0,0 +1,2 @@
+-- The tags stay split; the columns are dropped by the down migration of 077.
+SELECT 1;
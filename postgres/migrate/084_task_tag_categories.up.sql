@@ -0,0 +1,98 @@
+-- Tasks stored before 077 have their TaskZip techniques and data
+-- structures flattened into problem_tags and NULL in the new columns.
+-- Split them again by the TaskZip vocabularies of srvc/zip.go. A slug in
+-- several vocabularies stays in the first of topics, techniques and data
+-- structures; slugs outside them stay topics, as export wrote them.
+WITH vocab (slug, category) AS (VALUES
+    ('implementation', 'topic'), ('arrays', 'topic'), ('strings', 'topic'),
+    ('sorting-searching', 'topic'), ('mathematics', 'topic'),
+    ('number-theory', 'topic'), ('combinatorics', 'topic'),
+    ('graphs', 'topic'), ('trees', 'topic'), ('grids', 'topic'),
+    ('geometry', 'topic'), ('data-structures', 'topic'),
+    ('dynamic-programming', 'topic'), ('bitwise', 'topic'),
+    ('games', 'topic'), ('construction', 'topic'), ('interactive', 'topic'),
+    ('brute-force', 'technique'), ('simulation', 'technique'),
+    ('sorting', 'technique'), ('binary-search', 'technique'),
+    ('two-pointers', 'technique'), ('sliding-window', 'technique'),
+    ('prefix-sums', 'technique'), ('difference-array', 'technique'),
+    ('greedy', 'technique'), ('recursion', 'technique'),
+    ('backtracking', 'technique'), ('divide-and-conquer', 'technique'),
+    ('meet-in-the-middle', 'technique'),
+    ('coordinate-compression', 'technique'), ('sweep-line', 'technique'),
+    ('bfs', 'technique'), ('dfs', 'technique'), ('flood-fill', 'technique'),
+    ('shortest-paths', 'technique'), ('dijkstra', 'technique'),
+    ('bellman-ford', 'technique'), ('floyd-warshall', 'technique'),
+    ('topological-sort', 'technique'),
+    ('strongly-connected-components', 'technique'),
+    ('minimum-spanning-tree', 'technique'), ('euler-tour', 'technique'),
+    ('lca', 'technique'), ('tree-dp', 'technique'), ('max-flow', 'technique'),
+    ('matching', 'technique'), ('dp', 'technique'),
+    ('knapsack-dp', 'technique'), ('interval-dp', 'technique'),
+    ('bitmask-dp', 'technique'), ('digit-dp', 'technique'),
+    ('dp-optimization', 'technique'), ('modular-arithmetic', 'technique'),
+    ('gcd', 'technique'), ('sieve', 'technique'), ('primes', 'technique'),
+    ('probability', 'technique'), ('matrix-exponentiation', 'technique'),
+    ('game-theory', 'technique'), ('string-matching', 'technique'),
+    ('hashing', 'technique'), ('kmp', 'technique'),
+    ('z-function', 'technique'), ('trie', 'technique'),
+    ('suffix-array', 'technique'), ('convex-hull', 'technique'),
+    ('point-line-geometry', 'technique'), ('polygon-geometry', 'technique'),
+    ('array', 'data_structure'), ('stack', 'data_structure'),
+    ('queue', 'data_structure'), ('deque', 'data_structure'),
+    ('map-set', 'data_structure'), ('priority-queue', 'data_structure'),
+    ('dsu', 'data_structure'), ('fenwick-tree', 'data_structure'),
+    ('segment-tree', 'data_structure'),
+    ('lazy-segment-tree', 'data_structure'),
+    ('sparse-table', 'data_structure'), ('ordered-set', 'data_structure'),
+    ('bitset', 'data_structure')
+), split AS (
+    SELECT t.short_id,
+           COALESCE(jsonb_agg(x.slug ORDER BY x.ord) FILTER (WHERE COALESCE(v.category, 'topic') = 'topic'), '[]'::jsonb) AS topics,
+           COALESCE(jsonb_agg(x.slug ORDER BY x.ord) FILTER (WHERE v.category = 'technique'), '[]'::jsonb) AS techniques,
+           COALESCE(jsonb_agg(x.slug ORDER BY x.ord) FILTER (WHERE v.category = 'data_structure'), '[]'::jsonb) AS data_structures
+    FROM tasks t
+    CROSS JOIN LATERAL jsonb_array_elements_text(COALESCE(t.problem_tags, '[]'::jsonb)) WITH ORDINALITY AS x(slug, ord)
+    LEFT JOIN vocab v ON v.slug = x.slug
+    WHERE t.techniques IS NULL AND t.data_structures IS NULL
+    GROUP BY t.short_id
+)
+UPDATE tasks t
+SET problem_tags = s.topics, techniques = s.techniques, data_structures = s.data_structures
+FROM split s
+WHERE t.short_id = s.short_id;
+
+-- Rebuild every search document with the refreshTaskSearch query of
+-- modules/task/repo/search.go; 075 indexed only problem_tags.
+DELETE FROM task_search;
+
+WITH langs AS (
+    SELECT t.short_id, k.lang
+    FROM tasks t, jsonb_object_keys(COALESCE(t.full_name_dict, '{}'::jsonb)) AS k(lang)
+    UNION
+    SELECT s.task_short_id, s.lang_iso639 FROM task_md_statements s
+    WHERE s.lang_iso639 IS NOT NULL
+), docs AS (
+    SELECT l.short_id, l.lang,
+           CASE l.lang WHEN 'lv' THEN 'latvian' WHEN 'en' THEN 'english' ELSE 'simple' END AS config,
+           COALESCE(t.full_name_dict->>l.lang, '') AS name,
+           concat_ws(' ',
+               (SELECT string_agg(x, ' ') FROM jsonb_array_elements_text(COALESCE(t.problem_tags, '[]'::jsonb) || COALESCE(t.techniques, '[]'::jsonb) || COALESCE(t.data_structures, '[]'::jsonb)) AS x),
+               (SELECT string_agg(x, ' ') FROM jsonb_array_elements_text(COALESCE(t.authors, '[]'::jsonb)) AS x)
+           ) AS meta,
+           COALESCE(s.story, '') AS story,
+           concat_ws(E'\n\n', s.input, s.output) AS io
+    FROM langs l
+    JOIN tasks t ON t.short_id = l.short_id
+    LEFT JOIN LATERAL (
+        SELECT story, input, output FROM task_md_statements
+        WHERE task_short_id = l.short_id AND lang_iso639 = l.lang
+        ORDER BY id DESC LIMIT 1
+    ) s ON TRUE
+)
+INSERT INTO task_search (task_short_id, lang, config, body, document)
+SELECT short_id, lang, config, concat_ws(E'\n\n', story, io),
+       setweight(to_tsvector(config::regconfig, name), 'A') ||
+       setweight(to_tsvector(config::regconfig, meta), 'B') ||
+       setweight(to_tsvector(config::regconfig, story), 'C') ||
+       setweight(to_tsvector(config::regconfig, io), 'D')
+FROM docs;